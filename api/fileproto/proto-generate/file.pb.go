@@ -298,6 +298,10 @@ func (x *DownloadFileResponse) GetChunk() []byte {
 type ListFilesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	IncludeShared bool                   `protobuf:"varint,1,opt,name=include_shared,json=includeShared,proto3" json:"include_shared,omitempty"`
+	// Файл должен иметь все перечисленные теги
+	Tags []string `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	// и все перечисленные пары ключ/значение
+	Properties    map[string]string `protobuf:"bytes,3,rep,name=properties,proto3" json:"properties,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -339,6 +343,20 @@ func (x *ListFilesRequest) GetIncludeShared() bool {
 	return false
 }
 
+func (x *ListFilesRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ListFilesRequest) GetProperties() map[string]string {
+	if x != nil {
+		return x.Properties
+	}
+	return nil
+}
+
 type FileInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
@@ -349,6 +367,8 @@ type FileInfo struct {
 	CreatedAt     int64                  `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	UpdatedAt     int64                  `protobuf:"varint,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
 	IsOwner       bool                   `protobuf:"varint,8,opt,name=is_owner,json=isOwner,proto3" json:"is_owner,omitempty"`
+	Tags          []string               `protobuf:"bytes,9,rep,name=tags,proto3" json:"tags,omitempty"`
+	Properties    map[string]string      `protobuf:"bytes,10,rep,name=properties,proto3" json:"properties,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -439,6 +459,20 @@ func (x *FileInfo) GetIsOwner() bool {
 	return false
 }
 
+func (x *FileInfo) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *FileInfo) GetProperties() map[string]string {
+	if x != nil {
+		return x.Properties
+	}
+	return nil
+}
+
 type ListFilesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Files         []*FileInfo            `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
@@ -960,8 +994,18 @@ type FileVersionInfo struct {
 	VersionNumber uint32                 `protobuf:"varint,1,opt,name=version_number,json=versionNumber,proto3" json:"version_number,omitempty"`
 	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
 	CreatedAt     int64                  `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	// stored_size — сколько объект занимает в хранилище после сжатия и шифрования
+	StoredSize int64 `protobuf:"varint,4,opt,name=stored_size,json=storedSize,proto3" json:"stored_size,omitempty"`
+	// logical_size — исходный размер, который получает клиент; совпадает с size
+	LogicalSize int64 `protobuf:"varint,5,opt,name=logical_size,json=logicalSize,proto3" json:"logical_size,omitempty"`
+	// scan_status — pending_scan, clean или infected; скачать можно только clean
+	ScanStatus string `protobuf:"bytes,6,opt,name=scan_status,json=scanStatus,proto3" json:"scan_status,omitempty"`
+	// tier — hot или cold; холодные версии читаются так же, но медленнее
+	Tier string `protobuf:"bytes,7,opt,name=tier,proto3" json:"tier,omitempty"`
+	// replication_status — none, pending, replicated или failed
+	ReplicationStatus string `protobuf:"bytes,8,opt,name=replication_status,json=replicationStatus,proto3" json:"replication_status,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *FileVersionInfo) Reset() {
@@ -1015,6 +1059,41 @@ func (x *FileVersionInfo) GetCreatedAt() int64 {
 	return 0
 }
 
+func (x *FileVersionInfo) GetStoredSize() int64 {
+	if x != nil {
+		return x.StoredSize
+	}
+	return 0
+}
+
+func (x *FileVersionInfo) GetLogicalSize() int64 {
+	if x != nil {
+		return x.LogicalSize
+	}
+	return 0
+}
+
+func (x *FileVersionInfo) GetScanStatus() string {
+	if x != nil {
+		return x.ScanStatus
+	}
+	return ""
+}
+
+func (x *FileVersionInfo) GetTier() string {
+	if x != nil {
+		return x.Tier
+	}
+	return ""
+}
+
+func (x *FileVersionInfo) GetReplicationStatus() string {
+	if x != nil {
+		return x.ReplicationStatus
+	}
+	return ""
+}
+
 type GetFileVersionsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Versions      []*FileVersionInfo     `protobuf:"bytes,1,rep,name=versions,proto3" json:"versions,omitempty"`
@@ -1163,168 +1242,2366 @@ func (x *RevertFileResponse) GetNewFileId() string {
 	return ""
 }
 
-var File_file_proto protoreflect.FileDescriptor
+type WebhookInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WebhookId     string                 `protobuf:"bytes,1,opt,name=webhook_id,json=webhookId,proto3" json:"webhook_id,omitempty"`
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	EventTypes    []string               `protobuf:"bytes,3,rep,name=event_types,json=eventTypes,proto3" json:"event_types,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_file_proto_rawDesc = "" +
-	"\n" +
-	"\n" +
-	"file.proto\x12\x04file\"e\n" +
-	"\x11UploadFileRequest\x120\n" +
-	"\bmetadata\x18\x01 \x01(\v2\x12.file.FileMetadataH\x00R\bmetadata\x12\x16\n" +
-	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\x06\n" +
-	"\x04data\"E\n" +
-	"\fFileMetadata\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\x12!\n" +
-	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\"G\n" +
-	"\x12UploadFileResponse\x12\x17\n" +
-	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\".\n" +
-	"\x13DownloadFileRequest\x12\x17\n" +
-	"\afile_id\x18\x01 \x01(\tR\x06fileId\",\n" +
-	"\x14DownloadFileResponse\x12\x14\n" +
-	"\x05chunk\x18\x01 \x01(\fR\x05chunk\"9\n" +
-	"\x10ListFilesRequest\x12%\n" +
-	"\x0einclude_shared\x18\x01 \x01(\bR\rincludeShared\"\xe1\x01\n" +
-	"\bFileInfo\x12\x17\n" +
-	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
-	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x18\n" +
-	"\aversion\x18\x04 \x01(\rR\aversion\x12!\n" +
-	"\fcontent_type\x18\x05 \x01(\tR\vcontentType\x12\x1d\n" +
-	"\n" +
-	"created_at\x18\x06 \x01(\x03R\tcreatedAt\x12\x1d\n" +
-	"\n" +
-	"updated_at\x18\a \x01(\x03R\tupdatedAt\x12\x19\n" +
-	"\bis_owner\x18\b \x01(\bR\aisOwner\"9\n" +
-	"\x11ListFilesResponse\x12$\n" +
-	"\x05files\x18\x01 \x03(\v2\x0e.file.FileInfoR\x05files\",\n" +
-	"\x11DeleteFileRequest\x12\x17\n" +
-	"\afile_id\x18\x01 \x01(\tR\x06fileId\"H\n" +
-	"\x12DeleteFileResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"-\n" +
-	"\x12GetFileInfoRequest\x12\x17\n" +
-	"\afile_id\x18\x01 \x01(\tR\x06fileId\"9\n" +
-	"\x13GetFileInfoResponse\x12\"\n" +
-	"\x04file\x18\x01 \x01(\v2\x0e.file.FileInfoR\x04file\"G\n" +
-	"\x11RenameFileRequest\x12\x17\n" +
-	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x19\n" +
-	"\bnew_name\x18\x02 \x01(\tR\anewName\".\n" +
-	"\x12RenameFileResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"S\n" +
-	"\x0fPermissionEntry\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\x05R\x06userId\x12'\n" +
-	"\x0fpermission_type\x18\x02 \x01(\x05R\x0epermissionType\"m\n" +
-	"\x19SetFilePermissionsRequest\x12\x17\n" +
-	"\afile_id\x18\x01 \x01(\tR\x06fileId\x127\n" +
-	"\vpermissions\x18\x02 \x03(\v2\x15.file.PermissionEntryR\vpermissions\"6\n" +
-	"\x1aSetFilePermissionsResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"1\n" +
-	"\x16GetFileVersionsRequest\x12\x17\n" +
-	"\afile_id\x18\x01 \x01(\tR\x06fileId\"k\n" +
-	"\x0fFileVersionInfo\x12%\n" +
-	"\x0eversion_number\x18\x01 \x01(\rR\rversionNumber\x12\x12\n" +
-	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x1d\n" +
-	"\n" +
-	"created_at\x18\x03 \x01(\x03R\tcreatedAt\"L\n" +
-	"\x17GetFileVersionsResponse\x121\n" +
-	"\bversions\x18\x01 \x03(\v2\x15.file.FileVersionInfoR\bversions\"F\n" +
-	"\x11RevertFileRequest\x12\x17\n" +
-	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x18\n" +
-	"\aversion\x18\x02 \x01(\rR\aversion\"N\n" +
-	"\x12RevertFileResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1e\n" +
-	"\vnew_file_id\x18\x02 \x01(\tR\tnewFileId2\x8e\x05\n" +
-	"\vFileService\x12A\n" +
-	"\n" +
-	"UploadFile\x12\x17.file.UploadFileRequest\x1a\x18.file.UploadFileResponse(\x01\x12G\n" +
-	"\fDownloadFile\x12\x19.file.DownloadFileRequest\x1a\x1a.file.DownloadFileResponse0\x01\x12<\n" +
-	"\tListFiles\x12\x16.file.ListFilesRequest\x1a\x17.file.ListFilesResponse\x12?\n" +
-	"\n" +
-	"DeleteFile\x12\x17.file.DeleteFileRequest\x1a\x18.file.DeleteFileResponse\x12B\n" +
-	"\vGetFileInfo\x12\x18.file.GetFileInfoRequest\x1a\x19.file.GetFileInfoResponse\x12?\n" +
-	"\n" +
-	"RenameFile\x12\x17.file.RenameFileRequest\x1a\x18.file.RenameFileResponse\x12W\n" +
-	"\x12SetFilePermissions\x12\x1f.file.SetFilePermissionsRequest\x1a .file.SetFilePermissionsResponse\x12N\n" +
-	"\x0fGetFileVersions\x12\x1c.file.GetFileVersionsRequest\x1a\x1d.file.GetFileVersionsResponse\x12F\n" +
-	"\x11RevertFileVersion\x12\x17.file.RevertFileRequest\x1a\x18.file.RevertFileResponseB\x18Z\x16./proto-generate/;fileb\x06proto3"
+func (x *WebhookInfo) Reset() {
+	*x = WebhookInfo{}
+	mi := &file_file_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
 
-var (
-	file_file_proto_rawDescOnce sync.Once
-	file_file_proto_rawDescData []byte
-)
+func (x *WebhookInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
 
-func file_file_proto_rawDescGZIP() []byte {
-	file_file_proto_rawDescOnce.Do(func() {
-		file_file_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_file_proto_rawDesc), len(file_file_proto_rawDesc)))
-	})
-	return file_file_proto_rawDescData
+func (*WebhookInfo) ProtoMessage() {}
+
+func (x *WebhookInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-var file_file_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
-var file_file_proto_goTypes = []any{
-	(*UploadFileRequest)(nil),          // 0: file.UploadFileRequest
-	(*FileMetadata)(nil),               // 1: file.FileMetadata
-	(*UploadFileResponse)(nil),         // 2: file.UploadFileResponse
-	(*DownloadFileRequest)(nil),        // 3: file.DownloadFileRequest
-	(*DownloadFileResponse)(nil),       // 4: file.DownloadFileResponse
-	(*ListFilesRequest)(nil),           // 5: file.ListFilesRequest
-	(*FileInfo)(nil),                   // 6: file.FileInfo
-	(*ListFilesResponse)(nil),          // 7: file.ListFilesResponse
-	(*DeleteFileRequest)(nil),          // 8: file.DeleteFileRequest
-	(*DeleteFileResponse)(nil),         // 9: file.DeleteFileResponse
-	(*GetFileInfoRequest)(nil),         // 10: file.GetFileInfoRequest
-	(*GetFileInfoResponse)(nil),        // 11: file.GetFileInfoResponse
-	(*RenameFileRequest)(nil),          // 12: file.RenameFileRequest
-	(*RenameFileResponse)(nil),         // 13: file.RenameFileResponse
-	(*PermissionEntry)(nil),            // 14: file.PermissionEntry
-	(*SetFilePermissionsRequest)(nil),  // 15: file.SetFilePermissionsRequest
-	(*SetFilePermissionsResponse)(nil), // 16: file.SetFilePermissionsResponse
-	(*GetFileVersionsRequest)(nil),     // 17: file.GetFileVersionsRequest
-	(*FileVersionInfo)(nil),            // 18: file.FileVersionInfo
-	(*GetFileVersionsResponse)(nil),    // 19: file.GetFileVersionsResponse
-	(*RevertFileRequest)(nil),          // 20: file.RevertFileRequest
-	(*RevertFileResponse)(nil),         // 21: file.RevertFileResponse
+// Deprecated: Use WebhookInfo.ProtoReflect.Descriptor instead.
+func (*WebhookInfo) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{22}
 }
-var file_file_proto_depIdxs = []int32{
-	1,  // 0: file.UploadFileRequest.metadata:type_name -> file.FileMetadata
-	6,  // 1: file.ListFilesResponse.files:type_name -> file.FileInfo
-	6,  // 2: file.GetFileInfoResponse.file:type_name -> file.FileInfo
-	14, // 3: file.SetFilePermissionsRequest.permissions:type_name -> file.PermissionEntry
-	18, // 4: file.GetFileVersionsResponse.versions:type_name -> file.FileVersionInfo
-	0,  // 5: file.FileService.UploadFile:input_type -> file.UploadFileRequest
-	3,  // 6: file.FileService.DownloadFile:input_type -> file.DownloadFileRequest
-	5,  // 7: file.FileService.ListFiles:input_type -> file.ListFilesRequest
-	8,  // 8: file.FileService.DeleteFile:input_type -> file.DeleteFileRequest
-	10, // 9: file.FileService.GetFileInfo:input_type -> file.GetFileInfoRequest
-	12, // 10: file.FileService.RenameFile:input_type -> file.RenameFileRequest
-	15, // 11: file.FileService.SetFilePermissions:input_type -> file.SetFilePermissionsRequest
-	17, // 12: file.FileService.GetFileVersions:input_type -> file.GetFileVersionsRequest
-	20, // 13: file.FileService.RevertFileVersion:input_type -> file.RevertFileRequest
-	2,  // 14: file.FileService.UploadFile:output_type -> file.UploadFileResponse
-	4,  // 15: file.FileService.DownloadFile:output_type -> file.DownloadFileResponse
-	7,  // 16: file.FileService.ListFiles:output_type -> file.ListFilesResponse
-	9,  // 17: file.FileService.DeleteFile:output_type -> file.DeleteFileResponse
-	11, // 18: file.FileService.GetFileInfo:output_type -> file.GetFileInfoResponse
-	13, // 19: file.FileService.RenameFile:output_type -> file.RenameFileResponse
-	16, // 20: file.FileService.SetFilePermissions:output_type -> file.SetFilePermissionsResponse
-	19, // 21: file.FileService.GetFileVersions:output_type -> file.GetFileVersionsResponse
-	21, // 22: file.FileService.RevertFileVersion:output_type -> file.RevertFileResponse
-	14, // [14:23] is the sub-list for method output_type
-	5,  // [5:14] is the sub-list for method input_type
-	5,  // [5:5] is the sub-list for extension type_name
-	5,  // [5:5] is the sub-list for extension extendee
-	0,  // [0:5] is the sub-list for field type_name
+
+func (x *WebhookInfo) GetWebhookId() string {
+	if x != nil {
+		return x.WebhookId
+	}
+	return ""
 }
 
-func init() { file_file_proto_init() }
-func file_file_proto_init() {
-	if File_file_proto != nil {
-		return
+func (x *WebhookInfo) GetUrl() string {
+	if x != nil {
+		return x.Url
 	}
-	file_file_proto_msgTypes[0].OneofWrappers = []any{
-		(*UploadFileRequest_Metadata)(nil),
-		(*UploadFileRequest_Chunk)(nil),
+	return ""
+}
+
+func (x *WebhookInfo) GetEventTypes() []string {
+	if x != nil {
+		return x.EventTypes
+	}
+	return nil
+}
+
+func (x *WebhookInfo) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+type CreateWebhookRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Url   string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// file.uploaded, file.shared, file.deleted, file.version_created
+	EventTypes    []string `protobuf:"bytes,2,rep,name=event_types,json=eventTypes,proto3" json:"event_types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateWebhookRequest) Reset() {
+	*x = CreateWebhookRequest{}
+	mi := &file_file_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateWebhookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateWebhookRequest) ProtoMessage() {}
+
+func (x *CreateWebhookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateWebhookRequest.ProtoReflect.Descriptor instead.
+func (*CreateWebhookRequest) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CreateWebhookRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *CreateWebhookRequest) GetEventTypes() []string {
+	if x != nil {
+		return x.EventTypes
+	}
+	return nil
+}
+
+type CreateWebhookResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Webhook *WebhookInfo           `protobuf:"bytes,1,opt,name=webhook,proto3" json:"webhook,omitempty"`
+	// Секрет для проверки заголовка X-Webhook-Signature, возвращается только один раз
+	Secret        string `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateWebhookResponse) Reset() {
+	*x = CreateWebhookResponse{}
+	mi := &file_file_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateWebhookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateWebhookResponse) ProtoMessage() {}
+
+func (x *CreateWebhookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateWebhookResponse.ProtoReflect.Descriptor instead.
+func (*CreateWebhookResponse) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *CreateWebhookResponse) GetWebhook() *WebhookInfo {
+	if x != nil {
+		return x.Webhook
+	}
+	return nil
+}
+
+func (x *CreateWebhookResponse) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+type ListWebhooksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWebhooksRequest) Reset() {
+	*x = ListWebhooksRequest{}
+	mi := &file_file_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWebhooksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWebhooksRequest) ProtoMessage() {}
+
+func (x *ListWebhooksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWebhooksRequest.ProtoReflect.Descriptor instead.
+func (*ListWebhooksRequest) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{25}
+}
+
+type ListWebhooksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Webhooks      []*WebhookInfo         `protobuf:"bytes,1,rep,name=webhooks,proto3" json:"webhooks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWebhooksResponse) Reset() {
+	*x = ListWebhooksResponse{}
+	mi := &file_file_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWebhooksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWebhooksResponse) ProtoMessage() {}
+
+func (x *ListWebhooksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWebhooksResponse.ProtoReflect.Descriptor instead.
+func (*ListWebhooksResponse) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListWebhooksResponse) GetWebhooks() []*WebhookInfo {
+	if x != nil {
+		return x.Webhooks
+	}
+	return nil
+}
+
+type WebhookDelivery struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeliveryId    string                 `protobuf:"bytes,1,opt,name=delivery_id,json=deliveryId,proto3" json:"delivery_id,omitempty"`
+	WebhookId     string                 `protobuf:"bytes,2,opt,name=webhook_id,json=webhookId,proto3" json:"webhook_id,omitempty"`
+	EventType     string                 `protobuf:"bytes,3,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	Attempts      int32                  `protobuf:"varint,4,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	LastError     string                 `protobuf:"bytes,5,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WebhookDelivery) Reset() {
+	*x = WebhookDelivery{}
+	mi := &file_file_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebhookDelivery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebhookDelivery) ProtoMessage() {}
+
+func (x *WebhookDelivery) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebhookDelivery.ProtoReflect.Descriptor instead.
+func (*WebhookDelivery) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *WebhookDelivery) GetDeliveryId() string {
+	if x != nil {
+		return x.DeliveryId
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetWebhookId() string {
+	if x != nil {
+		return x.WebhookId
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *WebhookDelivery) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *WebhookDelivery) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+type ListDeadLettersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WebhookId     string                 `protobuf:"bytes,1,opt,name=webhook_id,json=webhookId,proto3" json:"webhook_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDeadLettersRequest) Reset() {
+	*x = ListDeadLettersRequest{}
+	mi := &file_file_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeadLettersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeadLettersRequest) ProtoMessage() {}
+
+func (x *ListDeadLettersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeadLettersRequest.ProtoReflect.Descriptor instead.
+func (*ListDeadLettersRequest) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ListDeadLettersRequest) GetWebhookId() string {
+	if x != nil {
+		return x.WebhookId
+	}
+	return ""
+}
+
+type ListDeadLettersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Deliveries    []*WebhookDelivery     `protobuf:"bytes,1,rep,name=deliveries,proto3" json:"deliveries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDeadLettersResponse) Reset() {
+	*x = ListDeadLettersResponse{}
+	mi := &file_file_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeadLettersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeadLettersResponse) ProtoMessage() {}
+
+func (x *ListDeadLettersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeadLettersResponse.ProtoReflect.Descriptor instead.
+func (*ListDeadLettersResponse) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ListDeadLettersResponse) GetDeliveries() []*WebhookDelivery {
+	if x != nil {
+		return x.Deliveries
+	}
+	return nil
+}
+
+type RedeliverWebhookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeliveryId    string                 `protobuf:"bytes,1,opt,name=delivery_id,json=deliveryId,proto3" json:"delivery_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RedeliverWebhookRequest) Reset() {
+	*x = RedeliverWebhookRequest{}
+	mi := &file_file_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RedeliverWebhookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RedeliverWebhookRequest) ProtoMessage() {}
+
+func (x *RedeliverWebhookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RedeliverWebhookRequest.ProtoReflect.Descriptor instead.
+func (*RedeliverWebhookRequest) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *RedeliverWebhookRequest) GetDeliveryId() string {
+	if x != nil {
+		return x.DeliveryId
+	}
+	return ""
+}
+
+type RedeliverWebhookResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RedeliverWebhookResponse) Reset() {
+	*x = RedeliverWebhookResponse{}
+	mi := &file_file_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RedeliverWebhookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RedeliverWebhookResponse) ProtoMessage() {}
+
+func (x *RedeliverWebhookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RedeliverWebhookResponse.ProtoReflect.Descriptor instead.
+func (*RedeliverWebhookResponse) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *RedeliverWebhookResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type SearchFilesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Синтаксис websearch: слова, "фраза", -исключение, or
+	Query         string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Limit         int32  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchFilesRequest) Reset() {
+	*x = SearchFilesRequest{}
+	mi := &file_file_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchFilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchFilesRequest) ProtoMessage() {}
+
+func (x *SearchFilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchFilesRequest.ProtoReflect.Descriptor instead.
+func (*SearchFilesRequest) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *SearchFilesRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchFilesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchFilesRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type SearchResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	File  *FileInfo              `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	Rank  float32                `protobuf:"fixed32,2,opt,name=rank,proto3" json:"rank,omitempty"`
+	// Фрагмент с совпадениями, выделенными <b>...</b>
+	Snippet       string `protobuf:"bytes,3,opt,name=snippet,proto3" json:"snippet,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_file_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *SearchResult) GetFile() *FileInfo {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+func (x *SearchResult) GetRank() float32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *SearchResult) GetSnippet() string {
+	if x != nil {
+		return x.Snippet
+	}
+	return ""
+}
+
+type SearchFilesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*SearchResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchFilesResponse) Reset() {
+	*x = SearchFilesResponse{}
+	mi := &file_file_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchFilesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchFilesResponse) ProtoMessage() {}
+
+func (x *SearchFilesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchFilesResponse.ProtoReflect.Descriptor instead.
+func (*SearchFilesResponse) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *SearchFilesResponse) GetResults() []*SearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type SetFileTagsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	FileId string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	// Заменяет текущий набор тегов
+	Tags          []string `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetFileTagsRequest) Reset() {
+	*x = SetFileTagsRequest{}
+	mi := &file_file_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetFileTagsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetFileTagsRequest) ProtoMessage() {}
+
+func (x *SetFileTagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetFileTagsRequest.ProtoReflect.Descriptor instead.
+func (*SetFileTagsRequest) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *SetFileTagsRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *SetFileTagsRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type SetFileTagsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Tags          []string               `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetFileTagsResponse) Reset() {
+	*x = SetFileTagsResponse{}
+	mi := &file_file_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetFileTagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetFileTagsResponse) ProtoMessage() {}
+
+func (x *SetFileTagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetFileTagsResponse.ProtoReflect.Descriptor instead.
+func (*SetFileTagsResponse) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *SetFileTagsResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *SetFileTagsResponse) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type SetFilePropertiesRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	FileId string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	// Заменяет текущий набор свойств
+	Properties    map[string]string `protobuf:"bytes,2,rep,name=properties,proto3" json:"properties,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetFilePropertiesRequest) Reset() {
+	*x = SetFilePropertiesRequest{}
+	mi := &file_file_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetFilePropertiesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetFilePropertiesRequest) ProtoMessage() {}
+
+func (x *SetFilePropertiesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetFilePropertiesRequest.ProtoReflect.Descriptor instead.
+func (*SetFilePropertiesRequest) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *SetFilePropertiesRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *SetFilePropertiesRequest) GetProperties() map[string]string {
+	if x != nil {
+		return x.Properties
+	}
+	return nil
+}
+
+type SetFilePropertiesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetFilePropertiesResponse) Reset() {
+	*x = SetFilePropertiesResponse{}
+	mi := &file_file_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetFilePropertiesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetFilePropertiesResponse) ProtoMessage() {}
+
+func (x *SetFilePropertiesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetFilePropertiesResponse.ProtoReflect.Descriptor instead.
+func (*SetFilePropertiesResponse) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *SetFilePropertiesResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type GetThumbnailRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	FileId string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	// Желаемая длина большей стороны; округляется вверх до ближайшего доступного размера
+	Size          uint32 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetThumbnailRequest) Reset() {
+	*x = GetThumbnailRequest{}
+	mi := &file_file_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetThumbnailRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetThumbnailRequest) ProtoMessage() {}
+
+func (x *GetThumbnailRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetThumbnailRequest.ProtoReflect.Descriptor instead.
+func (*GetThumbnailRequest) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *GetThumbnailRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *GetThumbnailRequest) GetSize() uint32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type GetThumbnailResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	ContentType   string                 `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Size          uint32                 `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetThumbnailResponse) Reset() {
+	*x = GetThumbnailResponse{}
+	mi := &file_file_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetThumbnailResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetThumbnailResponse) ProtoMessage() {}
+
+func (x *GetThumbnailResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetThumbnailResponse.ProtoReflect.Descriptor instead.
+func (*GetThumbnailResponse) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *GetThumbnailResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *GetThumbnailResponse) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *GetThumbnailResponse) GetSize() uint32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type DownloadArchiveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileIds       []string               `protobuf:"bytes,1,rep,name=file_ids,json=fileIds,proto3" json:"file_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadArchiveRequest) Reset() {
+	*x = DownloadArchiveRequest{}
+	mi := &file_file_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadArchiveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadArchiveRequest) ProtoMessage() {}
+
+func (x *DownloadArchiveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadArchiveRequest.ProtoReflect.Descriptor instead.
+func (*DownloadArchiveRequest) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *DownloadArchiveRequest) GetFileIds() []string {
+	if x != nil {
+		return x.FileIds
+	}
+	return nil
+}
+
+// Поток байтов ZIP-архива. Последняя запись архива — manifest.json
+// со списком вошедших и пропущенных файлов.
+type DownloadArchiveResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chunk         []byte                 `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadArchiveResponse) Reset() {
+	*x = DownloadArchiveResponse{}
+	mi := &file_file_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadArchiveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadArchiveResponse) ProtoMessage() {}
+
+func (x *DownloadArchiveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadArchiveResponse.ProtoReflect.Descriptor instead.
+func (*DownloadArchiveResponse) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *DownloadArchiveResponse) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+type UploadArchiveRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Data:
+	//
+	//	*UploadArchiveRequest_Metadata
+	//	*UploadArchiveRequest_Chunk
+	Data          isUploadArchiveRequest_Data `protobuf_oneof:"data"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadArchiveRequest) Reset() {
+	*x = UploadArchiveRequest{}
+	mi := &file_file_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadArchiveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadArchiveRequest) ProtoMessage() {}
+
+func (x *UploadArchiveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadArchiveRequest.ProtoReflect.Descriptor instead.
+func (*UploadArchiveRequest) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *UploadArchiveRequest) GetData() isUploadArchiveRequest_Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *UploadArchiveRequest) GetMetadata() *ArchiveMetadata {
+	if x != nil {
+		if x, ok := x.Data.(*UploadArchiveRequest_Metadata); ok {
+			return x.Metadata
+		}
+	}
+	return nil
+}
+
+func (x *UploadArchiveRequest) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Data.(*UploadArchiveRequest_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+type isUploadArchiveRequest_Data interface {
+	isUploadArchiveRequest_Data()
+}
+
+type UploadArchiveRequest_Metadata struct {
+	Metadata *ArchiveMetadata `protobuf:"bytes,1,opt,name=metadata,proto3,oneof"`
+}
+
+type UploadArchiveRequest_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*UploadArchiveRequest_Metadata) isUploadArchiveRequest_Data() {}
+
+func (*UploadArchiveRequest_Chunk) isUploadArchiveRequest_Data() {}
+
+type ArchiveMetadata struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// "zip" или "tar.gz"; если пусто, определяется по расширению name
+	Format        string `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArchiveMetadata) Reset() {
+	*x = ArchiveMetadata{}
+	mi := &file_file_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArchiveMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArchiveMetadata) ProtoMessage() {}
+
+func (x *ArchiveMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArchiveMetadata.ProtoReflect.Descriptor instead.
+func (*ArchiveMetadata) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *ArchiveMetadata) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ArchiveMetadata) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+type ArchiveEntryResult struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Path   string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	FileId string                 `protobuf:"bytes,2,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	// created, skipped или failed
+	Status        string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArchiveEntryResult) Reset() {
+	*x = ArchiveEntryResult{}
+	mi := &file_file_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArchiveEntryResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArchiveEntryResult) ProtoMessage() {}
+
+func (x *ArchiveEntryResult) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArchiveEntryResult.ProtoReflect.Descriptor instead.
+func (*ArchiveEntryResult) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *ArchiveEntryResult) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ArchiveEntryResult) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *ArchiveEntryResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ArchiveEntryResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type UploadArchiveResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*ArchiveEntryResult  `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	Created       int32                  `protobuf:"varint,2,opt,name=created,proto3" json:"created,omitempty"`
+	Failed        int32                  `protobuf:"varint,3,opt,name=failed,proto3" json:"failed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadArchiveResponse) Reset() {
+	*x = UploadArchiveResponse{}
+	mi := &file_file_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadArchiveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadArchiveResponse) ProtoMessage() {}
+
+func (x *UploadArchiveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadArchiveResponse.ProtoReflect.Descriptor instead.
+func (*UploadArchiveResponse) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *UploadArchiveResponse) GetEntries() []*ArchiveEntryResult {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *UploadArchiveResponse) GetCreated() int32 {
+	if x != nil {
+		return x.Created
+	}
+	return 0
+}
+
+func (x *UploadArchiveResponse) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+// Выгрузка всех файлов пользователя с историей версий. Архив собирается в фоне;
+// готовность проверяется через GetExportStatus.
+type StartExportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartExportRequest) Reset() {
+	*x = StartExportRequest{}
+	mi := &file_file_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartExportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartExportRequest) ProtoMessage() {}
+
+func (x *StartExportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartExportRequest.ProtoReflect.Descriptor instead.
+func (*StartExportRequest) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{47}
+}
+
+type StartExportResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Export        *ExportInfo            `protobuf:"bytes,1,opt,name=export,proto3" json:"export,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartExportResponse) Reset() {
+	*x = StartExportResponse{}
+	mi := &file_file_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartExportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartExportResponse) ProtoMessage() {}
+
+func (x *StartExportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartExportResponse.ProtoReflect.Descriptor instead.
+func (*StartExportResponse) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *StartExportResponse) GetExport() *ExportInfo {
+	if x != nil {
+		return x.Export
+	}
+	return nil
+}
+
+type ExportInfo struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	ExportId string                 `protobuf:"bytes,1,opt,name=export_id,json=exportId,proto3" json:"export_id,omitempty"`
+	// pending, running, ready или failed
+	Status string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	// размер архива в байтах, известен в состоянии ready
+	Size        int64  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Error       string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	CreatedAt   int64  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	CompletedAt int64  `protobuf:"varint,6,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	// после этого момента архив удаляется
+	ExpiresAt     int64 `protobuf:"varint,7,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportInfo) Reset() {
+	*x = ExportInfo{}
+	mi := &file_file_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportInfo) ProtoMessage() {}
+
+func (x *ExportInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportInfo.ProtoReflect.Descriptor instead.
+func (*ExportInfo) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *ExportInfo) GetExportId() string {
+	if x != nil {
+		return x.ExportId
+	}
+	return ""
+}
+
+func (x *ExportInfo) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ExportInfo) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ExportInfo) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ExportInfo) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *ExportInfo) GetCompletedAt() int64 {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return 0
+}
+
+func (x *ExportInfo) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type GetExportStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExportId      string                 `protobuf:"bytes,1,opt,name=export_id,json=exportId,proto3" json:"export_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetExportStatusRequest) Reset() {
+	*x = GetExportStatusRequest{}
+	mi := &file_file_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetExportStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetExportStatusRequest) ProtoMessage() {}
+
+func (x *GetExportStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetExportStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetExportStatusRequest) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *GetExportStatusRequest) GetExportId() string {
+	if x != nil {
+		return x.ExportId
+	}
+	return ""
+}
+
+type GetExportStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Export        *ExportInfo            `protobuf:"bytes,1,opt,name=export,proto3" json:"export,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetExportStatusResponse) Reset() {
+	*x = GetExportStatusResponse{}
+	mi := &file_file_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetExportStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetExportStatusResponse) ProtoMessage() {}
+
+func (x *GetExportStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetExportStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetExportStatusResponse) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *GetExportStatusResponse) GetExport() *ExportInfo {
+	if x != nil {
+		return x.Export
+	}
+	return nil
+}
+
+type DownloadExportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExportId      string                 `protobuf:"bytes,1,opt,name=export_id,json=exportId,proto3" json:"export_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadExportRequest) Reset() {
+	*x = DownloadExportRequest{}
+	mi := &file_file_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadExportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadExportRequest) ProtoMessage() {}
+
+func (x *DownloadExportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadExportRequest.ProtoReflect.Descriptor instead.
+func (*DownloadExportRequest) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *DownloadExportRequest) GetExportId() string {
+	if x != nil {
+		return x.ExportId
+	}
+	return ""
+}
+
+// Импорт архива выгрузки: первое сообщение — метаданные, дальше чанки ZIP.
+type ImportArchiveRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Data:
+	//
+	//	*ImportArchiveRequest_Metadata
+	//	*ImportArchiveRequest_Chunk
+	Data          isImportArchiveRequest_Data `protobuf_oneof:"data"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportArchiveRequest) Reset() {
+	*x = ImportArchiveRequest{}
+	mi := &file_file_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportArchiveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportArchiveRequest) ProtoMessage() {}
+
+func (x *ImportArchiveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportArchiveRequest.ProtoReflect.Descriptor instead.
+func (*ImportArchiveRequest) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *ImportArchiveRequest) GetData() isImportArchiveRequest_Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ImportArchiveRequest) GetMetadata() *ImportMetadata {
+	if x != nil {
+		if x, ok := x.Data.(*ImportArchiveRequest_Metadata); ok {
+			return x.Metadata
+		}
+	}
+	return nil
+}
+
+func (x *ImportArchiveRequest) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Data.(*ImportArchiveRequest_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+type isImportArchiveRequest_Data interface {
+	isImportArchiveRequest_Data()
+}
+
+type ImportArchiveRequest_Metadata struct {
+	Metadata *ImportMetadata `protobuf:"bytes,1,opt,name=metadata,proto3,oneof"`
+}
+
+type ImportArchiveRequest_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*ImportArchiveRequest_Metadata) isImportArchiveRequest_Data() {}
+
+func (*ImportArchiveRequest_Chunk) isImportArchiveRequest_Data() {}
+
+type ImportMetadata struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// восстановить права доступа других пользователей из архива
+	RestorePermissions bool `protobuf:"varint,1,opt,name=restore_permissions,json=restorePermissions,proto3" json:"restore_permissions,omitempty"`
+	// кому достаются файлы; 0 — вызывающему. Другого пользователя может указать только администратор
+	TargetUserId  uint32 `protobuf:"varint,2,opt,name=target_user_id,json=targetUserId,proto3" json:"target_user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportMetadata) Reset() {
+	*x = ImportMetadata{}
+	mi := &file_file_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportMetadata) ProtoMessage() {}
+
+func (x *ImportMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportMetadata.ProtoReflect.Descriptor instead.
+func (*ImportMetadata) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *ImportMetadata) GetRestorePermissions() bool {
+	if x != nil {
+		return x.RestorePermissions
+	}
+	return false
+}
+
+func (x *ImportMetadata) GetTargetUserId() uint32 {
+	if x != nil {
+		return x.TargetUserId
+	}
+	return 0
+}
+
+type ImportedFile struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// идентификатор файла в архиве
+	SourceFileId string `protobuf:"bytes,1,opt,name=source_file_id,json=sourceFileId,proto3" json:"source_file_id,omitempty"`
+	Name         string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	FileId       string `protobuf:"bytes,3,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Versions     int32  `protobuf:"varint,4,opt,name=versions,proto3" json:"versions,omitempty"`
+	// created, skipped или failed
+	Status        string `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportedFile) Reset() {
+	*x = ImportedFile{}
+	mi := &file_file_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportedFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportedFile) ProtoMessage() {}
+
+func (x *ImportedFile) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportedFile.ProtoReflect.Descriptor instead.
+func (*ImportedFile) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *ImportedFile) GetSourceFileId() string {
+	if x != nil {
+		return x.SourceFileId
+	}
+	return ""
+}
+
+func (x *ImportedFile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ImportedFile) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *ImportedFile) GetVersions() int32 {
+	if x != nil {
+		return x.Versions
+	}
+	return 0
+}
+
+func (x *ImportedFile) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ImportedFile) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ImportArchiveResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         []*ImportedFile        `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	Imported      int32                  `protobuf:"varint,2,opt,name=imported,proto3" json:"imported,omitempty"`
+	Failed        int32                  `protobuf:"varint,3,opt,name=failed,proto3" json:"failed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportArchiveResponse) Reset() {
+	*x = ImportArchiveResponse{}
+	mi := &file_file_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportArchiveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportArchiveResponse) ProtoMessage() {}
+
+func (x *ImportArchiveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_file_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportArchiveResponse.ProtoReflect.Descriptor instead.
+func (*ImportArchiveResponse) Descriptor() ([]byte, []int) {
+	return file_file_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *ImportArchiveResponse) GetFiles() []*ImportedFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *ImportArchiveResponse) GetImported() int32 {
+	if x != nil {
+		return x.Imported
+	}
+	return 0
+}
+
+func (x *ImportArchiveResponse) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+var File_file_proto protoreflect.FileDescriptor
+
+const file_file_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"file.proto\x12\x04file\"e\n" +
+	"\x11UploadFileRequest\x120\n" +
+	"\bmetadata\x18\x01 \x01(\v2\x12.file.FileMetadataH\x00R\bmetadata\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\x06\n" +
+	"\x04data\"E\n" +
+	"\fFileMetadata\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\"G\n" +
+	"\x12UploadFileResponse\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\".\n" +
+	"\x13DownloadFileRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\",\n" +
+	"\x14DownloadFileResponse\x12\x14\n" +
+	"\x05chunk\x18\x01 \x01(\fR\x05chunk\"\xd4\x01\n" +
+	"\x10ListFilesRequest\x12%\n" +
+	"\x0einclude_shared\x18\x01 \x01(\bR\rincludeShared\x12\x12\n" +
+	"\x04tags\x18\x02 \x03(\tR\x04tags\x12F\n" +
+	"\n" +
+	"properties\x18\x03 \x03(\v2&.file.ListFilesRequest.PropertiesEntryR\n" +
+	"properties\x1a=\n" +
+	"\x0fPropertiesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xf4\x02\n" +
+	"\bFileInfo\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\rR\aversion\x12!\n" +
+	"\fcontent_type\x18\x05 \x01(\tR\vcontentType\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\x03R\tupdatedAt\x12\x19\n" +
+	"\bis_owner\x18\b \x01(\bR\aisOwner\x12\x12\n" +
+	"\x04tags\x18\t \x03(\tR\x04tags\x12>\n" +
+	"\n" +
+	"properties\x18\n" +
+	" \x03(\v2\x1e.file.FileInfo.PropertiesEntryR\n" +
+	"properties\x1a=\n" +
+	"\x0fPropertiesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"9\n" +
+	"\x11ListFilesResponse\x12$\n" +
+	"\x05files\x18\x01 \x03(\v2\x0e.file.FileInfoR\x05files\",\n" +
+	"\x11DeleteFileRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\"H\n" +
+	"\x12DeleteFileResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"-\n" +
+	"\x12GetFileInfoRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\"9\n" +
+	"\x13GetFileInfoResponse\x12\"\n" +
+	"\x04file\x18\x01 \x01(\v2\x0e.file.FileInfoR\x04file\"G\n" +
+	"\x11RenameFileRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x19\n" +
+	"\bnew_name\x18\x02 \x01(\tR\anewName\".\n" +
+	"\x12RenameFileResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"S\n" +
+	"\x0fPermissionEntry\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x05R\x06userId\x12'\n" +
+	"\x0fpermission_type\x18\x02 \x01(\x05R\x0epermissionType\"m\n" +
+	"\x19SetFilePermissionsRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x127\n" +
+	"\vpermissions\x18\x02 \x03(\v2\x15.file.PermissionEntryR\vpermissions\"6\n" +
+	"\x1aSetFilePermissionsResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"1\n" +
+	"\x16GetFileVersionsRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\"\x93\x02\n" +
+	"\x0fFileVersionInfo\x12%\n" +
+	"\x0eversion_number\x18\x01 \x01(\rR\rversionNumber\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\x03R\tcreatedAt\x12\x1f\n" +
+	"\vstored_size\x18\x04 \x01(\x03R\n" +
+	"storedSize\x12!\n" +
+	"\flogical_size\x18\x05 \x01(\x03R\vlogicalSize\x12\x1f\n" +
+	"\vscan_status\x18\x06 \x01(\tR\n" +
+	"scanStatus\x12\x12\n" +
+	"\x04tier\x18\a \x01(\tR\x04tier\x12-\n" +
+	"\x12replication_status\x18\b \x01(\tR\x11replicationStatus\"L\n" +
+	"\x17GetFileVersionsResponse\x121\n" +
+	"\bversions\x18\x01 \x03(\v2\x15.file.FileVersionInfoR\bversions\"F\n" +
+	"\x11RevertFileRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\rR\aversion\"N\n" +
+	"\x12RevertFileResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1e\n" +
+	"\vnew_file_id\x18\x02 \x01(\tR\tnewFileId\"~\n" +
+	"\vWebhookInfo\x12\x1d\n" +
+	"\n" +
+	"webhook_id\x18\x01 \x01(\tR\twebhookId\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x1f\n" +
+	"\vevent_types\x18\x03 \x03(\tR\n" +
+	"eventTypes\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\x03R\tcreatedAt\"I\n" +
+	"\x14CreateWebhookRequest\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x1f\n" +
+	"\vevent_types\x18\x02 \x03(\tR\n" +
+	"eventTypes\"\\\n" +
+	"\x15CreateWebhookResponse\x12+\n" +
+	"\awebhook\x18\x01 \x01(\v2\x11.file.WebhookInfoR\awebhook\x12\x16\n" +
+	"\x06secret\x18\x02 \x01(\tR\x06secret\"\x15\n" +
+	"\x13ListWebhooksRequest\"E\n" +
+	"\x14ListWebhooksResponse\x12-\n" +
+	"\bwebhooks\x18\x01 \x03(\v2\x11.file.WebhookInfoR\bwebhooks\"\xca\x01\n" +
+	"\x0fWebhookDelivery\x12\x1f\n" +
+	"\vdelivery_id\x18\x01 \x01(\tR\n" +
+	"deliveryId\x12\x1d\n" +
+	"\n" +
+	"webhook_id\x18\x02 \x01(\tR\twebhookId\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x03 \x01(\tR\teventType\x12\x1a\n" +
+	"\battempts\x18\x04 \x01(\x05R\battempts\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x05 \x01(\tR\tlastError\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\x03R\tcreatedAt\"7\n" +
+	"\x16ListDeadLettersRequest\x12\x1d\n" +
+	"\n" +
+	"webhook_id\x18\x01 \x01(\tR\twebhookId\"P\n" +
+	"\x17ListDeadLettersResponse\x125\n" +
+	"\n" +
+	"deliveries\x18\x01 \x03(\v2\x15.file.WebhookDeliveryR\n" +
+	"deliveries\":\n" +
+	"\x17RedeliverWebhookRequest\x12\x1f\n" +
+	"\vdelivery_id\x18\x01 \x01(\tR\n" +
+	"deliveryId\"4\n" +
+	"\x18RedeliverWebhookResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"X\n" +
+	"\x12SearchFilesRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\"`\n" +
+	"\fSearchResult\x12\"\n" +
+	"\x04file\x18\x01 \x01(\v2\x0e.file.FileInfoR\x04file\x12\x12\n" +
+	"\x04rank\x18\x02 \x01(\x02R\x04rank\x12\x18\n" +
+	"\asnippet\x18\x03 \x01(\tR\asnippet\"C\n" +
+	"\x13SearchFilesResponse\x12,\n" +
+	"\aresults\x18\x01 \x03(\v2\x12.file.SearchResultR\aresults\"A\n" +
+	"\x12SetFileTagsRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x12\n" +
+	"\x04tags\x18\x02 \x03(\tR\x04tags\"C\n" +
+	"\x13SetFileTagsResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x12\n" +
+	"\x04tags\x18\x02 \x03(\tR\x04tags\"\xc2\x01\n" +
+	"\x18SetFilePropertiesRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12N\n" +
+	"\n" +
+	"properties\x18\x02 \x03(\v2..file.SetFilePropertiesRequest.PropertiesEntryR\n" +
+	"properties\x1a=\n" +
+	"\x0fPropertiesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"5\n" +
+	"\x19SetFilePropertiesResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"B\n" +
+	"\x13GetThumbnailRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\rR\x04size\"a\n" +
+	"\x14GetThumbnailResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\rR\x04size\"3\n" +
+	"\x16DownloadArchiveRequest\x12\x19\n" +
+	"\bfile_ids\x18\x01 \x03(\tR\afileIds\"/\n" +
+	"\x17DownloadArchiveResponse\x12\x14\n" +
+	"\x05chunk\x18\x01 \x01(\fR\x05chunk\"k\n" +
+	"\x14UploadArchiveRequest\x123\n" +
+	"\bmetadata\x18\x01 \x01(\v2\x15.file.ArchiveMetadataH\x00R\bmetadata\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\x06\n" +
+	"\x04data\"=\n" +
+	"\x0fArchiveMetadata\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\"o\n" +
+	"\x12ArchiveEntryResult\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x17\n" +
+	"\afile_id\x18\x02 \x01(\tR\x06fileId\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"}\n" +
+	"\x15UploadArchiveResponse\x122\n" +
+	"\aentries\x18\x01 \x03(\v2\x18.file.ArchiveEntryResultR\aentries\x12\x18\n" +
+	"\acreated\x18\x02 \x01(\x05R\acreated\x12\x16\n" +
+	"\x06failed\x18\x03 \x01(\x05R\x06failed\"\x14\n" +
+	"\x12StartExportRequest\"?\n" +
+	"\x13StartExportResponse\x12(\n" +
+	"\x06export\x18\x01 \x01(\v2\x10.file.ExportInfoR\x06export\"\xcc\x01\n" +
+	"\n" +
+	"ExportInfo\x12\x1b\n" +
+	"\texport_id\x18\x01 \x01(\tR\bexportId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x12!\n" +
+	"\fcompleted_at\x18\x06 \x01(\x03R\vcompletedAt\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\a \x01(\x03R\texpiresAt\"5\n" +
+	"\x16GetExportStatusRequest\x12\x1b\n" +
+	"\texport_id\x18\x01 \x01(\tR\bexportId\"C\n" +
+	"\x17GetExportStatusResponse\x12(\n" +
+	"\x06export\x18\x01 \x01(\v2\x10.file.ExportInfoR\x06export\"4\n" +
+	"\x15DownloadExportRequest\x12\x1b\n" +
+	"\texport_id\x18\x01 \x01(\tR\bexportId\"j\n" +
+	"\x14ImportArchiveRequest\x122\n" +
+	"\bmetadata\x18\x01 \x01(\v2\x14.file.ImportMetadataH\x00R\bmetadata\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\x06\n" +
+	"\x04data\"g\n" +
+	"\x0eImportMetadata\x12/\n" +
+	"\x13restore_permissions\x18\x01 \x01(\bR\x12restorePermissions\x12$\n" +
+	"\x0etarget_user_id\x18\x02 \x01(\rR\ftargetUserId\"\xab\x01\n" +
+	"\fImportedFile\x12$\n" +
+	"\x0esource_file_id\x18\x01 \x01(\tR\fsourceFileId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x17\n" +
+	"\afile_id\x18\x03 \x01(\tR\x06fileId\x12\x1a\n" +
+	"\bversions\x18\x04 \x01(\x05R\bversions\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\"u\n" +
+	"\x15ImportArchiveResponse\x12(\n" +
+	"\x05files\x18\x01 \x03(\v2\x12.file.ImportedFileR\x05files\x12\x1a\n" +
+	"\bimported\x18\x02 \x01(\x05R\bimported\x12\x16\n" +
+	"\x06failed\x18\x03 \x01(\x05R\x06failed2\xb5\r\n" +
+	"\vFileService\x12A\n" +
+	"\n" +
+	"UploadFile\x12\x17.file.UploadFileRequest\x1a\x18.file.UploadFileResponse(\x01\x12G\n" +
+	"\fDownloadFile\x12\x19.file.DownloadFileRequest\x1a\x1a.file.DownloadFileResponse0\x01\x12<\n" +
+	"\tListFiles\x12\x16.file.ListFilesRequest\x1a\x17.file.ListFilesResponse\x12?\n" +
+	"\n" +
+	"DeleteFile\x12\x17.file.DeleteFileRequest\x1a\x18.file.DeleteFileResponse\x12B\n" +
+	"\vGetFileInfo\x12\x18.file.GetFileInfoRequest\x1a\x19.file.GetFileInfoResponse\x12?\n" +
+	"\n" +
+	"RenameFile\x12\x17.file.RenameFileRequest\x1a\x18.file.RenameFileResponse\x12W\n" +
+	"\x12SetFilePermissions\x12\x1f.file.SetFilePermissionsRequest\x1a .file.SetFilePermissionsResponse\x12N\n" +
+	"\x0fGetFileVersions\x12\x1c.file.GetFileVersionsRequest\x1a\x1d.file.GetFileVersionsResponse\x12F\n" +
+	"\x11RevertFileVersion\x12\x17.file.RevertFileRequest\x1a\x18.file.RevertFileResponse\x12H\n" +
+	"\rCreateWebhook\x12\x1a.file.CreateWebhookRequest\x1a\x1b.file.CreateWebhookResponse\x12E\n" +
+	"\fListWebhooks\x12\x19.file.ListWebhooksRequest\x1a\x1a.file.ListWebhooksResponse\x12N\n" +
+	"\x0fListDeadLetters\x12\x1c.file.ListDeadLettersRequest\x1a\x1d.file.ListDeadLettersResponse\x12Q\n" +
+	"\x10RedeliverWebhook\x12\x1d.file.RedeliverWebhookRequest\x1a\x1e.file.RedeliverWebhookResponse\x12B\n" +
+	"\vSearchFiles\x12\x18.file.SearchFilesRequest\x1a\x19.file.SearchFilesResponse\x12B\n" +
+	"\vSetFileTags\x12\x18.file.SetFileTagsRequest\x1a\x19.file.SetFileTagsResponse\x12T\n" +
+	"\x11SetFileProperties\x12\x1e.file.SetFilePropertiesRequest\x1a\x1f.file.SetFilePropertiesResponse\x12E\n" +
+	"\fGetThumbnail\x12\x19.file.GetThumbnailRequest\x1a\x1a.file.GetThumbnailResponse\x12P\n" +
+	"\x0fDownloadArchive\x12\x1c.file.DownloadArchiveRequest\x1a\x1d.file.DownloadArchiveResponse0\x01\x12J\n" +
+	"\rUploadArchive\x12\x1a.file.UploadArchiveRequest\x1a\x1b.file.UploadArchiveResponse(\x01\x12B\n" +
+	"\vStartExport\x12\x18.file.StartExportRequest\x1a\x19.file.StartExportResponse\x12N\n" +
+	"\x0fGetExportStatus\x12\x1c.file.GetExportStatusRequest\x1a\x1d.file.GetExportStatusResponse\x12N\n" +
+	"\x0eDownloadExport\x12\x1b.file.DownloadExportRequest\x1a\x1d.file.DownloadArchiveResponse0\x01\x12J\n" +
+	"\rImportArchive\x12\x1a.file.ImportArchiveRequest\x1a\x1b.file.ImportArchiveResponse(\x01B\x18Z\x16./proto-generate/;fileb\x06proto3"
+
+var (
+	file_file_proto_rawDescOnce sync.Once
+	file_file_proto_rawDescData []byte
+)
+
+func file_file_proto_rawDescGZIP() []byte {
+	file_file_proto_rawDescOnce.Do(func() {
+		file_file_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_file_proto_rawDesc), len(file_file_proto_rawDesc)))
+	})
+	return file_file_proto_rawDescData
+}
+
+var file_file_proto_msgTypes = make([]protoimpl.MessageInfo, 60)
+var file_file_proto_goTypes = []any{
+	(*UploadFileRequest)(nil),          // 0: file.UploadFileRequest
+	(*FileMetadata)(nil),               // 1: file.FileMetadata
+	(*UploadFileResponse)(nil),         // 2: file.UploadFileResponse
+	(*DownloadFileRequest)(nil),        // 3: file.DownloadFileRequest
+	(*DownloadFileResponse)(nil),       // 4: file.DownloadFileResponse
+	(*ListFilesRequest)(nil),           // 5: file.ListFilesRequest
+	(*FileInfo)(nil),                   // 6: file.FileInfo
+	(*ListFilesResponse)(nil),          // 7: file.ListFilesResponse
+	(*DeleteFileRequest)(nil),          // 8: file.DeleteFileRequest
+	(*DeleteFileResponse)(nil),         // 9: file.DeleteFileResponse
+	(*GetFileInfoRequest)(nil),         // 10: file.GetFileInfoRequest
+	(*GetFileInfoResponse)(nil),        // 11: file.GetFileInfoResponse
+	(*RenameFileRequest)(nil),          // 12: file.RenameFileRequest
+	(*RenameFileResponse)(nil),         // 13: file.RenameFileResponse
+	(*PermissionEntry)(nil),            // 14: file.PermissionEntry
+	(*SetFilePermissionsRequest)(nil),  // 15: file.SetFilePermissionsRequest
+	(*SetFilePermissionsResponse)(nil), // 16: file.SetFilePermissionsResponse
+	(*GetFileVersionsRequest)(nil),     // 17: file.GetFileVersionsRequest
+	(*FileVersionInfo)(nil),            // 18: file.FileVersionInfo
+	(*GetFileVersionsResponse)(nil),    // 19: file.GetFileVersionsResponse
+	(*RevertFileRequest)(nil),          // 20: file.RevertFileRequest
+	(*RevertFileResponse)(nil),         // 21: file.RevertFileResponse
+	(*WebhookInfo)(nil),                // 22: file.WebhookInfo
+	(*CreateWebhookRequest)(nil),       // 23: file.CreateWebhookRequest
+	(*CreateWebhookResponse)(nil),      // 24: file.CreateWebhookResponse
+	(*ListWebhooksRequest)(nil),        // 25: file.ListWebhooksRequest
+	(*ListWebhooksResponse)(nil),       // 26: file.ListWebhooksResponse
+	(*WebhookDelivery)(nil),            // 27: file.WebhookDelivery
+	(*ListDeadLettersRequest)(nil),     // 28: file.ListDeadLettersRequest
+	(*ListDeadLettersResponse)(nil),    // 29: file.ListDeadLettersResponse
+	(*RedeliverWebhookRequest)(nil),    // 30: file.RedeliverWebhookRequest
+	(*RedeliverWebhookResponse)(nil),   // 31: file.RedeliverWebhookResponse
+	(*SearchFilesRequest)(nil),         // 32: file.SearchFilesRequest
+	(*SearchResult)(nil),               // 33: file.SearchResult
+	(*SearchFilesResponse)(nil),        // 34: file.SearchFilesResponse
+	(*SetFileTagsRequest)(nil),         // 35: file.SetFileTagsRequest
+	(*SetFileTagsResponse)(nil),        // 36: file.SetFileTagsResponse
+	(*SetFilePropertiesRequest)(nil),   // 37: file.SetFilePropertiesRequest
+	(*SetFilePropertiesResponse)(nil),  // 38: file.SetFilePropertiesResponse
+	(*GetThumbnailRequest)(nil),        // 39: file.GetThumbnailRequest
+	(*GetThumbnailResponse)(nil),       // 40: file.GetThumbnailResponse
+	(*DownloadArchiveRequest)(nil),     // 41: file.DownloadArchiveRequest
+	(*DownloadArchiveResponse)(nil),    // 42: file.DownloadArchiveResponse
+	(*UploadArchiveRequest)(nil),       // 43: file.UploadArchiveRequest
+	(*ArchiveMetadata)(nil),            // 44: file.ArchiveMetadata
+	(*ArchiveEntryResult)(nil),         // 45: file.ArchiveEntryResult
+	(*UploadArchiveResponse)(nil),      // 46: file.UploadArchiveResponse
+	(*StartExportRequest)(nil),         // 47: file.StartExportRequest
+	(*StartExportResponse)(nil),        // 48: file.StartExportResponse
+	(*ExportInfo)(nil),                 // 49: file.ExportInfo
+	(*GetExportStatusRequest)(nil),     // 50: file.GetExportStatusRequest
+	(*GetExportStatusResponse)(nil),    // 51: file.GetExportStatusResponse
+	(*DownloadExportRequest)(nil),      // 52: file.DownloadExportRequest
+	(*ImportArchiveRequest)(nil),       // 53: file.ImportArchiveRequest
+	(*ImportMetadata)(nil),             // 54: file.ImportMetadata
+	(*ImportedFile)(nil),               // 55: file.ImportedFile
+	(*ImportArchiveResponse)(nil),      // 56: file.ImportArchiveResponse
+	nil,                                // 57: file.ListFilesRequest.PropertiesEntry
+	nil,                                // 58: file.FileInfo.PropertiesEntry
+	nil,                                // 59: file.SetFilePropertiesRequest.PropertiesEntry
+}
+var file_file_proto_depIdxs = []int32{
+	1,  // 0: file.UploadFileRequest.metadata:type_name -> file.FileMetadata
+	57, // 1: file.ListFilesRequest.properties:type_name -> file.ListFilesRequest.PropertiesEntry
+	58, // 2: file.FileInfo.properties:type_name -> file.FileInfo.PropertiesEntry
+	6,  // 3: file.ListFilesResponse.files:type_name -> file.FileInfo
+	6,  // 4: file.GetFileInfoResponse.file:type_name -> file.FileInfo
+	14, // 5: file.SetFilePermissionsRequest.permissions:type_name -> file.PermissionEntry
+	18, // 6: file.GetFileVersionsResponse.versions:type_name -> file.FileVersionInfo
+	22, // 7: file.CreateWebhookResponse.webhook:type_name -> file.WebhookInfo
+	22, // 8: file.ListWebhooksResponse.webhooks:type_name -> file.WebhookInfo
+	27, // 9: file.ListDeadLettersResponse.deliveries:type_name -> file.WebhookDelivery
+	6,  // 10: file.SearchResult.file:type_name -> file.FileInfo
+	33, // 11: file.SearchFilesResponse.results:type_name -> file.SearchResult
+	59, // 12: file.SetFilePropertiesRequest.properties:type_name -> file.SetFilePropertiesRequest.PropertiesEntry
+	44, // 13: file.UploadArchiveRequest.metadata:type_name -> file.ArchiveMetadata
+	45, // 14: file.UploadArchiveResponse.entries:type_name -> file.ArchiveEntryResult
+	49, // 15: file.StartExportResponse.export:type_name -> file.ExportInfo
+	49, // 16: file.GetExportStatusResponse.export:type_name -> file.ExportInfo
+	54, // 17: file.ImportArchiveRequest.metadata:type_name -> file.ImportMetadata
+	55, // 18: file.ImportArchiveResponse.files:type_name -> file.ImportedFile
+	0,  // 19: file.FileService.UploadFile:input_type -> file.UploadFileRequest
+	3,  // 20: file.FileService.DownloadFile:input_type -> file.DownloadFileRequest
+	5,  // 21: file.FileService.ListFiles:input_type -> file.ListFilesRequest
+	8,  // 22: file.FileService.DeleteFile:input_type -> file.DeleteFileRequest
+	10, // 23: file.FileService.GetFileInfo:input_type -> file.GetFileInfoRequest
+	12, // 24: file.FileService.RenameFile:input_type -> file.RenameFileRequest
+	15, // 25: file.FileService.SetFilePermissions:input_type -> file.SetFilePermissionsRequest
+	17, // 26: file.FileService.GetFileVersions:input_type -> file.GetFileVersionsRequest
+	20, // 27: file.FileService.RevertFileVersion:input_type -> file.RevertFileRequest
+	23, // 28: file.FileService.CreateWebhook:input_type -> file.CreateWebhookRequest
+	25, // 29: file.FileService.ListWebhooks:input_type -> file.ListWebhooksRequest
+	28, // 30: file.FileService.ListDeadLetters:input_type -> file.ListDeadLettersRequest
+	30, // 31: file.FileService.RedeliverWebhook:input_type -> file.RedeliverWebhookRequest
+	32, // 32: file.FileService.SearchFiles:input_type -> file.SearchFilesRequest
+	35, // 33: file.FileService.SetFileTags:input_type -> file.SetFileTagsRequest
+	37, // 34: file.FileService.SetFileProperties:input_type -> file.SetFilePropertiesRequest
+	39, // 35: file.FileService.GetThumbnail:input_type -> file.GetThumbnailRequest
+	41, // 36: file.FileService.DownloadArchive:input_type -> file.DownloadArchiveRequest
+	43, // 37: file.FileService.UploadArchive:input_type -> file.UploadArchiveRequest
+	47, // 38: file.FileService.StartExport:input_type -> file.StartExportRequest
+	50, // 39: file.FileService.GetExportStatus:input_type -> file.GetExportStatusRequest
+	52, // 40: file.FileService.DownloadExport:input_type -> file.DownloadExportRequest
+	53, // 41: file.FileService.ImportArchive:input_type -> file.ImportArchiveRequest
+	2,  // 42: file.FileService.UploadFile:output_type -> file.UploadFileResponse
+	4,  // 43: file.FileService.DownloadFile:output_type -> file.DownloadFileResponse
+	7,  // 44: file.FileService.ListFiles:output_type -> file.ListFilesResponse
+	9,  // 45: file.FileService.DeleteFile:output_type -> file.DeleteFileResponse
+	11, // 46: file.FileService.GetFileInfo:output_type -> file.GetFileInfoResponse
+	13, // 47: file.FileService.RenameFile:output_type -> file.RenameFileResponse
+	16, // 48: file.FileService.SetFilePermissions:output_type -> file.SetFilePermissionsResponse
+	19, // 49: file.FileService.GetFileVersions:output_type -> file.GetFileVersionsResponse
+	21, // 50: file.FileService.RevertFileVersion:output_type -> file.RevertFileResponse
+	24, // 51: file.FileService.CreateWebhook:output_type -> file.CreateWebhookResponse
+	26, // 52: file.FileService.ListWebhooks:output_type -> file.ListWebhooksResponse
+	29, // 53: file.FileService.ListDeadLetters:output_type -> file.ListDeadLettersResponse
+	31, // 54: file.FileService.RedeliverWebhook:output_type -> file.RedeliverWebhookResponse
+	34, // 55: file.FileService.SearchFiles:output_type -> file.SearchFilesResponse
+	36, // 56: file.FileService.SetFileTags:output_type -> file.SetFileTagsResponse
+	38, // 57: file.FileService.SetFileProperties:output_type -> file.SetFilePropertiesResponse
+	40, // 58: file.FileService.GetThumbnail:output_type -> file.GetThumbnailResponse
+	42, // 59: file.FileService.DownloadArchive:output_type -> file.DownloadArchiveResponse
+	46, // 60: file.FileService.UploadArchive:output_type -> file.UploadArchiveResponse
+	48, // 61: file.FileService.StartExport:output_type -> file.StartExportResponse
+	51, // 62: file.FileService.GetExportStatus:output_type -> file.GetExportStatusResponse
+	42, // 63: file.FileService.DownloadExport:output_type -> file.DownloadArchiveResponse
+	56, // 64: file.FileService.ImportArchive:output_type -> file.ImportArchiveResponse
+	42, // [42:65] is the sub-list for method output_type
+	19, // [19:42] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
+}
+
+func init() { file_file_proto_init() }
+func file_file_proto_init() {
+	if File_file_proto != nil {
+		return
+	}
+	file_file_proto_msgTypes[0].OneofWrappers = []any{
+		(*UploadFileRequest_Metadata)(nil),
+		(*UploadFileRequest_Chunk)(nil),
+	}
+	file_file_proto_msgTypes[43].OneofWrappers = []any{
+		(*UploadArchiveRequest_Metadata)(nil),
+		(*UploadArchiveRequest_Chunk)(nil),
+	}
+	file_file_proto_msgTypes[53].OneofWrappers = []any{
+		(*ImportArchiveRequest_Metadata)(nil),
+		(*ImportArchiveRequest_Chunk)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -1332,7 +3609,7 @@ func file_file_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_file_proto_rawDesc), len(file_file_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   22,
+			NumMessages:   60,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -4,12 +4,23 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http"
 	auth "registration-service/api/authproto/proto-generate"
+	"registration-service/internal/clientip"
 	"registration-service/internal/config"
+	"registration-service/internal/encryption"
 	"registration-service/internal/handler/authHandler"
+	"registration-service/internal/jwtkeys"
+	"registration-service/internal/mail"
 	"registration-service/internal/repository/BlackListRepo"
+	"registration-service/internal/repository/loginAttemptRepo"
+	"registration-service/internal/repository/mfaRepo"
 	"registration-service/internal/repository/refreshToken"
+	"registration-service/internal/repository/resetToken"
+	"registration-service/internal/repository/sessionRepo"
+	"registration-service/internal/repository/signingKeyRepo"
 	"registration-service/internal/repository/userRepo"
+	"registration-service/internal/repository/verificationRepo"
 	"registration-service/internal/service/authService"
 	"registration-service/pkg/database/postgres"
 	"registration-service/pkg/database/redis"
@@ -32,16 +43,63 @@ func main() {
 		logger.GetLogger(ctx).Fatal("Failed to connect to database", zap.Error(err))
 	}
 	redisClient := redis.New(cfg.Redis)
+	mailer, err := mail.New(cfg.Mail)
+	if err != nil {
+		logger.GetLogger(ctx).Fatal("Failed to initialize mailer", zap.Error(err))
+	}
+
+	var wrapper encryption.KeyProvider
+	if cfg.Encryption.Enabled() {
+		localKeys, err := encryption.NewLocalKeyProvider(cfg.Encryption)
+		if err != nil {
+			logger.GetLogger(ctx).Fatal("Failed to load encryption keys", zap.Error(err))
+		}
+		wrapper = localKeys
+	} else {
+		logger.GetLogger(ctx).Warn("Signing keys are stored unencrypted: no master keys configured")
+	}
+	keyRing, err := jwtkeys.NewKeyRing(signingKeyRepo.New(conn), wrapper, cfg.SigningKeys)
+	if err != nil {
+		logger.GetLogger(ctx).Fatal("Invalid signing key config", zap.Error(err))
+	}
+	if err := keyRing.Load(ctx); err != nil {
+		logger.GetLogger(ctx).Fatal("Failed to load signing keys", zap.Error(err))
+	}
+	go keyRing.Run(ctx)
+
+	mux := http.NewServeMux()
+	mux.Handle(jwtkeys.JWKSPath, keyRing)
+	go func() {
+		if err := http.ListenAndServe(fmt.Sprintf(":%s", cfg.HTTPPort), mux); err != nil {
+			logger.GetLogger(ctx).Fatal("Failed to serve JWKS", zap.Error(err))
+		}
+	}()
 
 	authSvc := authService.New(
 		userRepo.New(conn),
 		cfg.JWTSecret,
+		keyRing,
 		refreshToken.New(redisClient),
+		sessionRepo.New(redisClient),
 		BlackListRepo.NewBlackListRepo(redisClient),
+		verificationRepo.New(redisClient),
+		resetToken.New(redisClient),
+		mfaRepo.New(redisClient),
+		loginAttemptRepo.New(redisClient),
+		mailer,
+		cfg.Verification,
+		cfg.PasswordReset,
+		cfg.MFA,
+		cfg.LoginThrottle,
 	)
 
+	clientIP, err := clientip.NewResolver(cfg.ClientIP)
+	if err != nil {
+		logger.GetLogger(ctx).Fatal("Invalid trusted proxies", zap.Error(err))
+	}
+
 	server := grpc.NewServer()
-	auth.RegisterAuthServiceServer(server, authHandler.New(authSvc))
+	auth.RegisterAuthServiceServer(server, authHandler.New(authSvc, clientIP))
 
 	lis, _ := net.Listen("tcp", fmt.Sprintf(":%s", cfg.GRPCPort))
 	if err := server.Serve(lis); err != nil {
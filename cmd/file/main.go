@@ -6,12 +6,30 @@ import (
 	"net"
 	auth "registration-service/api/authproto/proto-generate"
 	fileproto "registration-service/api/fileproto/proto-generate"
-	"registration-service/internal/MinIO"
 	"registration-service/internal/config"
+	"registration-service/internal/encryption"
 	"registration-service/internal/handler/fileHandler"
+	"registration-service/internal/model/fileInfo"
+	"registration-service/internal/reconcile"
+	"registration-service/internal/replication"
+	"registration-service/internal/repository/BlackListRepo"
+	"registration-service/internal/repository/exportRepo"
 	"registration-service/internal/repository/fileRepo"
+	"registration-service/internal/repository/webhookRepo"
+	"registration-service/internal/scan"
+	"registration-service/internal/search"
 	"registration-service/internal/service/fileService"
+	"registration-service/internal/storage"
+	"registration-service/internal/storage/driver"
+	"registration-service/internal/takeout"
+	"registration-service/internal/thumbnail"
+	"registration-service/internal/tiering"
+	"registration-service/internal/tokenverify"
+	"registration-service/internal/upload"
+	"registration-service/internal/versionStore"
+	"registration-service/internal/webhook"
 	"registration-service/pkg/database/postgres"
+	"registration-service/pkg/database/redis"
 	"registration-service/pkg/logger"
 	"registration-service/pkg/middleware"
 
@@ -37,8 +55,7 @@ func main() {
 	}
 	log.Info("Config loaded successfully",
 		zap.String("auth_service_addr", cfg.AuthServiceAddr),
-		zap.String("minio_endpoint", cfg.MinIO.MinioEndpoint),
-		zap.String("minio_bucket", cfg.MinIO.BucketName))
+		zap.String("storage_driver", cfg.Storage.Driver))
 
 	authConn, err := grpc.Dial(
 		cfg.AuthServiceAddr,
@@ -52,29 +69,115 @@ func main() {
 
 	authClient := auth.NewAuthServiceClient(authConn)
 
+	// токены проверяются локально: ключи из JWKS сервиса авторизации, отзывы из Redis
+	revocations := tokenverify.NewRevocationList(BlackListRepo.NewBlackListRepo(redis.New(cfg.Redis)))
+	go revocations.Run(ctx)
+	verifier := tokenverify.New(tokenverify.GRPCKeySource(authClient), tokenverify.GRPCFallback(authClient),
+		revocations, cfg.TokenVerification)
+	go verifier.Run(ctx)
+
 	conn, err := postgres.New(cfg.Postgres)
 	if err != nil {
 		log.Fatal("Error connecting to postgres", zap.Error(err))
 	}
 	log.Info("Connected to postgres")
 
-	minioClient, err := MinIO.New(cfg.MinIO)
+	objects, err := driver.New(cfg.Storage, cfg.MinIO)
 	if err != nil {
-		log.Fatal("Failed to initialize MinIO client", zap.Error(err))
+		log.Fatal("Failed to initialize object storage", zap.Error(err))
+	}
+	log.Info("Object storage initialized successfully", zap.String("driver", cfg.Storage.Driver))
+
+	var keys encryption.KeyProvider
+	if cfg.Encryption.Enabled() {
+		localKeys, err := encryption.NewLocalKeyProvider(cfg.Encryption)
+		if err != nil {
+			log.Fatal("Failed to load encryption keys", zap.Error(err))
+		}
+		keys = localKeys
+		log.Info("Object encryption enabled", zap.String("active_key_id", localKeys.ActiveKeyID()))
+	} else {
+		log.Warn("Object encryption disabled: no master keys configured")
+	}
+	if err := cfg.Compression.Validate(); err != nil {
+		log.Fatal("Invalid compression config", zap.Error(err))
+	}
+	var cold storage.ObjectStore
+	if cfg.Tiering.Enabled {
+		cold, err = driver.NewCold(cfg.Storage, cfg.MinIO)
+		if err != nil {
+			log.Fatal("Failed to initialize cold storage tier", zap.Error(err))
+		}
+		log.Info("Cold storage tier enabled", zap.Duration("cold_after", cfg.Tiering.ColdAfter))
 	}
-	log.Info("MinIO client initialized successfully",
-		zap.String("endpoint", cfg.MinIO.MinioEndpoint),
-		zap.String("bucket", cfg.MinIO.BucketName))
+	store := versionStore.New(objects, cold, keys, cfg.Compression)
 
+	filesRepo := fileRepo.New(conn, cfg.Replication.Enabled)
+	hooksRepo := webhookRepo.New(conn)
+	exportsRepo := exportRepo.New(conn)
+	indexer := search.NewIndexer(filesRepo, store, cfg.Search)
+	thumbnails := thumbnail.NewWorker(store, cfg.Thumbnail)
+	if cfg.Scan.ClamdAddr == "" {
+		log.Warn("Malware scanning disabled: SCAN_CLAMD_ADDR is not set")
+	}
+	scanner := scan.NewWorker(filesRepo, store, scan.NewScanner(cfg.Scan), cfg.Scan, func(version *fileInfo.FileVersion) {
+		indexer.Enqueue(version.FileID)
+		thumbnails.Enqueue(version)
+	})
+	recovery := upload.NewRecoveryWorker(filesRepo, store, cfg.Upload, scanner.Enqueue)
+	tiers := tiering.NewWorker(filesRepo, store, cfg.Tiering)
+	exports := takeout.NewWorker(filesRepo, exportsRepo, store, cfg.Export, cfg.Archive)
 	fileSvc := fileService.New(
-		fileRepo.New(conn),
+		filesRepo,
 		authClient,
-		minioClient,
+		store,
+		hooksRepo,
+		indexer,
+		thumbnails,
+		scanner,
+		tiers,
+		exportsRepo,
+		exports,
+		cfg.Archive,
 	)
 
+	go webhook.NewDispatcher(hooksRepo, cfg.Webhook).Run(ctx)
+	log.Info("Webhook dispatcher started")
+	go indexer.Run(ctx)
+	log.Info("Search indexer started")
+	go thumbnails.Run(ctx)
+	log.Info("Thumbnail worker started")
+	go scanner.Run(ctx)
+	log.Info("Scan worker started")
+	go recovery.Run(ctx)
+	log.Info("Upload recovery started", zap.Duration("pending_timeout", cfg.Upload.PendingTimeout))
+	go tiers.Run(ctx)
+	go exports.Run(ctx)
+	log.Info("Export worker started", zap.Duration("ttl", cfg.Export.TTL))
+	if cfg.Tiering.Enabled {
+		log.Info("Storage tiering started", zap.Duration("interval", cfg.Tiering.Interval))
+	}
+	if cfg.Replication.Enabled {
+		if err := cfg.Replication.Validate(cfg.Storage, cfg.MinIO); err != nil {
+			log.Fatal("Invalid replication config", zap.Error(err))
+		}
+		replica, err := replication.NewReplica(cfg.Replication)
+		if err != nil {
+			log.Fatal("Failed to initialize replica storage", zap.Error(err))
+		}
+		go replication.NewWorker(filesRepo, store, replica, cfg.Replication).Run(ctx)
+		log.Info("Replication started", zap.String("replica_driver", cfg.Replication.Storage.Driver))
+	}
+	if cfg.Reconcile.Interval > 0 {
+		go reconcile.New(filesRepo, objects, cfg.Reconcile).Run(ctx)
+		log.Info("Storage reconciliation started",
+			zap.Duration("interval", cfg.Reconcile.Interval),
+			zap.Bool("repair", cfg.Reconcile.Repair))
+	}
+
 	server := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(middleware.AuthInterceptor(authClient)),
-		grpc.ChainStreamInterceptor(middleware.StreamAuthInterceptor(authClient)),
+		grpc.ChainUnaryInterceptor(middleware.AuthInterceptor(verifier)),
+		grpc.ChainStreamInterceptor(middleware.StreamAuthInterceptor(verifier)),
 	)
 	fileproto.RegisterFileServiceServer(server, fileHandler.NewFileHandler(fileSvc))
 
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"registration-service/internal/config"
+	"registration-service/internal/encryption"
+	"registration-service/internal/repository/fileRepo"
+	"registration-service/pkg/database/postgres"
+	"registration-service/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// keyrotate переупаковывает ключи данных версий активным мастер-ключом (ENCRYPTION_ACTIVE_KEY_ID).
+// Объекты в хранилище не перезаписываются. Старый мастер-ключ должен оставаться в
+// ENCRYPTION_MASTER_KEYS, пока команда не завершится без ошибок.
+func main() {
+	batch := flag.Int("batch", 500, "versions per batch")
+	dryRun := flag.Bool("dry-run", false, "only count versions that need re-wrapping")
+	flag.Parse()
+
+	ctx := context.Background()
+	var err error
+	ctx, err = logger.New(ctx)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize logger: %v", err))
+	}
+	log := logger.GetLogger(ctx)
+
+	cfg, err := config.LoadFileConfig()
+	if err != nil {
+		log.Fatal("Error loading config", zap.Error(err))
+	}
+	if !cfg.Encryption.Enabled() {
+		log.Fatal("No master keys configured")
+	}
+	keys, err := encryption.NewLocalKeyProvider(cfg.Encryption)
+	if err != nil {
+		log.Fatal("Failed to load encryption keys", zap.Error(err))
+	}
+
+	conn, err := postgres.New(cfg.Postgres)
+	if err != nil {
+		log.Fatal("Error connecting to postgres", zap.Error(err))
+	}
+	defer conn.Close()
+	filesRepo := fileRepo.New(conn, false)
+
+	var afterID uint32
+	var rewrapped, failed int
+	for {
+		versions, err := filesRepo.ListVersionsToRewrap(ctx, keys.ActiveKeyID(), afterID, *batch)
+		if err != nil {
+			log.Fatal("Failed to list versions", zap.Error(err))
+		}
+		if len(versions) == 0 {
+			break
+		}
+		for _, version := range versions {
+			afterID = version.ID
+			if *dryRun {
+				rewrapped++
+				continue
+			}
+			keyID, wrapped, err := encryption.Rewrap(ctx, keys, version.KeyID, version.WrappedKey)
+			if err != nil {
+				failed++
+				log.Error("Failed to re-wrap data key", zap.String("storage_key", version.StorageKey), zap.Error(err))
+				continue
+			}
+			updated, err := filesRepo.UpdateWrappedKey(ctx, version.ID, version.KeyID, keyID, wrapped)
+			if err != nil {
+				failed++
+				log.Error("Failed to save data key", zap.String("storage_key", version.StorageKey), zap.Error(err))
+				continue
+			}
+			if updated {
+				rewrapped++
+			}
+		}
+	}
+
+	log.Info("Key rotation finished",
+		zap.String("active_key_id", keys.ActiveKeyID()),
+		zap.Bool("dry_run", *dryRun),
+		zap.Int("rewrapped", rewrapped),
+		zap.Int("failed", failed))
+	if failed > 0 {
+		log.Fatal("Some data keys were not re-wrapped; keep the old master key and run again")
+	}
+}
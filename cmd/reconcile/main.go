@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"registration-service/internal/config"
+	"registration-service/internal/reconcile"
+	"registration-service/internal/repository/fileRepo"
+	"registration-service/internal/storage/driver"
+	"registration-service/pkg/database/postgres"
+	"registration-service/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// reconcile сверяет file_versions.storage_key с хранилищем объектов и печатает расхождения.
+// С -repair удаляет объекты-сироты старше -grace и помечает строки, объекты которых пропали.
+func main() {
+	repair := flag.Bool("repair", false, "delete orphaned objects and flag rows with missing objects")
+	grace := flag.Duration("grace", 0, "override RECONCILE_GRACE_PERIOD")
+	flag.Parse()
+
+	ctx := context.Background()
+	var err error
+	ctx, err = logger.New(ctx)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize logger: %v", err))
+	}
+	log := logger.GetLogger(ctx)
+
+	cfg, err := config.LoadFileConfig()
+	if err != nil {
+		log.Fatal("Error loading config", zap.Error(err))
+	}
+	if *grace > 0 {
+		cfg.Reconcile.GracePeriod = *grace
+	}
+
+	conn, err := postgres.New(cfg.Postgres)
+	if err != nil {
+		log.Fatal("Error connecting to postgres", zap.Error(err))
+	}
+	defer conn.Close()
+	objects, err := driver.New(cfg.Storage, cfg.MinIO)
+	if err != nil {
+		log.Fatal("Failed to initialize object storage", zap.Error(err))
+	}
+
+	report, err := reconcile.New(fileRepo.New(conn, false), objects, cfg.Reconcile).Reconcile(ctx, *repair)
+	if err != nil {
+		log.Fatal("Reconciliation failed", zap.Error(err))
+	}
+
+	for _, orphan := range report.OrphanObjects {
+		fmt.Printf("orphan object\t%s\t%d bytes\t%s\n", orphan.Key, orphan.Size, orphan.LastModified.Format("2006-01-02T15:04:05Z07:00"))
+	}
+	for _, missing := range report.MissingObjects {
+		fmt.Printf("missing object\t%s\tfile %s\tversion row %d\n", missing.StorageKey, missing.FileID, missing.VersionID)
+	}
+	log.Info("Reconciliation finished",
+		zap.Bool("repair", *repair),
+		zap.Duration("grace_period", cfg.Reconcile.GracePeriod),
+		zap.Int("referenced_keys", report.ReferencedKeys),
+		zap.Int("scanned_objects", report.ScannedObjects),
+		zap.Int("orphan_objects", len(report.OrphanObjects)),
+		zap.Int("missing_objects", len(report.MissingObjects)),
+		zap.Int("deleted_objects", report.DeletedObjects),
+		zap.Int("flagged_rows", report.FlaggedRows),
+		zap.Int("restored_rows", report.RestoredRows))
+}
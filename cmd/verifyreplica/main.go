@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"registration-service/internal/config"
+	"registration-service/internal/replication"
+	"registration-service/internal/repository/fileRepo"
+	"registration-service/internal/storage"
+	"registration-service/internal/storage/driver"
+	"registration-service/internal/versionStore"
+	"registration-service/pkg/database/postgres"
+	"registration-service/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// verifyreplica сравнивает объекты реплицированных версий с копиями во вторичном хранилище
+// и печатает расхождения. С -hash сравнивает и содержимое, с -repair ставит версии
+// с расхождением в очередь репликации заново.
+func main() {
+	compareHash := flag.Bool("hash", false, "compare SHA-256 of object contents, not only sizes")
+	repair := flag.Bool("repair", false, "requeue mismatched versions for replication")
+	flag.Parse()
+
+	ctx := context.Background()
+	var err error
+	ctx, err = logger.New(ctx)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize logger: %v", err))
+	}
+	log := logger.GetLogger(ctx)
+
+	cfg, err := config.LoadFileConfig()
+	if err != nil {
+		log.Fatal("Error loading config", zap.Error(err))
+	}
+	if !cfg.Replication.Enabled {
+		log.Fatal("Replication is disabled: set REPLICATION_ENABLED")
+	}
+
+	conn, err := postgres.New(cfg.Postgres)
+	if err != nil {
+		log.Fatal("Error connecting to postgres", zap.Error(err))
+	}
+	defer conn.Close()
+	objects, err := driver.New(cfg.Storage, cfg.MinIO)
+	if err != nil {
+		log.Fatal("Failed to initialize object storage", zap.Error(err))
+	}
+	var cold storage.ObjectStore
+	if cfg.Tiering.Enabled {
+		cold, err = driver.NewCold(cfg.Storage, cfg.MinIO)
+		if err != nil {
+			log.Fatal("Failed to initialize cold storage tier", zap.Error(err))
+		}
+	}
+	if err := cfg.Replication.Validate(cfg.Storage, cfg.MinIO); err != nil {
+		log.Fatal("Invalid replication config", zap.Error(err))
+	}
+	replica, err := replication.NewReplica(cfg.Replication)
+	if err != nil {
+		log.Fatal("Failed to initialize replica storage", zap.Error(err))
+	}
+
+	// объекты сравниваются в хранимом виде, поэтому мастер-ключи не нужны
+	store := versionStore.New(objects, cold, nil, cfg.Compression)
+	verifier := replication.NewVerifier(fileRepo.New(conn, true), store, replica)
+	report, err := verifier.Verify(ctx, *compareHash, *repair)
+	if err != nil {
+		log.Fatal("Replica verification failed", zap.Error(err))
+	}
+
+	for _, m := range report.Mismatches {
+		fmt.Printf("%s\t%s\tversion row %d\tprimary %d bytes\treplica %d bytes\n",
+			m.Reason, m.StorageKey, m.VersionID, m.PrimarySize, m.ReplicaSize)
+	}
+	log.Info("Replica verification finished",
+		zap.Bool("hash", *compareHash),
+		zap.Bool("repair", *repair),
+		zap.Int("checked", report.Checked),
+		zap.Int("mismatches", len(report.Mismatches)),
+		zap.Int("requeued", report.Requeued))
+}
@@ -5,13 +5,18 @@ import (
 	"fmt"
 	"io"
 	"mime"
+	"net/url"
 	"path/filepath"
+	"registration-service/internal/storage"
 	"strings"
+	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 )
 
+const unknownSizePartSize = 16 * 1024 * 1024
+
 type Config struct {
 	MinioEndpoint     string `env:"MINIO_ENDPOINT" envDefault:"minio:9000"`
 	BucketName        string `env:"MINIO_BUCKET_NAME" envDefault:"storage"`
@@ -27,6 +32,8 @@ type MinIOClient struct {
 	Bucket string
 }
 
+var _ storage.ObjectStore = (*MinIOClient)(nil)
+
 func New(cfg Config) (*MinIOClient, error) {
 	// Проверяем обязательные параметры
 	if cfg.MinioEndpoint == "" {
@@ -77,7 +84,8 @@ func New(cfg Config) (*MinIOClient, error) {
 	}, nil
 }
 
-func (m *MinIOClient) UploadFile(ctx context.Context, key string, reader io.Reader, size int64, fileContentType string) error {
+// Put загружает объект. Если content type не указан, он определяется по расширению ключа.
+func (m *MinIOClient) Put(ctx context.Context, key string, reader io.Reader, size int64, fileContentType string) error {
 	// Используем предоставленный fileContentType; если он пустой, можно определить по расширению ключа или использовать application/octet-stream
 	contentTypeToUse := fileContentType
 	if contentTypeToUse == "" {
@@ -93,31 +101,56 @@ func (m *MinIOClient) UploadFile(ctx context.Context, key string, reader io.Read
 		}
 	}
 
-	// Загружаем файл с указанным Content-Type
-	_, err := m.Client.PutObject(ctx, m.Bucket, key, reader, size, minio.PutObjectOptions{
+	opts := minio.PutObjectOptions{
 		ContentType: contentTypeToUse,
-	})
+	}
+	// Размер неизвестен заранее (например, при сжатии на лету): ограничиваем буфер multipart-части,
+	// иначе minio-go рассчитывает часть под максимально возможный размер объекта
+	if size < 0 {
+		opts.PartSize = unknownSizePartSize
+	}
+
+	// Загружаем файл с указанным Content-Type
+	_, err := m.Client.PutObject(ctx, m.Bucket, key, reader, size, opts)
 	if err != nil {
 		return fmt.Errorf("failed to upload file: %v", err)
 	}
 	return nil
 }
 
-func (m *MinIOClient) DownloadFile(ctx context.Context, key string) (io.Reader, error) {
-	obj, err := m.Client.GetObject(ctx, m.Bucket, key, minio.GetObjectOptions{})
+func (m *MinIOClient) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return m.GetRange(ctx, key, 0, -1)
+}
+
+// GetRange открывает объект и сразу запрашивает Stat: GetObject ленивый, и без этого
+// отсутствие объекта обнаружилось бы только при первом чтении.
+func (m *MinIOClient) GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	if length == 0 {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	opts := minio.GetObjectOptions{}
+	if offset > 0 || length > 0 {
+		// end == 0 при offset > 0 означает "до конца объекта"
+		end := int64(0)
+		if length > 0 {
+			end = offset + length - 1
+		}
+		if err := opts.SetRange(offset, end); err != nil {
+			return nil, fmt.Errorf("invalid range: %v", err)
+		}
+	}
+	obj, err := m.Client.GetObject(ctx, m.Bucket, key, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download file: %v", err)
 	}
-	objInfo, err := obj.Stat()
-	if err != nil {
-		fmt.Printf("[MinIOClient.DownloadFile] WARN: Failed to get Stat for object %s: %v\n", key, err)
-	} else {
-		fmt.Printf("[MinIOClient.DownloadFile] DEBUG: Got object %s, Stat: Size=%d, ContentType=%s, ETag=%s\n", key, objInfo.Size, objInfo.ContentType, objInfo.ETag)
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, mapError(err, key)
 	}
 	return obj, nil
 }
 
-func (m *MinIOClient) DeleteFile(ctx context.Context, key string) error {
+func (m *MinIOClient) Delete(ctx context.Context, key string) error {
 	err := m.Client.RemoveObject(ctx, m.Bucket, key, minio.RemoveObjectOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to delete file: %v", err)
@@ -125,7 +158,60 @@ func (m *MinIOClient) DeleteFile(ctx context.Context, key string) error {
 	return nil
 }
 
-// Добавляем метод для получения публичного URL
-func (m *MinIOClient) GetPublicURL(key string) string {
-	return fmt.Sprintf("/api/files/%s", key)
+// Copy копирует объект внутри бакета на стороне сервера.
+func (m *MinIOClient) Copy(ctx context.Context, srcKey, dstKey string) error {
+	_, err := m.Client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: m.Bucket, Object: dstKey},
+		minio.CopySrcOptions{Bucket: m.Bucket, Object: srcKey})
+	if err != nil {
+		return mapError(err, srcKey)
+	}
+	return nil
+}
+
+func (m *MinIOClient) Stat(ctx context.Context, key string) (storage.ObjectInfo, error) {
+	info, err := m.Client.StatObject(ctx, m.Bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return storage.ObjectInfo{}, mapError(err, key)
+	}
+	return toObjectInfo(info), nil
+}
+
+func (m *MinIOClient) List(ctx context.Context, prefix string, fn func(storage.ObjectInfo) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for info := range m.Client.ListObjects(ctx, m.Bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if info.Err != nil {
+			return fmt.Errorf("failed to list objects: %v", info.Err)
+		}
+		if err := fn(toObjectInfo(info)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MinIOClient) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	u, err := m.Client.PresignedGetObject(ctx, m.Bucket, key, expiry, url.Values{})
+	if err != nil {
+		return "", fmt.Errorf("failed to presign object: %v", err)
+	}
+	return u.String(), nil
+}
+
+func toObjectInfo(info minio.ObjectInfo) storage.ObjectInfo {
+	return storage.ObjectInfo{
+		Key:          info.Key,
+		Size:         info.Size,
+		ContentType:  info.ContentType,
+		LastModified: info.LastModified,
+	}
+}
+
+// mapError переводит ответ NoSuchKey в storage.ErrNotFound.
+func mapError(err error, key string) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return fmt.Errorf("%w: %s", storage.ErrNotFound, key)
+	}
+	return err
 }
@@ -0,0 +1,20 @@
+package archive
+
+import "strings"
+
+// IsPrecompressed сообщает, что содержимое уже сжато: повторное сжатие таких форматов только тратит CPU.
+func IsPrecompressed(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	if strings.HasPrefix(contentType, "image/") && !strings.HasPrefix(contentType, "image/svg") {
+		return true
+	}
+	if strings.HasPrefix(contentType, "video/") || strings.HasPrefix(contentType, "audio/") {
+		return true
+	}
+	switch contentType {
+	case "application/zip", "application/gzip", "application/x-gzip", "application/x-7z-compressed",
+		"application/x-rar-compressed", "application/zstd", "application/x-xz":
+		return true
+	}
+	return false
+}
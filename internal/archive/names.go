@@ -0,0 +1,44 @@
+package archive
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// EntryName превращает имя файла в безопасное имя записи архива без каталогов.
+func EntryName(name string) string {
+	name = strings.NewReplacer("/", "_", "\\", "_", "\x00", "").Replace(strings.TrimSpace(name))
+	if name == "" || name == "." || name == ".." {
+		return "file"
+	}
+	return name
+}
+
+// NameSet выдаёт уникальные имена записей: повторное "a.txt" становится "a (2).txt".
+type NameSet struct {
+	used map[string]struct{}
+}
+
+func NewNameSet() *NameSet {
+	return &NameSet{used: make(map[string]struct{})}
+}
+
+// Reserve занимает имя, чтобы его не получил ни один файл (например, имя манифеста).
+func (s *NameSet) Reserve(name string) {
+	s.used[strings.ToLower(name)] = struct{}{}
+}
+
+func (s *NameSet) Unique(name string) string {
+	name = EntryName(name)
+	candidate := name
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 2; ; i++ {
+		if _, taken := s.used[strings.ToLower(candidate)]; !taken {
+			s.used[strings.ToLower(candidate)] = struct{}{}
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s (%d)%s", base, i, ext)
+	}
+}
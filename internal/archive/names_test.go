@@ -0,0 +1,28 @@
+package archive_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"registration-service/internal/archive"
+)
+
+func TestEntryName(t *testing.T) {
+	assert.Equal(t, "report.pdf", archive.EntryName("report.pdf"))
+	assert.Equal(t, ".._.._etc_passwd", archive.EntryName("../../etc/passwd"))
+	assert.Equal(t, "a_b", archive.EntryName(`a\b`))
+	assert.Equal(t, "file", archive.EntryName("  "))
+	assert.Equal(t, "file", archive.EntryName(".."))
+}
+
+func TestNameSet_Unique(t *testing.T) {
+	names := archive.NewNameSet()
+	names.Reserve("manifest.json")
+
+	assert.Equal(t, "a.txt", names.Unique("a.txt"))
+	assert.Equal(t, "a (2).txt", names.Unique("a.txt"))
+	assert.Equal(t, "A (3).txt", names.Unique("A.txt"))
+	assert.Equal(t, "manifest (2).json", names.Unique("manifest.json"))
+	assert.Equal(t, "notes", names.Unique("notes"))
+	assert.Equal(t, "notes (2)", names.Unique("notes"))
+}
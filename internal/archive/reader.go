@@ -0,0 +1,220 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+const (
+	FormatZip   = "zip"
+	FormatTarGz = "tar.gz"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported archive format")
+	ErrUnsafePath        = errors.New("unsafe path in archive")
+	ErrTooManyEntries    = errors.New("archive has too many entries")
+	ErrEntryTooLarge     = errors.New("archive entry is too large")
+	ErrArchiveTooLarge   = errors.New("archive expands beyond the allowed size")
+	ErrCompressionRatio  = errors.New("archive compression ratio is suspiciously high")
+)
+
+type Config struct {
+	MaxUploadBytes      int64   `env:"ARCHIVE_MAX_UPLOAD_BYTES" env-default:"1073741824"`
+	MaxEntries          int     `env:"ARCHIVE_MAX_ENTRIES" env-default:"1000"`
+	MaxEntrySize        int64   `env:"ARCHIVE_MAX_ENTRY_SIZE" env-default:"104857600"`
+	MaxTotalSize        int64   `env:"ARCHIVE_MAX_TOTAL_SIZE" env-default:"2147483648"`
+	MaxCompressionRatio float64 `env:"ARCHIVE_MAX_COMPRESSION_RATIO" env-default:"100"`
+}
+
+type Entry struct {
+	// Path — очищенный относительный путь со слешами в качестве разделителя
+	Path    string
+	Regular bool
+	Dir     bool
+	// Size — размер обычной записи из заголовка. Reader записи не отдаст ни больше, ни меньше:
+	// расхождение с заголовком archive/zip и archive/tar возвращают как ошибку чтения
+	Size int64
+}
+
+// DetectFormat определяет формат по явному значению или по имени архива.
+func DetectFormat(format, name string) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		lower := strings.ToLower(name)
+		switch {
+		case strings.HasSuffix(lower, ".zip"):
+			format = FormatZip
+		case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+			format = FormatTarGz
+		}
+	}
+	switch format {
+	case FormatZip:
+		return FormatZip, nil
+	case FormatTarGz, "tgz":
+		return FormatTarGz, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+}
+
+// SafePath нормализует путь записи и отвергает абсолютные пути и выход за корень (zip-slip).
+func SafePath(name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.ContainsRune(name, 0) || strings.HasPrefix(name, "/") || (len(name) > 1 && name[1] == ':') {
+		return "", fmt.Errorf("%w: %q", ErrUnsafePath, name)
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("%w: %q", ErrUnsafePath, name)
+		}
+	}
+	cleaned := path.Clean(name)
+	if cleaned == "." {
+		return "", fmt.Errorf("%w: %q", ErrUnsafePath, name)
+	}
+	return cleaned, nil
+}
+
+// Walk обходит записи архива, соблюдая лимиты. Размеры из заголовков не считаются надёжными:
+// fn получает reader, который возвращает ошибку при превышении лимита по фактически прочитанным байтам.
+// Для обычных записей fn обязан дочитать reader до конца, иначе учёт размера будет неполным.
+func Walk(format string, r io.ReaderAt, size int64, limits Config, fn func(Entry, io.Reader) error) error {
+	switch format {
+	case FormatZip:
+		return walkZip(r, size, limits, fn)
+	case FormatTarGz:
+		return walkTarGz(r, size, limits, fn)
+	}
+	return fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+}
+
+// Validate проходит архив целиком без побочных эффектов, чтобы отвергнуть его до распаковки.
+func Validate(format string, r io.ReaderAt, size int64, limits Config) error {
+	return Walk(format, r, size, limits, func(entry Entry, data io.Reader) error {
+		_, err := io.Copy(io.Discard, data)
+		return err
+	})
+}
+
+type counter struct {
+	limits  Config
+	entries int
+	total   int64
+}
+
+func (c *counter) next(name string) (string, error) {
+	c.entries++
+	if c.entries > c.limits.MaxEntries {
+		return "", fmt.Errorf("%w: more than %d", ErrTooManyEntries, c.limits.MaxEntries)
+	}
+	return SafePath(name)
+}
+
+// limitedReader считает распакованные байты записи и всего архива.
+type limitedReader struct {
+	r        io.Reader
+	counter  *counter
+	entry    int64
+	path     string
+	archived int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.entry += int64(n)
+	l.counter.total += int64(n)
+	if l.entry > l.counter.limits.MaxEntrySize {
+		return n, fmt.Errorf("%w: %s exceeds %d bytes", ErrEntryTooLarge, l.path, l.counter.limits.MaxEntrySize)
+	}
+	if l.counter.total > l.counter.limits.MaxTotalSize {
+		return n, fmt.Errorf("%w: more than %d bytes", ErrArchiveTooLarge, l.counter.limits.MaxTotalSize)
+	}
+	if l.archived > 0 && float64(l.counter.total)/float64(l.archived) > l.counter.limits.MaxCompressionRatio &&
+		l.counter.total > 1024*1024 {
+		return n, fmt.Errorf("%w: more than %.0f:1", ErrCompressionRatio, l.counter.limits.MaxCompressionRatio)
+	}
+	return n, err
+}
+
+func walkZip(r io.ReaderAt, size int64, limits Config, fn func(Entry, io.Reader) error) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	if len(zr.File) > limits.MaxEntries {
+		return fmt.Errorf("%w: %d > %d", ErrTooManyEntries, len(zr.File), limits.MaxEntries)
+	}
+	c := &counter{limits: limits}
+	for _, f := range zr.File {
+		entryPath, err := c.next(f.Name)
+		if err != nil {
+			return err
+		}
+		if !f.Mode().IsRegular() {
+			if err := fn(Entry{Path: entryPath, Dir: f.Mode().IsDir()}, eofReader{}); err != nil {
+				return err
+			}
+			continue
+		}
+		if f.UncompressedSize64 > uint64(limits.MaxEntrySize) {
+			return fmt.Errorf("%w: %s declares %d bytes", ErrEntryTooLarge, entryPath, f.UncompressedSize64)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", entryPath, err)
+		}
+		err = fn(Entry{Path: entryPath, Regular: true, Size: int64(f.UncompressedSize64)}, &limitedReader{r: rc, counter: c, path: entryPath, archived: size})
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkTarGz(r io.ReaderAt, size int64, limits Config, fn func(Entry, io.Reader) error) error {
+	gz, err := gzip.NewReader(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	defer gz.Close()
+
+	c := &counter{limits: limits}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar header: %w", err)
+		}
+		entryPath, err := c.next(hdr.Name)
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			if err := fn(Entry{Path: entryPath, Dir: hdr.Typeflag == tar.TypeDir}, eofReader{}); err != nil {
+				return err
+			}
+			continue
+		}
+		if hdr.Size > limits.MaxEntrySize {
+			return fmt.Errorf("%w: %s declares %d bytes", ErrEntryTooLarge, entryPath, hdr.Size)
+		}
+		if err := fn(Entry{Path: entryPath, Regular: true, Size: hdr.Size}, &limitedReader{r: tr, counter: c, path: entryPath, archived: size}); err != nil {
+			return err
+		}
+	}
+}
+
+type eofReader struct{}
+
+func (eofReader) Read([]byte) (int, error) { return 0, io.EOF }
@@ -0,0 +1,128 @@
+package archive_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"registration-service/internal/archive"
+)
+
+var testLimits = archive.Config{
+	MaxUploadBytes:      1 << 20,
+	MaxEntries:          10,
+	MaxEntrySize:        4 << 20,
+	MaxTotalSize:        8 << 20,
+	MaxCompressionRatio: 100,
+}
+
+func makeZip(t *testing.T, files map[string]string) *bytes.Reader {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		_, err = io.WriteString(w, content)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+	return bytes.NewReader(buf.Bytes())
+}
+
+func makeTarGz(t *testing.T, files map[string]string) *bytes.Reader {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "docs/", Typeflag: tar.TypeDir, Mode: 0o755}))
+	for name, content := range files {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(content))}))
+		_, err := io.WriteString(tw, content)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+	return bytes.NewReader(buf.Bytes())
+}
+
+func collect(t *testing.T, format string, r *bytes.Reader) (map[string]string, error) {
+	got := map[string]string{}
+	err := archive.Walk(format, r, r.Size(), testLimits, func(entry archive.Entry, data io.Reader) error {
+		if !entry.Regular {
+			return nil
+		}
+		content, err := io.ReadAll(data)
+		got[entry.Path] = string(content)
+		assert.EqualValues(t, len(content), entry.Size, entry.Path)
+		return err
+	})
+	return got, err
+}
+
+func TestWalk(t *testing.T) {
+	files := map[string]string{"readme.md": "hello", "docs/a.txt": "a"}
+
+	t.Run("Zip", func(t *testing.T) {
+		got, err := collect(t, archive.FormatZip, makeZip(t, files))
+		assert.NoError(t, err)
+		assert.Equal(t, files, got)
+	})
+
+	t.Run("Tar.gz", func(t *testing.T) {
+		got, err := collect(t, archive.FormatTarGz, makeTarGz(t, files))
+		assert.NoError(t, err)
+		assert.Equal(t, files, got)
+	})
+}
+
+func TestValidate_Rejects(t *testing.T) {
+	t.Run("Zip slip", func(t *testing.T) {
+		r := makeZip(t, map[string]string{"../../etc/cron.d/evil": "x"})
+		assert.ErrorIs(t, archive.Validate(archive.FormatZip, r, r.Size(), testLimits), archive.ErrUnsafePath)
+
+		r = makeTarGz(t, map[string]string{"/etc/passwd": "x"})
+		assert.ErrorIs(t, archive.Validate(archive.FormatTarGz, r, r.Size(), testLimits), archive.ErrUnsafePath)
+	})
+
+	t.Run("Too many entries", func(t *testing.T) {
+		files := map[string]string{}
+		for i := 0; i < 11; i++ {
+			files[strings.Repeat("f", i+1)] = "x"
+		}
+		r := makeZip(t, files)
+		assert.ErrorIs(t, archive.Validate(archive.FormatZip, r, r.Size(), testLimits), archive.ErrTooManyEntries)
+
+		r = makeTarGz(t, files)
+		assert.ErrorIs(t, archive.Validate(archive.FormatTarGz, r, r.Size(), testLimits), archive.ErrTooManyEntries)
+	})
+
+	t.Run("Zip bomb", func(t *testing.T) {
+		// 3 МБ нулей сжимаются в несколько килобайт
+		r := makeZip(t, map[string]string{"zeros.bin": strings.Repeat("\x00", 3<<20)})
+		assert.ErrorIs(t, archive.Validate(archive.FormatZip, r, r.Size(), testLimits), archive.ErrCompressionRatio)
+	})
+
+	t.Run("Entry too large", func(t *testing.T) {
+		limits := testLimits
+		limits.MaxEntrySize = 10
+		r := makeTarGz(t, map[string]string{"big.txt": strings.Repeat("a", 11)})
+		assert.ErrorIs(t, archive.Validate(archive.FormatTarGz, r, r.Size(), limits), archive.ErrEntryTooLarge)
+	})
+}
+
+func TestDetectFormat(t *testing.T) {
+	format, err := archive.DetectFormat("", "project.TGZ")
+	assert.NoError(t, err)
+	assert.Equal(t, archive.FormatTarGz, format)
+
+	format, err = archive.DetectFormat("zip", "whatever")
+	assert.NoError(t, err)
+	assert.Equal(t, archive.FormatZip, format)
+
+	_, err = archive.DetectFormat("", "project.rar")
+	assert.ErrorIs(t, err, archive.ErrUnsupportedFormat)
+}
@@ -0,0 +1,97 @@
+// Package clientip определяет адрес клиента gRPC-запроса с учётом доверенных прокси.
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+type Config struct {
+	// TrustedProxies — адреса и подсети балансировщиков (например 10.0.0.0/8). Только от них
+	// принимается x-forwarded-for; пусто — заголовок игнорируется.
+	TrustedProxies []string `env:"TRUSTED_PROXIES" env-separator:","`
+}
+
+// Resolver берёт адрес из соединения. x-forwarded-for клиент может подставить сам, поэтому он
+// учитывается, только если соединение пришло от доверенного прокси.
+type Resolver struct {
+	trusted []*net.IPNet
+}
+
+func NewResolver(cfg Config) (*Resolver, error) {
+	r := &Resolver{}
+	for _, entry := range cfg.TrustedProxies {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			r.trusted = append(r.trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		r.trusted = append(r.trusted, network)
+	}
+	return r, nil
+}
+
+// IP возвращает адрес клиента или пустую строку, если его не узнать. Цепочка x-forwarded-for
+// разбирается справа: записи доверенных прокси пропускаются, первая чужая и есть клиент.
+// Всё левее неё мог написать сам клиент.
+func (r *Resolver) IP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	addr := p.Addr.String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil || !r.isTrusted(ip) {
+		return addr
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	var hops []string
+	for _, value := range md.Get("x-forwarded-for") {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			// мусор в цепочке: дальше доверять нечему
+			break
+		}
+		ip = hop
+		if !r.isTrusted(ip) {
+			break
+		}
+	}
+	return ip.String()
+}
+
+func (r *Resolver) isTrusted(ip net.IP) bool {
+	for _, network := range r.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
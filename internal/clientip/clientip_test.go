@@ -0,0 +1,57 @@
+package clientip_test
+
+import (
+	"context"
+	"net"
+	"registration-service/internal/clientip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func requestFrom(remote string, forwardedFor ...string) context.Context {
+	addr, err := net.ResolveTCPAddr("tcp", remote)
+	if err != nil {
+		panic(err)
+	}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+	if len(forwardedFor) > 0 {
+		ctx = metadata.NewIncomingContext(ctx, metadata.MD{"x-forwarded-for": forwardedFor})
+	}
+	return ctx
+}
+
+func TestResolver_IgnoresSpoofedHeaderFromUntrustedPeer(t *testing.T) {
+	r, err := clientip.NewResolver(clientip.Config{TrustedProxies: []string{"10.0.0.0/8"}})
+	require.NoError(t, err)
+
+	// клиент подключился напрямую и выдаёт себя за другой адрес, чтобы обойти блокировку
+	assert.Equal(t, "203.0.113.7", r.IP(requestFrom("203.0.113.7:5000", "198.51.100.1")))
+
+	noProxies, err := clientip.NewResolver(clientip.Config{})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", noProxies.IP(requestFrom("10.0.0.2:5000", "198.51.100.1")))
+}
+
+func TestResolver_UsesForwardedForBehindTrustedProxy(t *testing.T) {
+	r, err := clientip.NewResolver(clientip.Config{TrustedProxies: []string{"10.0.0.0/8", "192.0.2.10"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "203.0.113.7", r.IP(requestFrom("10.0.0.2:5000", "203.0.113.7")))
+	// левее клиента — то, что он прислал сам; справа — доверенные прокси
+	assert.Equal(t, "203.0.113.7", r.IP(requestFrom("10.0.0.2:5000", "198.51.100.1, 203.0.113.7, 192.0.2.10")))
+	assert.Equal(t, "203.0.113.7", r.IP(requestFrom("10.0.0.2:5000", "198.51.100.1", "203.0.113.7")))
+	// без заголовка или с мусором в нём остаётся адрес прокси
+	assert.Equal(t, "10.0.0.2", r.IP(requestFrom("10.0.0.2:5000")))
+	assert.Equal(t, "10.0.0.2", r.IP(requestFrom("10.0.0.2:5000", "not-an-ip")))
+}
+
+func TestNewResolver_RejectsInvalidProxy(t *testing.T) {
+	_, err := clientip.NewResolver(clientip.Config{TrustedProxies: []string{"10.0.0.0/33"}})
+	assert.Error(t, err)
+	_, err = clientip.NewResolver(clientip.Config{TrustedProxies: []string{"proxy.local"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,112 @@
+package compression
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	CodecNone = "none"
+	CodecZstd = "zstd"
+	CodecGzip = "gzip"
+)
+
+var ErrUnknownCodec = errors.New("unknown compression codec")
+
+type Config struct {
+	// Codec — кодек для новых версий: zstd, gzip или none
+	Codec string `env:"COMPRESSION_CODEC" env-default:"zstd"`
+	// MinSize — файлы меньше этого размера не сжимаются: выигрыш не окупает заголовки кодека
+	MinSize int64 `env:"COMPRESSION_MIN_SIZE" env-default:"1024"`
+}
+
+func (c Config) Validate() error {
+	switch c.Codec {
+	case CodecNone, CodecZstd, CodecGzip:
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownCodec, c.Codec)
+}
+
+// Choose возвращает кодек для версии с таким типом содержимого и размером.
+func (c Config) Choose(contentType string, size int64) string {
+	if c.Codec == "" || c.Codec == CodecNone || size < c.MinSize || !IsCompressible(contentType) {
+		return CodecNone
+	}
+	return c.Codec
+}
+
+// IsCompressible сообщает, что содержимое текстовое и хорошо сжимается.
+func IsCompressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	if strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript", "application/x-ndjson",
+		"application/x-yaml", "application/yaml", "application/csv", "application/sql",
+		"application/toml", "image/svg+xml":
+		return true
+	}
+	return false
+}
+
+// NewReader возвращает поток сжатых данных src. Сжатие идёт в отдельной горутине;
+// ошибка чтения src или кодека возвращается из Read. Close останавливает горутину досрочно.
+func NewReader(codec string, src io.Reader) (io.ReadCloser, error) {
+	if codec == "" || codec == CodecNone {
+		return io.NopCloser(src), nil
+	}
+	pr, pw := io.Pipe()
+	var encoder io.WriteCloser
+	switch codec {
+	case CodecZstd:
+		zw, err := zstd.NewWriter(pw)
+		if err != nil {
+			return nil, err
+		}
+		encoder = zw
+	case CodecGzip:
+		encoder = gzip.NewWriter(pw)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCodec, codec)
+	}
+
+	go func() {
+		_, err := io.Copy(encoder, src)
+		if closeErr := encoder.Close(); err == nil {
+			err = closeErr
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
+
+// NewDecoder возвращает распакованный поток src.
+func NewDecoder(codec string, src io.Reader) (io.ReadCloser, error) {
+	switch codec {
+	case "", CodecNone:
+		return io.NopCloser(src), nil
+	case CodecZstd:
+		zr, err := zstd.NewReader(src)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	case CodecGzip:
+		return gzip.NewReader(src)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownCodec, codec)
+}
@@ -0,0 +1,58 @@
+package compression_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"registration-service/internal/compression"
+)
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	plain := []byte(strings.Repeat("id,name,status\n42,apollo,approved\n", 2000))
+	for _, codec := range []string{compression.CodecNone, compression.CodecZstd, compression.CodecGzip} {
+		r, err := compression.NewReader(codec, bytes.NewReader(plain))
+		require.NoError(t, err, codec)
+		packed, err := io.ReadAll(r)
+		require.NoError(t, err, codec)
+		if codec != compression.CodecNone {
+			assert.Less(t, len(packed), len(plain)/10, codec)
+		}
+
+		d, err := compression.NewDecoder(codec, bytes.NewReader(packed))
+		require.NoError(t, err, codec)
+		got, err := io.ReadAll(d)
+		require.NoError(t, err, codec)
+		assert.Equal(t, plain, got, codec)
+		assert.NoError(t, d.Close())
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("connection reset") }
+
+func TestNewReader_PropagatesSourceError(t *testing.T) {
+	r, err := compression.NewReader(compression.CodecZstd, failingReader{})
+	require.NoError(t, err)
+	_, err = io.ReadAll(r)
+	assert.EqualError(t, err, "connection reset")
+}
+
+func TestConfig_Choose(t *testing.T) {
+	cfg := compression.Config{Codec: compression.CodecZstd, MinSize: 1024}
+	assert.Equal(t, compression.CodecZstd, cfg.Choose("text/csv", 4096))
+	assert.Equal(t, compression.CodecZstd, cfg.Choose("application/json; charset=utf-8", 4096))
+	assert.Equal(t, compression.CodecNone, cfg.Choose("text/plain", 100))
+	assert.Equal(t, compression.CodecNone, cfg.Choose("image/png", 4096))
+	assert.Equal(t, compression.CodecNone, compression.Config{Codec: compression.CodecNone}.Choose("text/plain", 4096))
+
+	assert.NoError(t, cfg.Validate())
+	assert.ErrorIs(t, compression.Config{Codec: "brotli"}.Validate(), compression.ErrUnknownCodec)
+	_, err := compression.NewDecoder("brotli", bytes.NewReader(nil))
+	assert.ErrorIs(t, err, compression.ErrUnknownCodec)
+}
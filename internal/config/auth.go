@@ -0,0 +1,50 @@
+package config
+
+import "time"
+
+type VerificationConfig struct {
+	// Required запрещает вход, пока адрес не подтверждён
+	Required bool          `env:"EMAIL_VERIFICATION_REQUIRED" env-default:"false"`
+	TokenTTL time.Duration `env:"EMAIL_VERIFICATION_TTL" env-default:"24h"`
+	// Secret подписывает токены подтверждения; если пуст, ключ выводится из JWT_TOKEN
+	Secret string `env:"EMAIL_VERIFICATION_SECRET"`
+	// URL — страница подтверждения, токен добавляется к ней параметром token;
+	// если пуст, в письме только сам токен
+	URL            string        `env:"EMAIL_VERIFICATION_URL"`
+	ResendInterval time.Duration `env:"EMAIL_VERIFICATION_RESEND_INTERVAL" env-default:"1m"`
+}
+
+type PasswordResetConfig struct {
+	TokenTTL time.Duration `env:"PASSWORD_RESET_TTL" env-default:"30m"`
+	// URL — страница сброса, токен добавляется к ней параметром token;
+	// если пуст, в письме только сам токен
+	URL             string        `env:"PASSWORD_RESET_URL"`
+	RequestInterval time.Duration `env:"PASSWORD_RESET_REQUEST_INTERVAL" env-default:"1m"`
+}
+
+type MFAConfig struct {
+	// Issuer показывается в приложении-аутентификаторе рядом с адресом пользователя
+	Issuer string `env:"MFA_ISSUER" env-default:"registration-service"`
+	// TokenTTL — сколько после пароля можно ввести код
+	TokenTTL time.Duration `env:"MFA_TOKEN_TTL" env-default:"5m"`
+	// Skew — на сколько 30-секундных интервалов могут расходиться часы клиента
+	Skew          int `env:"MFA_SKEW" env-default:"1"`
+	MaxAttempts   int `env:"MFA_MAX_ATTEMPTS" env-default:"5"`
+	RecoveryCodes int `env:"MFA_RECOVERY_CODES" env-default:"10"`
+}
+
+// LoginThrottleConfig задаёт защиту от перебора паролей. Неудачи считаются отдельно для аккаунта
+// и для адреса клиента: после SlowdownAfter неудач каждая следующая запрещает вход на
+// SlowdownDelay, с удвоением до MaxDelay, а после MaxAccountFailures (MaxIPFailures для адреса)
+// вход блокируется на Lockout.
+type LoginThrottleConfig struct {
+	// Window — через сколько без неудач счётчик обнуляется
+	Window             time.Duration `env:"LOGIN_FAILURE_WINDOW" env-default:"15m"`
+	SlowdownAfter      int           `env:"LOGIN_SLOWDOWN_AFTER" env-default:"3"`
+	SlowdownDelay      time.Duration `env:"LOGIN_SLOWDOWN_DELAY" env-default:"1s"`
+	MaxDelay           time.Duration `env:"LOGIN_MAX_DELAY" env-default:"30s"`
+	MaxAccountFailures int           `env:"LOGIN_MAX_ACCOUNT_FAILURES" env-default:"10"`
+	// MaxIPFailures выше, чем для аккаунта: за одним адресом может быть много пользователей
+	MaxIPFailures int           `env:"LOGIN_MAX_IP_FAILURES" env-default:"50"`
+	Lockout       time.Duration `env:"LOGIN_LOCKOUT" env-default:"15m"`
+}
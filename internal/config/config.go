@@ -2,30 +2,84 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"github.com/ilyakaznacheev/cleanenv"
 	"registration-service/internal/MinIO"
+	"registration-service/internal/archive"
+	"registration-service/internal/clientip"
+	"registration-service/internal/compression"
+	"registration-service/internal/encryption"
+	"registration-service/internal/jwtkeys"
+	"registration-service/internal/mail"
+	"registration-service/internal/reconcile"
+	"registration-service/internal/replication"
+	"registration-service/internal/scan"
+	"registration-service/internal/search"
+	"registration-service/internal/storage/driver"
+	"registration-service/internal/takeout"
+	"registration-service/internal/thumbnail"
+	"registration-service/internal/tiering"
+	"registration-service/internal/tokenverify"
+	"registration-service/internal/upload"
+	"registration-service/internal/webhook"
 	"registration-service/pkg/database/postgres"
 	"registration-service/pkg/database/redis"
 )
 
 type AuthConfig struct {
-	GRPCPort  string `env:"GRPC_AUTH_PORT" env-default:"50051"`
-	JWTSecret string `env:"JWT_TOKEN"`
+	GRPCPort string `env:"GRPC_AUTH_PORT" env-default:"50051"`
+	// HTTPPort — порт, на котором отдаётся JWKS
+	HTTPPort string `env:"HTTP_AUTH_PORT" env-default:"8081"`
+	// JWTSecret подписывает старые HS256-токены и служит основой ключей mfa-токенов и токенов
+	// подтверждения; с пустым секретом их мог бы подделать кто угодно
+	JWTSecret string `env:"JWT_TOKEN" env-required:"true"`
 	Postgres  postgres.Config
 	Redis     redis.Config
+	Mail      mail.Config
+	// SigningKeys — ключи подписи access-токенов; Encryption оборачивает их закрытые части
+	SigningKeys   jwtkeys.Config
+	Encryption    encryption.Config
+	Verification  VerificationConfig
+	PasswordReset PasswordResetConfig
+	MFA           MFAConfig
+	LoginThrottle LoginThrottleConfig
+
+	// ClientIP — каким прокси доверять при определении адреса клиента
+	ClientIP clientip.Config
 }
 
 type FileConfig struct {
 	GRPCPort        string `env:"GRPC_FILE_PORT" env-default:"50052"`
 	AuthServiceAddr string `env:"AUTH_SERVICE_ADDR" env-default:"localhost:50053"`
 	Postgres        postgres.Config
+	Storage         driver.Config
 	MinIO           MinIO.Config
+	Webhook         webhook.Config
+	Search          search.Config
+	Thumbnail       thumbnail.Config
+	Archive         archive.Config
+	Encryption      encryption.Config
+	Compression     compression.Config
+	Scan            scan.Config
+	Reconcile       reconcile.Config
+	Upload          upload.Config
+	Tiering         tiering.Config
+	Replication     replication.Config
+	Export          takeout.Config
+
+	// Redis — источник чёрного списка токенов для локальной проверки
+	Redis             redis.Config
+	TokenVerification tokenverify.Config
 }
 
 func LoadAuthConfig() (*AuthConfig, error) {
 	var cfg AuthConfig
 	if err := cleanenv.ReadConfig("./.env", &cfg); err != nil {
-		return nil, errors.New("cannot read Auth Config")
+		return nil, fmt.Errorf("cannot read Auth Config: %w", err)
+	}
+	// env-required пропускает переменную, заданную пустой строкой
+	if cfg.JWTSecret == "" {
+		return nil, errors.New("cannot read Auth Config: JWT_TOKEN is empty")
 	}
 	return &cfg, nil
 }
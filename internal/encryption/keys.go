@@ -0,0 +1,157 @@
+package encryption
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	ErrUnknownKey   = errors.New("unknown master key")
+	ErrInvalidKey   = errors.New("invalid master key")
+	ErrUnwrapFailed = errors.New("failed to unwrap data key")
+)
+
+type Config struct {
+	// MasterKeys — список "id:base64(32 байта)" через запятую
+	MasterKeys string `env:"ENCRYPTION_MASTER_KEYS"`
+	// MasterKeyFile — файл с теми же записями, по одной на строку; строки с # игнорируются
+	MasterKeyFile string `env:"ENCRYPTION_MASTER_KEY_FILE"`
+	// ActiveKeyID — ключ, которым оборачиваются новые ключи данных
+	ActiveKeyID string `env:"ENCRYPTION_ACTIVE_KEY_ID"`
+}
+
+// Enabled сообщает, настроены ли мастер-ключи. Без них объекты хранятся как раньше, в открытом виде.
+func (c Config) Enabled() bool {
+	return c.MasterKeys != "" || c.MasterKeyFile != ""
+}
+
+// KeyProvider оборачивает ключи данных мастер-ключом. Реализация может держать ключи локально
+// или обращаться во внешний KMS; идентификатор ключа сохраняется рядом с обёрнутым ключом,
+// чтобы после ротации старые версии оставались читаемыми.
+type KeyProvider interface {
+	ActiveKeyID() string
+	Wrap(ctx context.Context, dataKey []byte) (keyID string, wrapped []byte, err error)
+	Unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// LocalKeyProvider хранит мастер-ключи в памяти, загружая их из переменной окружения или файла.
+type LocalKeyProvider struct {
+	keys     map[string][]byte
+	activeID string
+}
+
+func NewLocalKeyProvider(cfg Config) (*LocalKeyProvider, error) {
+	entries := splitEntries(cfg.MasterKeys, ",")
+	if cfg.MasterKeyFile != "" {
+		data, err := os.ReadFile(cfg.MasterKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read master key file: %w", err)
+		}
+		entries = append(entries, splitEntries(string(data), "\n")...)
+	}
+
+	p := &LocalKeyProvider{keys: make(map[string][]byte, len(entries)), activeID: cfg.ActiveKeyID}
+	for _, entry := range entries {
+		id, encoded, ok := strings.Cut(entry, ":")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("%w: expected id:base64, got %q", ErrInvalidKey, entry)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil || len(key) != DataKeySize {
+			return nil, fmt.Errorf("%w: key %q must be %d base64-encoded bytes", ErrInvalidKey, id, DataKeySize)
+		}
+		if _, dup := p.keys[id]; dup {
+			return nil, fmt.Errorf("%w: duplicate key id %q", ErrInvalidKey, id)
+		}
+		p.keys[id] = key
+	}
+	if len(p.keys) == 0 {
+		return nil, fmt.Errorf("%w: no master keys configured", ErrInvalidKey)
+	}
+	if p.activeID == "" {
+		if len(p.keys) > 1 {
+			return nil, fmt.Errorf("%w: ENCRYPTION_ACTIVE_KEY_ID is required with several master keys", ErrInvalidKey)
+		}
+		for id := range p.keys {
+			p.activeID = id
+		}
+	}
+	if _, ok := p.keys[p.activeID]; !ok {
+		return nil, fmt.Errorf("%w: active key %q", ErrUnknownKey, p.activeID)
+	}
+	return p, nil
+}
+
+func splitEntries(s, sep string) []string {
+	var entries []string
+	for _, entry := range strings.Split(s, sep) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func (p *LocalKeyProvider) ActiveKeyID() string {
+	return p.activeID
+}
+
+// Wrap шифрует ключ данных активным мастер-ключом: nonce || AES-GCM(dataKey), id ключа — additional data.
+func (p *LocalKeyProvider) Wrap(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	aead, err := newGCM(p.keys[p.activeID])
+	if err != nil {
+		return "", nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return p.activeID, aead.Seal(nonce, nonce, dataKey, []byte(p.activeID)), nil
+}
+
+func (p *LocalKeyProvider) Unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	master, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+	aead, err := newGCM(master)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, ErrUnwrapFailed
+	}
+	nonce, sealed := wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():]
+	dataKey, err := aead.Open(nil, nonce, sealed, []byte(keyID))
+	if err != nil {
+		return nil, ErrUnwrapFailed
+	}
+	return dataKey, nil
+}
+
+// Rewrap переупаковывает ключ данных активным мастер-ключом; сам ключ данных и объект не меняются.
+func Rewrap(ctx context.Context, keys KeyProvider, keyID string, wrapped []byte) (string, []byte, error) {
+	dataKey, err := keys.Unwrap(ctx, keyID, wrapped)
+	if err != nil {
+		return "", nil, err
+	}
+	return keys.Wrap(ctx, dataKey)
+}
+
+// DeriveKey выводит отдельный ключ для каждого объекта версии (содержимое, превью),
+// чтобы счётчики nonce разных объектов не пересекались под одним ключом.
+func DeriveKey(dataKey []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, dataKey)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,78 @@
+package encryption_test
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"registration-service/internal/encryption"
+)
+
+func masterKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), encryption.DataKeySize)))
+}
+
+func TestLocalKeyProvider_WrapUnwrap(t *testing.T) {
+	ctx := context.Background()
+	keys, err := encryption.NewLocalKeyProvider(encryption.Config{MasterKeys: "k1:" + masterKey('a')})
+	require.NoError(t, err)
+	assert.Equal(t, "k1", keys.ActiveKeyID())
+
+	dataKey, _ := encryption.NewDataKey()
+	keyID, wrapped, err := keys.Wrap(ctx, dataKey)
+	require.NoError(t, err)
+	assert.Equal(t, "k1", keyID)
+	assert.NotContains(t, string(wrapped), string(dataKey))
+
+	got, err := keys.Unwrap(ctx, keyID, wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, got)
+
+	_, err = keys.Unwrap(ctx, "missing", wrapped)
+	assert.ErrorIs(t, err, encryption.ErrUnknownKey)
+	wrapped[len(wrapped)-1] ^= 1
+	_, err = keys.Unwrap(ctx, keyID, wrapped)
+	assert.ErrorIs(t, err, encryption.ErrUnwrapFailed)
+}
+
+func TestRewrap_MovesToActiveKey(t *testing.T) {
+	ctx := context.Background()
+	old, err := encryption.NewLocalKeyProvider(encryption.Config{MasterKeys: "k1:" + masterKey('a')})
+	require.NoError(t, err)
+	dataKey, _ := encryption.NewDataKey()
+	_, wrapped, err := old.Wrap(ctx, dataKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "master.keys")
+	require.NoError(t, os.WriteFile(keyFile, []byte("# rotated\nk1:"+masterKey('a')+"\nk2:"+masterKey('b')+"\n"), 0o600))
+	rotated, err := encryption.NewLocalKeyProvider(encryption.Config{MasterKeyFile: keyFile, ActiveKeyID: "k2"})
+	require.NoError(t, err)
+
+	keyID, rewrapped, err := encryption.Rewrap(ctx, rotated, "k1", wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, "k2", keyID)
+
+	got, err := rotated.Unwrap(ctx, keyID, rewrapped)
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, got)
+}
+
+func TestNewLocalKeyProvider_RejectsBadConfig(t *testing.T) {
+	cases := []encryption.Config{
+		{MasterKeys: "k1:c2hvcnQ="},
+		{MasterKeys: "no-separator"},
+		{MasterKeys: "k1:" + masterKey('a') + ",k2:" + masterKey('b')},
+		{MasterKeys: "k1:" + masterKey('a'), ActiveKeyID: "k9"},
+		{MasterKeys: "k1:" + masterKey('a') + ",k1:" + masterKey('b'), ActiveKeyID: "k1"},
+	}
+	for _, cfg := range cases {
+		_, err := encryption.NewLocalKeyProvider(cfg)
+		assert.Error(t, err, cfg.MasterKeys)
+	}
+}
@@ -0,0 +1,188 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Объект шифруется AES-256-GCM сегментами по SegmentSize байт открытого текста.
+// Nonce сегмента — его порядковый номер: ключ данных уникален для каждой версии, поэтому
+// пара (ключ, nonce) не повторяется. Последний сегмент помечается в additional data,
+// так что обрезанный или переставленный объект не расшифруется.
+const (
+	DataKeySize = 32
+	SegmentSize = 64 * 1024
+
+	tagSize = 16
+)
+
+var ErrCorrupted = errors.New("encrypted object is corrupted or truncated")
+
+func NewDataKey() ([]byte, error) {
+	key := make([]byte, DataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	return key, nil
+}
+
+// EncryptedSize — размер зашифрованного объекта для открытого текста длиной plainSize.
+func EncryptedSize(plainSize int64) int64 {
+	segments := plainSize / SegmentSize
+	if plainSize%SegmentSize != 0 || plainSize == 0 {
+		segments++
+	}
+	return plainSize + segments*tagSize
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func segmentNonce(counter uint64) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[4:], counter)
+	return nonce
+}
+
+func segmentAD(final bool) []byte {
+	if final {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+type encryptReader struct {
+	src     io.Reader
+	aead    cipher.AEAD
+	plain   []byte
+	out     []byte
+	counter uint64
+	done    bool
+	err     error
+}
+
+// NewEncryptReader возвращает поток шифротекста для src, читая его сегментами.
+func NewEncryptReader(src io.Reader, key []byte) (io.Reader, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptReader{src: src, aead: aead, plain: make([]byte, SegmentSize+1)}, nil
+}
+
+func (r *encryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.fill()
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// fill шифрует очередной сегмент. Читается на байт больше сегмента, чтобы заранее знать,
+// последний ли он; лишний байт переносится в начало следующего сегмента.
+func (r *encryptReader) fill() {
+	carried := 0
+	if r.counter > 0 {
+		carried = 1
+	}
+	n, err := io.ReadFull(r.src, r.plain[carried:])
+	n += carried
+	final := false
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		final = true
+	case err != nil:
+		r.err = err
+		return
+	}
+
+	segment := r.plain[:min(n, SegmentSize)]
+	r.out = r.aead.Seal(r.out[:0], segmentNonce(r.counter), segment, segmentAD(final))
+	r.counter++
+	if final {
+		r.done = true
+		return
+	}
+	r.plain[0] = r.plain[SegmentSize]
+}
+
+type decryptReader struct {
+	src     io.Reader
+	aead    cipher.AEAD
+	buf     []byte
+	out     []byte
+	counter uint64
+	done    bool
+	err     error
+}
+
+func NewDecryptReader(src io.Reader, key []byte) (io.Reader, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{src: src, aead: aead, buf: make([]byte, SegmentSize+tagSize+1)}, nil
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.fill()
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *decryptReader) fill() {
+	carried := 0
+	if r.counter > 0 {
+		carried = 1
+	}
+	n, err := io.ReadFull(r.src, r.buf[carried:])
+	n += carried
+	final := false
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		final = true
+	case err != nil:
+		r.err = err
+		return
+	}
+
+	segment := r.buf[:min(n, SegmentSize+tagSize)]
+	plain, openErr := r.aead.Open(r.out[:0], segmentNonce(r.counter), segment, segmentAD(final))
+	if openErr != nil {
+		r.err = ErrCorrupted
+		return
+	}
+	r.out = plain
+	r.counter++
+	if final {
+		r.done = true
+		return
+	}
+	r.buf[0] = r.buf[SegmentSize+tagSize]
+}
@@ -0,0 +1,73 @@
+package encryption_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"registration-service/internal/encryption"
+)
+
+func encrypt(t *testing.T, plain, key []byte) []byte {
+	t.Helper()
+	r, err := encryption.NewEncryptReader(bytes.NewReader(plain), key)
+	require.NoError(t, err)
+	sealed, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return sealed
+}
+
+func decrypt(key, sealed []byte) ([]byte, error) {
+	r, err := encryption.NewDecryptReader(bytes.NewReader(sealed), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestStream_RoundTrip(t *testing.T) {
+	key, err := encryption.NewDataKey()
+	require.NoError(t, err)
+
+	seg := encryption.SegmentSize
+	for _, size := range []int{0, 1, seg - 1, seg, seg + 1, 3*seg + 17} {
+		plain := make([]byte, size)
+		_, _ = rand.Read(plain)
+
+		sealed := encrypt(t, plain, key)
+		assert.Equal(t, encryption.EncryptedSize(int64(size)), int64(len(sealed)), "size %d", size)
+
+		got, err := decrypt(key, sealed)
+		require.NoError(t, err, "size %d", size)
+		assert.True(t, bytes.Equal(plain, got), "size %d", size)
+	}
+}
+
+func TestStream_DetectsTamperingAndTruncation(t *testing.T) {
+	key, _ := encryption.NewDataKey()
+	plain := bytes.Repeat([]byte("secret"), encryption.SegmentSize/2)
+	sealed := encrypt(t, plain, key)
+
+	tampered := append([]byte(nil), sealed...)
+	tampered[10] ^= 1
+	_, err := decrypt(key, tampered)
+	assert.ErrorIs(t, err, encryption.ErrCorrupted)
+
+	// отрезан последний сегмент целиком: предыдущий не помечен как последний
+	truncated := sealed[:encryption.SegmentSize+16]
+	_, err = decrypt(key, truncated)
+	assert.ErrorIs(t, err, encryption.ErrCorrupted)
+
+	otherKey, _ := encryption.NewDataKey()
+	_, err = decrypt(otherKey, sealed)
+	assert.ErrorIs(t, err, encryption.ErrCorrupted)
+}
+
+func TestDeriveKey_DiffersByPurpose(t *testing.T) {
+	key, _ := encryption.NewDataKey()
+	assert.NotEqual(t, encryption.DeriveKey(key, "content"), encryption.DeriveKey(key, "thumb-256"))
+	assert.Len(t, encryption.DeriveKey(key, "content"), encryption.DataKeySize)
+}
@@ -2,7 +2,9 @@ package authHandler
 
 import (
 	"context"
+	"errors"
 	auth "registration-service/api/authproto/proto-generate"
+	"registration-service/internal/clientip"
 	"registration-service/internal/service/authService"
 
 	"google.golang.org/grpc/codes"
@@ -11,25 +13,36 @@ import (
 
 type GRPChandler struct {
 	authService *authService.AuthService
+	clientIP    *clientip.Resolver
 	auth.UnimplementedAuthServiceServer
 }
 
-func New(service *authService.AuthService) *GRPChandler {
-	return &GRPChandler{authService: service}
+func New(service *authService.AuthService, clientIP *clientip.Resolver) *GRPChandler {
+	return &GRPChandler{authService: service, clientIP: clientIP}
 }
 
 func (h *GRPChandler) Register(ctx context.Context, req *auth.RegisterRequest) (*auth.RegisterResponse, error) {
 	userID, err := h.authService.Register(ctx, req.Username, req.Email, req.Password)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, profileError(err)
 	}
-	return &auth.RegisterResponse{Message: "user created", UserId: userID}, nil
+	return &auth.RegisterResponse{Message: "user created, check your email to confirm the address", UserId: userID}, nil
 }
 
 func (h *GRPChandler) Login(ctx context.Context, req *auth.LoginRequest) (*auth.LoginResponse, error) {
-	accesstoken, refreshToken, userID, err := h.authService.Login(ctx, req.Username, req.Password)
+	accesstoken, refreshToken, mfaToken, userID, err := h.authService.Login(ctx, req.Username, req.Password, h.clientInfo(ctx, req.DeviceName))
 	if err != nil {
-		return nil, status.Errorf(codes.Unauthenticated, err.Error())
+		var throttled *authService.LoginThrottledError
+		if errors.As(err, &throttled) {
+			return nil, throttledError(throttled)
+		}
+		if errors.Is(err, authService.ErrEmailNotVerified) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if mfaToken != "" {
+		return &auth.LoginResponse{UserId: userID, MfaRequired: true, MfaToken: mfaToken}, nil
 	}
 	return &auth.LoginResponse{Token: accesstoken, RefreshToken: refreshToken, UserId: userID}, nil
 }
@@ -49,9 +62,44 @@ func (h *GRPChandler) Logout(ctx context.Context, req *auth.LogoutRequest) (*aut
 }
 
 func (h *GRPChandler) RefreshToken(ctx context.Context, req *auth.RefreshTokenRequest) (*auth.RefreshTokenResponse, error) {
-	newToken, _, err := h.authService.RefreshToken(ctx, req.UserID, req.RefreshToken)
+	newToken, newRefreshToken, err := h.authService.RefreshToken(ctx, req.UserID, req.RefreshToken)
 	if err != nil {
-		return nil, status.Errorf(codes.Unauthenticated, err.Error())
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return &auth.RefreshTokenResponse{Token: newToken, RefreshToken: newRefreshToken}, nil
+}
+
+func (h *GRPChandler) VerifyEmail(ctx context.Context, req *auth.VerifyEmailRequest) (*auth.VerifyEmailResponse, error) {
+	userID, err := h.authService.VerifyEmail(ctx, req.Token)
+	if err != nil {
+		if errors.Is(err, authService.ErrInvalidVerificationToken) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &auth.VerifyEmailResponse{Message: "email verified", UserId: userID}, nil
+}
+
+func (h *GRPChandler) ResendVerification(ctx context.Context, req *auth.ResendVerificationRequest) (*auth.ResendVerificationResponse, error) {
+	if err := h.authService.ResendVerification(ctx, req.Email); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &auth.ResendVerificationResponse{Message: "if the address is registered and not yet verified, a new email has been sent"}, nil
+}
+
+func (h *GRPChandler) RequestPasswordReset(ctx context.Context, req *auth.RequestPasswordResetRequest) (*auth.RequestPasswordResetResponse, error) {
+	if err := h.authService.RequestPasswordReset(ctx, req.Email); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &auth.RequestPasswordResetResponse{Message: "if the address is registered, a password reset email has been sent"}, nil
+}
+
+func (h *GRPChandler) ResetPassword(ctx context.Context, req *auth.ResetPasswordRequest) (*auth.ResetPasswordResponse, error) {
+	if err := h.authService.ResetPassword(ctx, req.Token, req.NewPassword); err != nil {
+		if errors.Is(err, authService.ErrInvalidResetToken) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
 	}
-	return &auth.RefreshTokenResponse{Token: newToken}, nil
+	return &auth.ResetPasswordResponse{Message: "password changed, sign in again"}, nil
 }
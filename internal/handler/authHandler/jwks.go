@@ -0,0 +1,24 @@
+package authHandler
+
+import (
+	"context"
+	auth "registration-service/api/authproto/proto-generate"
+)
+
+func (h *GRPChandler) GetJWKS(ctx context.Context, req *auth.GetJWKSRequest) (*auth.GetJWKSResponse, error) {
+	set := h.authService.JWKS()
+	resp := &auth.GetJWKSResponse{Keys: make([]*auth.JWK, 0, len(set.Keys))}
+	for _, k := range set.Keys {
+		resp.Keys = append(resp.Keys, &auth.JWK{
+			Kty: k.KeyType,
+			Kid: k.KeyID,
+			Alg: k.Algorithm,
+			Use: k.Use,
+			Crv: k.Curve,
+			X:   k.X,
+			N:   k.N,
+			E:   k.E,
+		})
+	}
+	return resp, nil
+}
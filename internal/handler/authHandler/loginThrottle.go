@@ -0,0 +1,37 @@
+package authHandler
+
+import (
+	"context"
+	"errors"
+	auth "registration-service/api/authproto/proto-generate"
+	"registration-service/internal/service/authService"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func (h *GRPChandler) UnlockAccount(ctx context.Context, req *auth.UnlockAccountRequest) (*auth.UnlockAccountResponse, error) {
+	adminID, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.authService.UnlockAccount(ctx, adminID, req.UserId); err != nil {
+		if errors.Is(err, authService.ErrNotAdmin) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &auth.UnlockAccountResponse{Message: "account unlocked"}, nil
+}
+
+// throttledError сообщает клиенту, через сколько можно повторить вход, в стандартной детали
+// google.rpc.RetryInfo.
+func throttledError(err *authService.LoginThrottledError) error {
+	st := status.New(codes.ResourceExhausted, err.Error())
+	if detailed, detailsErr := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(err.RetryAfter)}); detailsErr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
@@ -0,0 +1,73 @@
+package authHandler
+
+import (
+	"context"
+	"errors"
+	auth "registration-service/api/authproto/proto-generate"
+	"registration-service/internal/service/authService"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *GRPChandler) EnrollTOTP(ctx context.Context, req *auth.EnrollTOTPRequest) (*auth.EnrollTOTPResponse, error) {
+	userID, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	secret, uri, err := h.authService.EnrollTOTP(ctx, userID)
+	if err != nil {
+		return nil, mfaError(err)
+	}
+	return &auth.EnrollTOTPResponse{Secret: secret, Uri: uri}, nil
+}
+
+func (h *GRPChandler) ConfirmTOTP(ctx context.Context, req *auth.ConfirmTOTPRequest) (*auth.ConfirmTOTPResponse, error) {
+	userID, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	recoveryCodes, err := h.authService.ConfirmTOTP(ctx, userID, req.Code)
+	if err != nil {
+		return nil, mfaError(err)
+	}
+	return &auth.ConfirmTOTPResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (h *GRPChandler) VerifyMFA(ctx context.Context, req *auth.VerifyMFARequest) (*auth.LoginResponse, error) {
+	accessToken, refreshToken, userID, err := h.authService.VerifyMFA(ctx, req.MfaToken, req.Code, h.clientInfo(ctx, req.DeviceName))
+	if err != nil {
+		return nil, mfaError(err)
+	}
+	return &auth.LoginResponse{Token: accessToken, RefreshToken: refreshToken, UserId: userID}, nil
+}
+
+func (h *GRPChandler) ResetMFA(ctx context.Context, req *auth.ResetMFARequest) (*auth.ResetMFAResponse, error) {
+	adminID, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.authService.ResetMFA(ctx, adminID, req.UserId); err != nil {
+		return nil, mfaError(err)
+	}
+	return &auth.ResetMFAResponse{Message: "two-factor authentication turned off"}, nil
+}
+
+func mfaError(err error) error {
+	var throttled *authService.LoginThrottledError
+	if errors.As(err, &throttled) {
+		return throttledError(throttled)
+	}
+	switch {
+	case errors.Is(err, authService.ErrInvalidMFACode), errors.Is(err, authService.ErrInvalidMFAToken):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, authService.ErrTooManyMFAAttempts):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, authService.ErrMFAAlreadyEnabled), errors.Is(err, authService.ErrMFANotEnrolled):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, authService.ErrNotAdmin):
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,120 @@
+package authHandler
+
+import (
+	"context"
+	"errors"
+	auth "registration-service/api/authproto/proto-generate"
+	"registration-service/internal/model/user"
+	"registration-service/internal/service/authService"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authenticate проверяет access-токен из метаданных authorization и возвращает ID пользователя.
+// Сервис авторизации не стоит за AuthInterceptor, поэтому методы аккаунта проверяют токен сами.
+func (h *GRPChandler) authenticate(ctx context.Context) (uint32, error) {
+	uid, _, err := h.authenticateSession(ctx)
+	return uid, err
+}
+
+// authenticateSession — то же, что authenticate, но возвращает ещё и ID сессии токена.
+func (h *GRPChandler) authenticateSession(ctx context.Context) (uint32, string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0, "", status.Error(codes.Unauthenticated, "metadata not provided")
+	}
+	authHeader := md.Get("authorization")
+	if len(authHeader) == 0 {
+		return 0, "", status.Error(codes.Unauthenticated, "authorization token not provided")
+	}
+	uid, sessionID, isValid := h.authService.GetSessionByToken(ctx, strings.TrimPrefix(authHeader[0], "Bearer "))
+	if !isValid {
+		return 0, "", status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return uid, sessionID, nil
+}
+
+func (h *GRPChandler) ChangePassword(ctx context.Context, req *auth.ChangePasswordRequest) (*auth.ChangePasswordResponse, error) {
+	userID, sessionID, err := h.authenticateSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accessToken, refreshToken, err := h.authService.ChangePassword(ctx, userID, sessionID, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		return nil, profileError(err)
+	}
+	return &auth.ChangePasswordResponse{Token: accessToken, RefreshToken: refreshToken}, nil
+}
+
+func (h *GRPChandler) ChangeEmail(ctx context.Context, req *auth.ChangeEmailRequest) (*auth.ChangeEmailResponse, error) {
+	userID, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.authService.ChangeEmail(ctx, userID, req.CurrentPassword, req.NewEmail); err != nil {
+		return nil, profileError(err)
+	}
+	return &auth.ChangeEmailResponse{Message: "email changed, check your inbox to confirm the new address"}, nil
+}
+
+func (h *GRPChandler) ChangeUsername(ctx context.Context, req *auth.ChangeUsernameRequest) (*auth.ChangeUsernameResponse, error) {
+	userID, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.authService.ChangeUsername(ctx, userID, req.Username); err != nil {
+		return nil, profileError(err)
+	}
+	return &auth.ChangeUsernameResponse{Message: "username changed"}, nil
+}
+
+func (h *GRPChandler) GetProfile(ctx context.Context, req *auth.GetProfileRequest) (*auth.Profile, error) {
+	userID, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	u, err := h.authService.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return profileInfo(u), nil
+}
+
+func (h *GRPChandler) UpdateProfile(ctx context.Context, req *auth.UpdateProfileRequest) (*auth.Profile, error) {
+	userID, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	u, err := h.authService.UpdateProfile(ctx, userID, req.DisplayName, req.AvatarUrl)
+	if err != nil {
+		return nil, profileError(err)
+	}
+	return profileInfo(u), nil
+}
+
+func profileError(err error) error {
+	switch {
+	case errors.Is(err, authService.ErrInvalidArgument):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, authService.ErrWrongPassword):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, authService.ErrEmailTaken), errors.Is(err, authService.ErrUsernameTaken):
+		return status.Error(codes.AlreadyExists, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func profileInfo(u *user.User) *auth.Profile {
+	return &auth.Profile{
+		UserId:        uint32(u.ID),
+		Username:      u.Username,
+		Email:         u.Email,
+		EmailVerified: u.EmailVerified,
+		DisplayName:   u.DisplayName,
+		AvatarUrl:     u.AvatarURL,
+	}
+}
@@ -0,0 +1,88 @@
+package authHandler
+
+import (
+	"context"
+	"errors"
+	auth "registration-service/api/authproto/proto-generate"
+	"registration-service/internal/service/authService"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// clientInfo собирает сведения об устройстве для новой сессии. Адрес клиента определяет
+// clientip.Resolver: x-forwarded-for учитывается только от доверенных прокси.
+func (h *GRPChandler) clientInfo(ctx context.Context, deviceName string) authService.ClientInfo {
+	client := authService.ClientInfo{DeviceName: deviceName, IP: h.clientIP.IP(ctx)}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ua := md.Get("user-agent"); len(ua) > 0 {
+			client.UserAgent = ua[0]
+		}
+	}
+	return client
+}
+
+func (h *GRPChandler) ListSessions(ctx context.Context, req *auth.ListSessionsRequest) (*auth.ListSessionsResponse, error) {
+	userID, sessionID, err := h.authenticateSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := h.authService.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	resp := &auth.ListSessionsResponse{Sessions: make([]*auth.Session, 0, len(sessions))}
+	for _, s := range sessions {
+		resp.Sessions = append(resp.Sessions, &auth.Session{
+			Id:         s.ID,
+			DeviceName: s.DeviceName,
+			UserAgent:  s.UserAgent,
+			Ip:         s.IP,
+			CreatedAt:  s.CreatedAt.Unix(),
+			LastUsedAt: s.LastUsedAt.Unix(),
+			Current:    s.ID == sessionID,
+		})
+	}
+	return resp, nil
+}
+
+func (h *GRPChandler) RevokeSession(ctx context.Context, req *auth.RevokeSessionRequest) (*auth.RevokeSessionResponse, error) {
+	userID, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.authService.RevokeSession(ctx, userID, req.SessionId); err != nil {
+		if errors.Is(err, authService.ErrSessionNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &auth.RevokeSessionResponse{Message: "session revoked"}, nil
+}
+
+func (h *GRPChandler) RevokeAllOtherSessions(ctx context.Context, req *auth.RevokeAllOtherSessionsRequest) (*auth.RevokeAllOtherSessionsResponse, error) {
+	userID, sessionID, err := h.authenticateSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	revoked, err := h.authService.RevokeAllOtherSessions(ctx, userID, sessionID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &auth.RevokeAllOtherSessionsResponse{Message: "other sessions revoked", Revoked: uint32(revoked)}, nil
+}
+
+func (h *GRPChandler) RevokeAllTokens(ctx context.Context, req *auth.RevokeAllTokensRequest) (*auth.RevokeAllTokensResponse, error) {
+	callerID, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.authService.RevokeAllTokens(ctx, callerID, req.UserId); err != nil {
+		if errors.Is(err, authService.ErrNotAdmin) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &auth.RevokeAllTokensResponse{Message: "all tokens revoked"}, nil
+}
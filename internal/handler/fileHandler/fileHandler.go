@@ -1,13 +1,17 @@
 package fileHandler
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"io"
 	"log"
 	fileproto "registration-service/api/fileproto/proto-generate"
 	"registration-service/internal/model/fileInfo"
+	"registration-service/internal/model/webhookInfo"
 	"registration-service/internal/service/fileService"
+	"registration-service/internal/thumbnail"
 
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
@@ -66,6 +70,9 @@ func (h *FileHandler) DownloadFile(req *fileproto.DownloadFileRequest, stream fi
 		if err.Error() == "file not found" {
 			return status.Error(codes.NotFound, "file not found")
 		}
+		if errors.Is(err, fileService.ErrScanPending) || errors.Is(err, fileService.ErrFileInfected) {
+			return status.Error(codes.FailedPrecondition, err.Error())
+		}
 		log.Printf("[FileHandler.DownloadFile] Error from service on DownloadFile call: %v", err)
 		return status.Error(codes.Internal, "cannot download file")
 	}
@@ -109,7 +116,10 @@ func (h *FileHandler) ListFiles(ctx context.Context, req *fileproto.ListFilesReq
 	}
 	log.Printf("[fileHandler.ListFiles] UserID %d retrieved from context", userID)
 
-	files, err := h.fileService.ListFiles(ctx, req.IncludeShared)
+	files, err := h.fileService.ListFiles(ctx, req.IncludeShared, fileInfo.FileFilter{
+		Tags:       req.Tags,
+		Properties: req.Properties,
+	})
 	if err != nil {
 		log.Printf("[fileHandler.ListFiles] ERROR calling h.fileService.ListFiles: %v", err)
 		return nil, status.Error(codes.Internal, err.Error())
@@ -127,13 +137,16 @@ func (h *FileHandler) ListFiles(ctx context.Context, req *fileproto.ListFilesReq
 		log.Printf("[fileHandler.ListFiles] Successfully processed file ID %s. Name: %s, Version: %d", file.ID.String(), fileInfo.Name, fileVers.VersionNumber)
 
 		fileInfos = append(fileInfos, &fileproto.FileInfo{
-			FileId:    file.ID.String(),
-			Name:      fileInfo.Name,
-			Size:      fileVers.Size,
-			Version:   fileVers.VersionNumber,
-			CreatedAt: file.CreatedAt.Unix(),
-			UpdatedAt: fileInfo.CreatedAt.Unix(),
-			IsOwner:   file.OwnerID == userID,
+			FileId:      file.ID.String(),
+			Name:        fileInfo.Name,
+			Size:        fileVers.Size,
+			Version:     fileVers.VersionNumber,
+			ContentType: fileVers.ContentType,
+			CreatedAt:   file.CreatedAt.Unix(),
+			UpdatedAt:   fileInfo.CreatedAt.Unix(),
+			IsOwner:     file.OwnerID == userID,
+			Tags:        fileInfo.Tags,
+			Properties:  fileInfo.Properties,
 		})
 	}
 	log.Printf("[fileHandler.ListFiles] Successfully prepared %d FileInfo objects for response", len(fileInfos))
@@ -172,10 +185,12 @@ func (h *FileHandler) GetFileInfo(ctx context.Context, req *fileproto.GetFileInf
 			Name:        fileInfo.Name,
 			Size:        fileVers.Size,
 			Version:     uint32(fileInfo.CurrentVersion),
-			ContentType: "application/octet-stream",
+			ContentType: fileVers.ContentType,
 			CreatedAt:   fileInfo.CreatedAt.Unix(),
 			UpdatedAt:   fileVers.CreatedAt.Unix(),
 			IsOwner:     fileInfo.OwnerID == userID,
+			Tags:        fileInfo.Tags,
+			Properties:  fileInfo.Properties,
 		},
 	}, nil
 }
@@ -224,9 +239,14 @@ func (h *FileHandler) GetFileVersions(ctx context.Context, req *fileproto.GetFil
 	var fileVers []*fileproto.FileVersionInfo
 	for _, version := range versions {
 		fileVers = append(fileVers, &fileproto.FileVersionInfo{
-			VersionNumber: uint32(version.VersionNumber),
-			Size:          version.Size,
-			CreatedAt:     version.CreatedAt.Unix(),
+			VersionNumber:     uint32(version.VersionNumber),
+			Size:              version.Size,
+			CreatedAt:         version.CreatedAt.Unix(),
+			StoredSize:        version.StoredSize,
+			LogicalSize:       version.Size,
+			ScanStatus:        version.ScanStatus,
+			Tier:              version.Tier,
+			ReplicationStatus: version.ReplicationStatus,
 		})
 	}
 	return &fileproto.GetFileVersionsResponse{Versions: fileVers}, nil
@@ -240,6 +260,9 @@ func (h *FileHandler) RevertFileVersion(ctx context.Context, req *fileproto.Reve
 
 	newFile, err := h.fileService.RevertFileVersion(ctx, fileID, int(req.Version))
 	if err != nil {
+		if errors.Is(err, fileService.ErrScanPending) || errors.Is(err, fileService.ErrFileInfected) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
@@ -248,3 +271,377 @@ func (h *FileHandler) RevertFileVersion(ctx context.Context, req *fileproto.Reve
 		NewFileId: newFile.ID.String(),
 	}, nil
 }
+
+func (h *FileHandler) CreateWebhook(ctx context.Context, req *fileproto.CreateWebhookRequest) (*fileproto.CreateWebhookResponse, error) {
+	hook, err := h.fileService.CreateWebhook(ctx, req.Url, req.EventTypes)
+	if err != nil {
+		if errors.Is(err, fileService.ErrInvalidWebhook) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &fileproto.CreateWebhookResponse{
+		Webhook: toWebhookProto(hook),
+		Secret:  hook.Secret,
+	}, nil
+}
+
+func (h *FileHandler) ListWebhooks(ctx context.Context, req *fileproto.ListWebhooksRequest) (*fileproto.ListWebhooksResponse, error) {
+	hooks, err := h.fileService.ListWebhooks(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	var webhooks []*fileproto.WebhookInfo
+	for _, hook := range hooks {
+		webhooks = append(webhooks, toWebhookProto(hook))
+	}
+	return &fileproto.ListWebhooksResponse{Webhooks: webhooks}, nil
+}
+
+func (h *FileHandler) ListDeadLetters(ctx context.Context, req *fileproto.ListDeadLettersRequest) (*fileproto.ListDeadLettersResponse, error) {
+	var webhookID uuid.UUID
+	if req.WebhookId != "" {
+		var err error
+		if webhookID, err = uuid.Parse(req.WebhookId); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid webhook id")
+		}
+	}
+	deliveries, err := h.fileService.ListDeadLetters(ctx, webhookID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	var result []*fileproto.WebhookDelivery
+	for _, d := range deliveries {
+		result = append(result, &fileproto.WebhookDelivery{
+			DeliveryId: d.ID.String(),
+			WebhookId:  d.WebhookID.String(),
+			EventType:  d.EventType,
+			Attempts:   int32(d.Attempts),
+			LastError:  d.LastError,
+			CreatedAt:  d.CreatedAt.Unix(),
+		})
+	}
+	return &fileproto.ListDeadLettersResponse{Deliveries: result}, nil
+}
+
+func (h *FileHandler) RedeliverWebhook(ctx context.Context, req *fileproto.RedeliverWebhookRequest) (*fileproto.RedeliverWebhookResponse, error) {
+	deliveryID, err := uuid.Parse(req.DeliveryId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid delivery id")
+	}
+	if err := h.fileService.RedeliverWebhook(ctx, deliveryID); err != nil {
+		if errors.Is(err, fileService.ErrDeliveryNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &fileproto.RedeliverWebhookResponse{Success: true}, nil
+}
+
+func toWebhookProto(hook *webhookInfo.Webhook) *fileproto.WebhookInfo {
+	return &fileproto.WebhookInfo{
+		WebhookId:  hook.ID.String(),
+		Url:        hook.URL,
+		EventTypes: hook.EventTypes,
+		CreatedAt:  hook.CreatedAt.Unix(),
+	}
+}
+
+func (h *FileHandler) SearchFiles(ctx context.Context, req *fileproto.SearchFilesRequest) (*fileproto.SearchFilesResponse, error) {
+	userID, ok := ctx.Value("userID").(uint32)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+	results, err := h.fileService.SearchFiles(ctx, req.Query, int(req.Limit), int(req.Offset))
+	if err != nil {
+		if errors.Is(err, fileService.ErrEmptySearchQuery) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	var found []*fileproto.SearchResult
+	for _, result := range results {
+		file, fileVers, err := h.fileService.GetFileWithVersion(ctx, result.File.ID)
+		if err != nil {
+			log.Printf("[fileHandler.SearchFiles] skipping file %s: %v", result.File.ID, err)
+			continue
+		}
+		found = append(found, &fileproto.SearchResult{
+			File: &fileproto.FileInfo{
+				FileId:      file.ID.String(),
+				Name:        file.Name,
+				Size:        fileVers.Size,
+				Version:     fileVers.VersionNumber,
+				ContentType: fileVers.ContentType,
+				CreatedAt:   file.CreatedAt.Unix(),
+				UpdatedAt:   fileVers.CreatedAt.Unix(),
+				IsOwner:     file.OwnerID == userID,
+				Tags:        file.Tags,
+				Properties:  file.Properties,
+			},
+			Rank:    result.Rank,
+			Snippet: result.Snippet,
+		})
+	}
+	return &fileproto.SearchFilesResponse{Results: found}, nil
+}
+
+func (h *FileHandler) SetFileTags(ctx context.Context, req *fileproto.SetFileTagsRequest) (*fileproto.SetFileTagsResponse, error) {
+	fileID, err := uuid.Parse(req.FileId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid file id")
+	}
+	tags, err := h.fileService.SetFileTags(ctx, fileID, req.Tags)
+	if err != nil {
+		if errors.Is(err, fileService.ErrInvalidMetadata) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &fileproto.SetFileTagsResponse{Success: true, Tags: tags}, nil
+}
+
+func (h *FileHandler) SetFileProperties(ctx context.Context, req *fileproto.SetFilePropertiesRequest) (*fileproto.SetFilePropertiesResponse, error) {
+	fileID, err := uuid.Parse(req.FileId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid file id")
+	}
+	if err := h.fileService.SetFileProperties(ctx, fileID, req.Properties); err != nil {
+		if errors.Is(err, fileService.ErrInvalidMetadata) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &fileproto.SetFilePropertiesResponse{Success: true}, nil
+}
+
+func (h *FileHandler) GetThumbnail(ctx context.Context, req *fileproto.GetThumbnailRequest) (*fileproto.GetThumbnailResponse, error) {
+	fileID, err := uuid.Parse(req.FileId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid file id")
+	}
+	data, contentType, size, err := h.fileService.GetThumbnail(ctx, fileID, int(req.Size))
+	if err != nil {
+		switch {
+		case err.Error() == "file not found":
+			return nil, status.Error(codes.NotFound, "file not found")
+		case errors.Is(err, thumbnail.ErrUnsupportedImage), errors.Is(err, thumbnail.ErrImageTooLarge),
+			errors.Is(err, fileService.ErrScanPending), errors.Is(err, fileService.ErrFileInfected):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		log.Printf("[FileHandler.GetThumbnail] Error from service on GetThumbnail call: %v", err)
+		return nil, status.Error(codes.Internal, "cannot get thumbnail")
+	}
+	return &fileproto.GetThumbnailResponse{
+		Data:        data,
+		ContentType: contentType,
+		Size:        uint32(size),
+	}, nil
+}
+
+func (h *FileHandler) DownloadArchive(req *fileproto.DownloadArchiveRequest, stream fileproto.FileService_DownloadArchiveServer) error {
+	out := bufio.NewWriterSize(&archiveChunkWriter{stream: stream}, 1024*32)
+	if err := h.fileService.WriteArchive(stream.Context(), req.FileIds, out); err != nil {
+		if errors.Is(err, fileService.ErrInvalidArchiveRequest) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		log.Printf("[FileHandler.DownloadArchive] Error from service on WriteArchive call: %v", err)
+		return status.Error(codes.Internal, "cannot build archive")
+	}
+	if err := out.Flush(); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// archiveChunkWriter отправляет каждый Write отдельным сообщением стрима.
+type archiveChunkWriter struct {
+	stream interface {
+		Send(*fileproto.DownloadArchiveResponse) error
+	}
+}
+
+func (w *archiveChunkWriter) Write(p []byte) (int, error) {
+	if err := w.stream.Send(&fileproto.DownloadArchiveResponse{Chunk: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (h *FileHandler) UploadArchive(stream fileproto.FileService_UploadArchiveServer) error {
+	ctx := stream.Context()
+	first, err := stream.Recv()
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "metadata is required")
+	}
+	metadata := first.GetMetadata()
+	if metadata == nil {
+		return status.Error(codes.InvalidArgument, "metadata is required")
+	}
+
+	results, err := h.fileService.UploadArchive(ctx, metadata.Name, metadata.Format, &archiveChunkReader{recv: func() ([]byte, error) {
+		req, err := stream.Recv()
+		return req.GetChunk(), err
+	}})
+	if err != nil {
+		if errors.Is(err, fileService.ErrArchiveRejected) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		log.Printf("[FileHandler.UploadArchive] Error from service on UploadArchive call: %v", err)
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &fileproto.UploadArchiveResponse{}
+	for _, result := range results {
+		entry := &fileproto.ArchiveEntryResult{
+			Path:   result.Path,
+			Status: result.Status,
+			Error:  result.Error,
+		}
+		switch result.Status {
+		case fileInfo.ArchiveEntryCreated:
+			entry.FileId = result.FileID.String()
+			resp.Created++
+		case fileInfo.ArchiveEntryFailed:
+			resp.Failed++
+		}
+		resp.Entries = append(resp.Entries, entry)
+	}
+	return stream.SendAndClose(resp)
+}
+
+// archiveChunkReader отдаёт чанки клиентского стрима как непрерывный io.Reader.
+type archiveChunkReader struct {
+	recv func() ([]byte, error)
+	buf  []byte
+}
+
+func (r *archiveChunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		chunk, err := r.recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = chunk
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (h *FileHandler) StartExport(ctx context.Context, req *fileproto.StartExportRequest) (*fileproto.StartExportResponse, error) {
+	export, err := h.fileService.StartExport(ctx)
+	if err != nil {
+		log.Printf("[FileHandler.StartExport] Error from service on StartExport call: %v", err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &fileproto.StartExportResponse{Export: exportInfo(export)}, nil
+}
+
+func (h *FileHandler) GetExportStatus(ctx context.Context, req *fileproto.GetExportStatusRequest) (*fileproto.GetExportStatusResponse, error) {
+	exportID, err := uuid.Parse(req.ExportId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid export ID")
+	}
+	export, err := h.fileService.GetExport(ctx, exportID)
+	if err != nil {
+		if errors.Is(err, fileService.ErrExportNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		log.Printf("[FileHandler.GetExportStatus] Error from service on GetExport call: %v", err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &fileproto.GetExportStatusResponse{Export: exportInfo(export)}, nil
+}
+
+func (h *FileHandler) DownloadExport(req *fileproto.DownloadExportRequest, stream fileproto.FileService_DownloadExportServer) error {
+	exportID, err := uuid.Parse(req.ExportId)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "invalid export ID")
+	}
+	reader, _, err := h.fileService.OpenExport(stream.Context(), exportID)
+	if err != nil {
+		switch {
+		case errors.Is(err, fileService.ErrExportNotFound):
+			return status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, fileService.ErrExportNotReady):
+			return status.Error(codes.FailedPrecondition, err.Error())
+		}
+		log.Printf("[FileHandler.DownloadExport] Error from service on OpenExport call: %v", err)
+		return status.Error(codes.Internal, "cannot open export")
+	}
+	defer reader.Close()
+
+	if _, err := io.CopyBuffer(&archiveChunkWriter{stream: stream}, reader, make([]byte, 1024*32)); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+func (h *FileHandler) ImportArchive(stream fileproto.FileService_ImportArchiveServer) error {
+	ctx := stream.Context()
+	first, err := stream.Recv()
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "metadata is required")
+	}
+	metadata := first.GetMetadata()
+	if metadata == nil {
+		return status.Error(codes.InvalidArgument, "metadata is required")
+	}
+
+	results, err := h.fileService.ImportArchive(ctx, metadata.TargetUserId, metadata.RestorePermissions, &archiveChunkReader{recv: func() ([]byte, error) {
+		req, err := stream.Recv()
+		return req.GetChunk(), err
+	}})
+	if err != nil {
+		if errors.Is(err, fileService.ErrArchiveRejected) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, fileService.ErrNotAdmin) {
+			return status.Error(codes.PermissionDenied, err.Error())
+		}
+		if errors.Is(err, fileService.ErrImportTargetNotFound) {
+			return status.Error(codes.NotFound, err.Error())
+		}
+		log.Printf("[FileHandler.ImportArchive] Error from service on ImportArchive call: %v", err)
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &fileproto.ImportArchiveResponse{}
+	for _, result := range results {
+		file := &fileproto.ImportedFile{
+			SourceFileId: result.SourceFileID,
+			Name:         result.Name,
+			Versions:     int32(result.Versions),
+			Status:       result.Status,
+			Error:        result.Error,
+		}
+		switch result.Status {
+		case fileInfo.ArchiveEntryCreated:
+			file.FileId = result.FileID.String()
+			resp.Imported++
+		case fileInfo.ArchiveEntryFailed:
+			resp.Failed++
+		}
+		resp.Files = append(resp.Files, file)
+	}
+	return stream.SendAndClose(resp)
+}
+
+func exportInfo(export *fileInfo.Export) *fileproto.ExportInfo {
+	info := &fileproto.ExportInfo{
+		ExportId:  export.ID.String(),
+		Status:    export.Status,
+		Size:      export.Size,
+		Error:     export.Error,
+		CreatedAt: export.CreatedAt.Unix(),
+	}
+	if export.CompletedAt != nil {
+		info.CompletedAt = export.CompletedAt.Unix()
+	}
+	if export.ExpiresAt != nil {
+		info.ExpiresAt = export.ExpiresAt.Unix()
+	}
+	return info
+}
@@ -0,0 +1,95 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"registration-service/internal/model/signingKey"
+	"sort"
+	"time"
+)
+
+// JWK — открытый ключ в формате RFC 7517: RSA (kty RSA) или Ed25519 (kty OKP, RFC 8037).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKSPath — путь, по которому сервис авторизации отдаёт JWKS по HTTP.
+const JWKSPath = "/.well-known/jwks.json"
+
+// jwksMaxAge — сколько клиенты могут кешировать JWKS. Новый ключ начинает подписывать сразу,
+// поэтому проверяющие должны перечитывать JWKS, встретив незнакомый kid.
+const jwksMaxAge = 5 * time.Minute
+
+var b64 = base64.RawURLEncoding
+
+func NewJWK(kid, algorithm string, public crypto.PublicKey) (JWK, error) {
+	jwk := JWK{KeyID: kid, Algorithm: algorithm, Use: "sig"}
+	switch pub := public.(type) {
+	case ed25519.PublicKey:
+		jwk.KeyType, jwk.Curve, jwk.X = "OKP", "Ed25519", b64.EncodeToString(pub)
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = b64.EncodeToString(pub.N.Bytes())
+		jwk.E = b64.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	default:
+		return JWK{}, fmt.Errorf("%w: %T", ErrUnsupportedAlgorithm, public)
+	}
+	return jwk, nil
+}
+
+// PublicKey восстанавливает открытый ключ из JWK.
+func (j JWK) PublicKey() (crypto.PublicKey, error) {
+	switch {
+	case j.KeyType == "OKP" && j.Curve == "Ed25519" && j.Algorithm == signingKey.AlgEdDSA:
+		x, err := b64.DecodeString(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key %q", j.KeyID)
+		}
+		return ed25519.PublicKey(x), nil
+	case j.KeyType == "RSA" && j.Algorithm == signingKey.AlgRS256:
+		n, err := b64.DecodeString(j.N)
+		if err != nil || len(n) == 0 {
+			return nil, fmt.Errorf("invalid RSA modulus of key %q", j.KeyID)
+		}
+		e, err := b64.DecodeString(j.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid RSA exponent of key %q", j.KeyID)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	default:
+		return nil, fmt.Errorf("%w: kty %q, alg %q", ErrUnsupportedAlgorithm, j.KeyType, j.Algorithm)
+	}
+}
+
+// ServeHTTP отдаёт JWKS; ключи отсортированы, чтобы ответ не менялся от запроса к запросу.
+func (r *KeyRing) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	set := r.JWKS()
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(jwksMaxAge/time.Second)))
+	if err := json.NewEncoder(w).Encode(set); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,291 @@
+// Package jwtkeys хранит ключи подписи access-токенов и публикует их открытые части в JWKS,
+// чтобы другие сервисы проверяли токены сами, без общего секрета.
+package jwtkeys
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"registration-service/internal/encryption"
+	"registration-service/internal/model/signingKey"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownKey           = errors.New("unknown signing key")
+	ErrNoActiveKey          = errors.New("no active signing key")
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+)
+
+const rsaKeyBits = 2048
+
+type Config struct {
+	// Algorithm — EdDSA (Ed25519) или RS256
+	Algorithm        string        `env:"JWT_SIGNING_ALG" env-default:"EdDSA"`
+	RotationInterval time.Duration `env:"JWT_KEY_ROTATION_INTERVAL" env-default:"720h"`
+	// Overlap — сколько прежний ключ публикуется после ротации; должен быть не меньше срока
+	// жизни access-токена, иначе выданные им токены перестанут проверяться
+	Overlap time.Duration `env:"JWT_KEY_OVERLAP" env-default:"24h"`
+	// RefreshInterval — как часто экземпляр перечитывает ключи, выпущенные другими экземплярами
+	RefreshInterval time.Duration `env:"JWT_KEY_REFRESH_INTERVAL" env-default:"1m"`
+	// AcceptHS256 принимает токены без kid, подписанные общим секретом до перехода на
+	// асимметричные ключи. Включается только на время миграции, пока не истекут такие токены
+	AcceptHS256 bool `env:"JWT_ACCEPT_HS256" env-default:"false"`
+}
+
+// Store — часть signingKeyRepo.SigningKeyRepo, которая нужна связке ключей.
+type Store interface {
+	ListKeys(ctx context.Context) ([]*signingKey.SigningKey, error)
+	RotateKey(ctx context.Context, key *signingKey.SigningKey, staleBefore time.Time, overlap time.Duration) (bool, error)
+	DeleteRetiredKeys(ctx context.Context) (int64, error)
+}
+
+type key struct {
+	id        string
+	algorithm string
+	private   crypto.Signer
+	createdAt time.Time
+}
+
+func (k *key) method() jwt.SigningMethod {
+	if k.algorithm == signingKey.AlgRS256 {
+		return jwt.SigningMethodRS256
+	}
+	return jwt.SigningMethodEdDSA
+}
+
+// KeyRing подписывает токены действующим ключом и проверяет их любым опубликованным.
+// Ключи общие для всех экземпляров сервиса и хранятся в Store.
+type KeyRing struct {
+	store   Store
+	wrapper encryption.KeyProvider
+	cfg     Config
+
+	mu     sync.RWMutex
+	active *key
+	keys   map[string]*key
+}
+
+// NewKeyRing создаёт связку ключей; wrapper может быть nil, тогда закрытые ключи хранятся
+// без шифрования.
+func NewKeyRing(store Store, wrapper encryption.KeyProvider, cfg Config) (*KeyRing, error) {
+	if cfg.Algorithm != signingKey.AlgEdDSA && cfg.Algorithm != signingKey.AlgRS256 {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, cfg.Algorithm)
+	}
+	return &KeyRing{store: store, wrapper: wrapper, cfg: cfg, keys: map[string]*key{}}, nil
+}
+
+// Load выпускает первый ключ, если его ещё нет или пора ротировать, и загружает опубликованные ключи.
+func (r *KeyRing) Load(ctx context.Context) error {
+	if err := r.rotateIfDue(ctx); err != nil {
+		return err
+	}
+	return r.refresh(ctx)
+}
+
+func (r *KeyRing) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := r.Load(ctx); err != nil {
+			log.Printf("[jwtkeys.KeyRing] failed to refresh keys: %v", err)
+		}
+		if _, err := r.store.DeleteRetiredKeys(ctx); err != nil {
+			log.Printf("[jwtkeys.KeyRing] failed to delete retired keys: %v", err)
+		}
+	}
+}
+
+func (r *KeyRing) rotateIfDue(ctx context.Context) error {
+	now := time.Now()
+	r.mu.RLock()
+	active := r.active
+	r.mu.RUnlock()
+	if active != nil && active.algorithm == r.cfg.Algorithm && now.Sub(active.createdAt) < r.cfg.RotationInterval {
+		return nil
+	}
+
+	stored, err := r.generate(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	rotated, err := r.store.RotateKey(ctx, stored, now.Add(-r.cfg.RotationInterval), r.cfg.Overlap)
+	if err != nil {
+		return fmt.Errorf("failed to rotate signing key: %w", err)
+	}
+	if rotated {
+		log.Printf("[jwtkeys.KeyRing] rotated signing key, new kid %s", stored.ID)
+	}
+	return nil
+}
+
+func (r *KeyRing) generate(ctx context.Context, now time.Time) (*signingKey.SigningKey, error) {
+	var private crypto.Signer
+	var err error
+	switch r.cfg.Algorithm {
+	case signingKey.AlgRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	default:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	stored := &signingKey.SigningKey{ID: uuid.NewString(), Algorithm: r.cfg.Algorithm, PrivateKey: der, CreatedAt: now}
+	if r.wrapper != nil {
+		stored.WrapKeyID, stored.PrivateKey, err = r.wrapper.Wrap(ctx, der)
+		if err != nil {
+			return nil, fmt.Errorf("failed to wrap private key: %w", err)
+		}
+	}
+	return stored, nil
+}
+
+func (r *KeyRing) refresh(ctx context.Context) error {
+	stored, err := r.store.ListKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list signing keys: %w", err)
+	}
+	keys := make(map[string]*key, len(stored))
+	var active *key
+	for _, s := range stored {
+		k, err := r.decode(ctx, s)
+		if err != nil {
+			log.Printf("[jwtkeys.KeyRing] skipping key %s: %v", s.ID, err)
+			continue
+		}
+		keys[k.id] = k
+		if s.RetireAt == nil && (active == nil || k.createdAt.After(active.createdAt)) {
+			active = k
+		}
+	}
+	if active == nil {
+		return ErrNoActiveKey
+	}
+
+	r.mu.Lock()
+	r.active, r.keys = active, keys
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *KeyRing) decode(ctx context.Context, stored *signingKey.SigningKey) (*key, error) {
+	der := stored.PrivateKey
+	if stored.WrapKeyID != "" {
+		if r.wrapper == nil {
+			return nil, fmt.Errorf("key is wrapped with %q, but no master keys are configured", stored.WrapKeyID)
+		}
+		var err error
+		if der, err = r.wrapper.Unwrap(ctx, stored.WrapKeyID, der); err != nil {
+			return nil, err
+		}
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	private, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedAlgorithm, parsed)
+	}
+	switch private.(type) {
+	case ed25519.PrivateKey:
+		if stored.Algorithm != signingKey.AlgEdDSA {
+			return nil, fmt.Errorf("%w: Ed25519 key stored as %s", ErrUnsupportedAlgorithm, stored.Algorithm)
+		}
+	case *rsa.PrivateKey:
+		if stored.Algorithm != signingKey.AlgRS256 {
+			return nil, fmt.Errorf("%w: RSA key stored as %s", ErrUnsupportedAlgorithm, stored.Algorithm)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedAlgorithm, private)
+	}
+	return &key{id: stored.ID, algorithm: stored.Algorithm, private: private, createdAt: stored.CreatedAt}, nil
+}
+
+// Sign подписывает claims действующим ключом и ставит его идентификатор в заголовок kid.
+func (r *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	r.mu.RLock()
+	active := r.active
+	r.mu.RUnlock()
+	if active == nil {
+		return "", ErrNoActiveKey
+	}
+	token := jwt.NewWithClaims(active.method(), claims)
+	token.Header["kid"] = active.id
+	return token.SignedString(active.private)
+}
+
+// Keyfunc находит открытый ключ по kid для jwt.Parse. Алгоритм токена должен совпадать с
+// алгоритмом ключа, иначе токен отклоняется.
+func (r *KeyRing) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	r.mu.RLock()
+	k, ok := r.keys[kid]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	if token.Method.Alg() != k.algorithm {
+		return nil, fmt.Errorf("%w: token signed with %s, key is %s", ErrUnsupportedAlgorithm, token.Method.Alg(), k.algorithm)
+	}
+	return k.private.Public(), nil
+}
+
+// VerificationKeyfunc — Keyfunc, который при AcceptHS256 дополнительно принимает токены без
+// kid, подписанные HS256 общим секретом legacySecret.
+func (r *KeyRing) VerificationKeyfunc(legacySecret []byte) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if _, hasKID := token.Header["kid"]; !hasKID && r.cfg.AcceptHS256 && len(legacySecret) > 0 {
+			if token.Method != jwt.SigningMethodHS256 {
+				return nil, fmt.Errorf("%w: %s token without kid", ErrUnsupportedAlgorithm, token.Method.Alg())
+			}
+			return legacySecret, nil
+		}
+		return r.Keyfunc(token)
+	}
+}
+
+// ValidMethods — алгоритмы, которые принимает VerificationKeyfunc.
+func (r *KeyRing) ValidMethods() []string {
+	methods := []string{signingKey.AlgEdDSA, signingKey.AlgRS256}
+	if r.cfg.AcceptHS256 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	return methods
+}
+
+// JWKS возвращает открытые части всех опубликованных ключей.
+func (r *KeyRing) JWKS() JWKS {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	set := JWKS{Keys: make([]JWK, 0, len(r.keys))}
+	for _, k := range r.keys {
+		jwk, err := NewJWK(k.id, k.algorithm, k.private.Public())
+		if err != nil {
+			log.Printf("[jwtkeys.KeyRing] failed to encode key %s: %v", k.id, err)
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
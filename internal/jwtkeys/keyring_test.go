@@ -0,0 +1,142 @@
+package jwtkeys_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"registration-service/internal/jwtkeys"
+	"registration-service/internal/model/signingKey"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newKeyRing(t *testing.T, store jwtkeys.Store, cfg jwtkeys.Config) *jwtkeys.KeyRing {
+	ring, err := jwtkeys.NewKeyRing(store, nil, cfg)
+	require.NoError(t, err)
+	require.NoError(t, ring.Load(context.Background()))
+	return ring
+}
+
+func claims(subject string) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{Subject: subject, ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}
+}
+
+func parse(ring *jwtkeys.KeyRing, token string) (*jwt.RegisteredClaims, error) {
+	parsed := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(token, parsed, ring.VerificationKeyfunc([]byte("legacy-secret")),
+		jwt.WithValidMethods(ring.ValidMethods()))
+	return parsed, err
+}
+
+func TestKeyRing_SignAndVerify(t *testing.T) {
+	for _, alg := range []string{signingKey.AlgEdDSA, signingKey.AlgRS256} {
+		t.Run(alg, func(t *testing.T) {
+			ring := newKeyRing(t, jwtkeys.NewMemoryStore(), jwtkeys.Config{Algorithm: alg, RotationInterval: time.Hour, Overlap: time.Hour})
+
+			token, err := ring.Sign(claims("42"))
+			require.NoError(t, err)
+			parsed, err := parse(ring, token)
+			require.NoError(t, err)
+			assert.Equal(t, "42", parsed.Subject)
+
+			unverified, _, err := jwt.NewParser().ParseUnverified(token, &jwt.RegisteredClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, alg, unverified.Header["alg"])
+			require.Len(t, ring.JWKS().Keys, 1)
+			assert.Equal(t, ring.JWKS().Keys[0].KeyID, unverified.Header["kid"])
+		})
+	}
+}
+
+func TestKeyRing_RotationKeepsPreviousKeyDuringOverlap(t *testing.T) {
+	ctx := context.Background()
+	store := jwtkeys.NewMemoryStore()
+	old := newKeyRing(t, store, jwtkeys.Config{Algorithm: signingKey.AlgEdDSA, RotationInterval: time.Hour, Overlap: time.Hour})
+	oldToken, err := old.Sign(claims("1"))
+	require.NoError(t, err)
+
+	// второй экземпляр с нулевым интервалом ротирует ключ сразу
+	rotating := newKeyRing(t, store, jwtkeys.Config{Algorithm: signingKey.AlgEdDSA, RotationInterval: 0, Overlap: time.Hour})
+	newToken, err := rotating.Sign(claims("2"))
+	require.NoError(t, err)
+	assert.Len(t, rotating.JWKS().Keys, 2, "previous key is still published")
+
+	_, err = parse(rotating, oldToken)
+	assert.NoError(t, err, "tokens signed before rotation stay valid")
+
+	_, err = parse(old, newToken)
+	assert.ErrorIs(t, err, jwtkeys.ErrUnknownKey, "instance has not reloaded keys yet")
+	require.NoError(t, old.Load(ctx))
+	_, err = parse(old, newToken)
+	assert.NoError(t, err)
+}
+
+func TestKeyRing_DropsKeyAfterOverlap(t *testing.T) {
+	ctx := context.Background()
+	store := jwtkeys.NewMemoryStore()
+	ring := newKeyRing(t, store, jwtkeys.Config{Algorithm: signingKey.AlgEdDSA, RotationInterval: 0, Overlap: 0})
+	token, err := ring.Sign(claims("1"))
+	require.NoError(t, err)
+
+	// нулевой интервал: следующая загрузка ротирует ключ, нулевое перекрытие сразу его снимает
+	require.NoError(t, ring.Load(ctx))
+	_, err = parse(ring, token)
+	assert.ErrorIs(t, err, jwtkeys.ErrUnknownKey)
+	deleted, err := store.DeleteRetiredKeys(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, deleted)
+}
+
+func TestKeyRing_RejectsAlgorithmConfusion(t *testing.T) {
+	ring := newKeyRing(t, jwtkeys.NewMemoryStore(), jwtkeys.Config{Algorithm: signingKey.AlgEdDSA, RotationInterval: time.Hour, AcceptHS256: true})
+	jwk := ring.JWKS().Keys[0]
+	public, err := jwk.PublicKey()
+	require.NoError(t, err)
+
+	// HS256 с открытым ключом в роли секрета и чужим kid
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims("1"))
+	forged.Header["kid"] = jwk.KeyID
+	token, err := forged.SignedString([]byte(public.(ed25519.PublicKey)))
+	require.NoError(t, err)
+	_, err = parse(ring, token)
+	assert.Error(t, err)
+
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims("7")).SignedString([]byte("legacy-secret"))
+	require.NoError(t, err)
+	parsed, err := parse(ring, legacy)
+	require.NoError(t, err)
+	assert.Equal(t, "7", parsed.Subject)
+
+	strict := newKeyRing(t, jwtkeys.NewMemoryStore(), jwtkeys.Config{Algorithm: signingKey.AlgEdDSA, RotationInterval: time.Hour})
+	_, err = parse(strict, legacy)
+	assert.Error(t, err, "HS256 tokens are rejected once AcceptHS256 is off")
+}
+
+func TestKeyRing_ServeJWKS(t *testing.T) {
+	ring := newKeyRing(t, jwtkeys.NewMemoryStore(), jwtkeys.Config{Algorithm: signingKey.AlgRS256, RotationInterval: time.Hour})
+	token, err := ring.Sign(claims("5"))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ring.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, jwtkeys.JWKSPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Cache-Control"), "max-age=")
+
+	var set jwtkeys.JWKS
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &set))
+	require.Len(t, set.Keys, 1)
+	assert.Equal(t, "RSA", set.Keys[0].KeyType)
+
+	// токен проверяется только по опубликованному JWKS, без доступа к связке
+	public, err := set.Keys[0].PublicKey()
+	require.NoError(t, err)
+	_, err = jwt.Parse(token, func(*jwt.Token) (interface{}, error) { return public, nil },
+		jwt.WithValidMethods([]string{signingKey.AlgRS256}))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,71 @@
+package jwtkeys
+
+import (
+	"context"
+	"registration-service/internal/model/signingKey"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore держит ключи в памяти процесса. Предназначен для тестов и запуска одного экземпляра
+// без базы; после перезапуска выданные токены перестают проверяться.
+type MemoryStore struct {
+	mu   sync.Mutex
+	keys []*signingKey.SigningKey
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) ListKeys(ctx context.Context) ([]*signingKey.SigningKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var keys []*signingKey.SigningKey
+	for _, k := range s.keys {
+		if k.RetireAt == nil || k.RetireAt.After(now) {
+			copied := *k
+			keys = append(keys, &copied)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+func (s *MemoryStore) RotateKey(ctx context.Context, key *signingKey.SigningKey, staleBefore time.Time, overlap time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range s.keys {
+		if k.RetireAt == nil && k.CreatedAt.After(staleBefore) && k.Algorithm == key.Algorithm {
+			return false, nil
+		}
+	}
+	retireAt := time.Now().Add(overlap)
+	for _, k := range s.keys {
+		if k.RetireAt == nil {
+			k.RetireAt = &retireAt
+		}
+	}
+	copied := *key
+	s.keys = append(s.keys, &copied)
+	return true, nil
+}
+
+func (s *MemoryStore) DeleteRetiredKeys(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	kept := s.keys[:0]
+	var deleted int64
+	for _, k := range s.keys {
+		if k.RetireAt != nil && !k.RetireAt.After(now) {
+			deleted++
+			continue
+		}
+		kept = append(kept, k)
+	}
+	s.keys = kept
+	return deleted, nil
+}
@@ -0,0 +1,39 @@
+package mail_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"registration-service/internal/mail"
+)
+
+func TestFileMailer_WritesMessage(t *testing.T) {
+	dir := t.TempDir()
+	mailer, err := mail.New(mail.Config{Driver: mail.FileDriver, FileDir: dir, From: "no-reply@example.com"})
+	require.NoError(t, err)
+
+	require.NoError(t, mailer.Send(context.Background(), mail.Message{
+		To: "user@example.com", Subject: "Подтвердите адрес", Body: "token: abc",
+	}))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	content := string(data)
+	assert.Contains(t, content, "From: no-reply@example.com\r\n")
+	assert.Contains(t, content, "To: user@example.com\r\n")
+	assert.Contains(t, content, "Subject: =?utf-8?q?")
+	assert.True(t, strings.HasSuffix(content, "\r\n\r\ntoken: abc\r\n"))
+}
+
+func TestNew_UnknownDriver(t *testing.T) {
+	_, err := mail.New(mail.Config{Driver: "carrier-pigeon"})
+	assert.Error(t, err)
+}
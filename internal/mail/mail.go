@@ -0,0 +1,66 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"time"
+)
+
+const (
+	SMTPDriver = "smtp"
+	FileDriver = "file"
+	LogDriver  = "log"
+)
+
+type Config struct {
+	// Driver — smtp, file (письма складываются в FileDir) или log (письма пишутся в лог)
+	Driver       string `env:"MAIL_DRIVER" env-default:"log"`
+	From         string `env:"MAIL_FROM" env-default:"no-reply@localhost"`
+	SMTPHost     string `env:"SMTP_HOST" env-default:"localhost"`
+	SMTPPort     string `env:"SMTP_PORT" env-default:"587"`
+	SMTPUsername string `env:"SMTP_USERNAME"`
+	SMTPPassword string `env:"SMTP_PASSWORD"`
+	FileDir      string `env:"MAIL_FILE_DIR" env-default:"./data/mail"`
+}
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer отправляет служебные письма (подтверждение адреса, сброс пароля).
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New создаёт Mailer по конфигурации.
+func New(cfg Config) (Mailer, error) {
+	switch cfg.Driver {
+	case SMTPDriver:
+		return NewSMTPMailer(cfg), nil
+	case FileDriver:
+		return NewFileMailer(cfg.FileDir, cfg.From)
+	case LogDriver, "":
+		return NewLogMailer(cfg.From), nil
+	}
+	return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+}
+
+// format собирает письмо в формате RFC 5322 с телом text/plain в UTF-8.
+func format(from string, msg Message, date time.Time) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(msg.Body)
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
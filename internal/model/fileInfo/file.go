@@ -6,11 +6,19 @@ import (
 )
 
 type File struct {
-	ID             uuid.UUID `json:"id"`
-	OwnerID        uint32    `json:"owner_id"`
-	Name           string    `json:"name"`
-	CurrentVersion int       `json:"current_version"`
-	CreatedAt      time.Time `json:"created_at"`
+	ID             uuid.UUID         `json:"id"`
+	OwnerID        uint32            `json:"owner_id"`
+	Name           string            `json:"name"`
+	CurrentVersion int               `json:"current_version"`
+	CreatedAt      time.Time         `json:"created_at"`
+	Tags           []string          `json:"tags"`
+	Properties     map[string]string `json:"properties"`
+}
+
+// FileFilter — условия ListFiles: файл должен иметь все теги и все пары ключ/значение.
+type FileFilter struct {
+	Tags       []string
+	Properties map[string]string
 }
 
 type FileVersion struct {
@@ -19,11 +27,161 @@ type FileVersion struct {
 	VersionNumber uint32    `json:"version_number"`
 	StorageKey    string    `json:"storage_key"`
 	Size          int64     `json:"size"`
+	ContentType   string    `json:"content_type"`
+	CreatedAt     time.Time `json:"created_at"`
+	// Size — логический размер, StoredSize — сколько объект занимает в хранилище после сжатия и шифрования
+	StoredSize int64  `json:"stored_size"`
+	Codec      string `json:"codec"`
+	ScanStatus string `json:"scan_status"`
+	// Status — состояние загрузки: StatusPending, пока объект не подтверждён в хранилище
+	Status string `json:"status"`
+	// Tier — в каком хранилище лежит объект версии: TierHot или TierCold
+	Tier string `json:"tier"`
+	// ReplicationStatus — состояние копии во вторичном хранилище; ReplicationNone, если репликация выключена
+	ReplicationStatus string `json:"replication_status"`
+	// ScanResult — название угрозы для заражённых версий
+	ScanResult string `json:"scan_result"`
+	// KeyID и WrappedKey пусты у версий, сохранённых без шифрования
+	KeyID      string `json:"-"`
+	WrappedKey []byte `json:"-"`
+}
+
+// Состояния проверки версии сканером. Текущей может стать только версия в ScanClean.
+const (
+	ScanPending  = "pending_scan"
+	ScanClean    = "clean"
+	ScanInfected = "infected"
+)
+
+// Состояния загрузки файла и версии. Строки создаются в StatusPending и переводятся в StatusActive
+// после того, как объект подтверждён в хранилище; в списках и поиске видны только активные файлы.
+const (
+	StatusPending = "pending"
+	StatusActive  = "active"
+)
+
+// Уровни хранения. Новые версии пишутся в TierHot; политика хранения переносит в TierCold
+// нетекущие версии и давно не открывавшиеся файлы. Производные объекты (превью) всегда в TierHot.
+const (
+	TierHot  = "hot"
+	TierCold = "cold"
+)
+
+// Состояния репликации версии. Версия ставится в очередь, когда сканер признаёт её чистой.
+const (
+	ReplicationNone       = "none"
+	ReplicationPending    = "pending"
+	ReplicationReplicated = "replicated"
+	ReplicationFailed     = "failed"
+)
+
+// Операции и состояния задач очереди replication_jobs.
+const (
+	ReplicationPut    = "put"
+	ReplicationDelete = "delete"
+
+	ReplicationJobPending = "pending"
+	ReplicationJobDone    = "done"
+	ReplicationJobDead    = "dead"
+)
+
+// ReplicationJob — строка очереди replication_jobs. VersionID пуст у задач удаления:
+// к их выполнению строки версии уже нет.
+type ReplicationJob struct {
+	ID            int64     `json:"id"`
+	VersionID     uint32    `json:"version_id"`
+	StorageKey    string    `json:"storage_key"`
+	Operation     string    `json:"operation"`
+	Status        string    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     string    `json:"last_error"`
 	CreatedAt     time.Time `json:"created_at"`
 }
 
+// ObjectReference — ключ объекта, на который ссылается строка file_versions; используется сверкой с хранилищем.
+type ObjectReference struct {
+	VersionID  uint32    `json:"version_id"`
+	FileID     uuid.UUID `json:"file_id"`
+	StorageKey string    `json:"storage_key"`
+	CreatedAt  time.Time `json:"created_at"`
+	// Missing — строка уже помечена как ссылающаяся на отсутствующий объект
+	Missing bool   `json:"missing"`
+	Tier    string `json:"tier"`
+}
+
 type FilePermission struct {
 	FileID     uuid.UUID `json:"file_id"`
 	UserID     int32     `json:"user_id"`
 	Permission int       `json:"permission"`
 }
+
+type SearchResult struct {
+	File    *File   `json:"file"`
+	Rank    float32 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+const (
+	ArchiveEntryCreated = "created"
+	ArchiveEntrySkipped = "skipped"
+	ArchiveEntryFailed  = "failed"
+)
+
+// ArchiveEntryResult — строка отчёта UploadArchive по одной записи архива.
+type ArchiveEntryResult struct {
+	Path   string    `json:"path"`
+	FileID uuid.UUID `json:"file_id"`
+	Status string    `json:"status"`
+	Error  string    `json:"error"`
+}
+
+// Состояния задачи выгрузки данных пользователя.
+const (
+	ExportPending = "pending"
+	ExportRunning = "running"
+	ExportReady   = "ready"
+	ExportFailed  = "failed"
+)
+
+// Export — строка таблицы exports. Архив хранится как объект версии: с тем же кодеком и
+// обёрнутым ключом шифрования, поэтому читается через versionStore.
+type Export struct {
+	ID          uuid.UUID  `json:"id"`
+	OwnerID     uint32     `json:"owner_id"`
+	Status      string     `json:"status"`
+	StorageKey  string     `json:"storage_key"`
+	Size        int64      `json:"size"`
+	Error       string     `json:"error"`
+	Codec       string     `json:"codec"`
+	KeyID       string     `json:"-"`
+	WrappedKey  []byte     `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// Object описывает объект архива так, как его понимает versionStore.
+func (e *Export) Object() *FileVersion {
+	return &FileVersion{
+		StorageKey:  e.StorageKey,
+		Size:        e.Size,
+		ContentType: "application/zip",
+		Codec:       e.Codec,
+		KeyID:       e.KeyID,
+		WrappedKey:  e.WrappedKey,
+		Tier:        TierHot,
+		Status:      StatusActive,
+	}
+}
+
+// ImportResult — строка отчёта ImportArchive по одному файлу из манифеста.
+type ImportResult struct {
+	SourceFileID string    `json:"source_file_id"`
+	Name         string    `json:"name"`
+	FileID       uuid.UUID `json:"file_id"`
+	Versions     int       `json:"versions"`
+	Status       string    `json:"status"`
+	Error        string    `json:"error"`
+}
@@ -0,0 +1,15 @@
+package session
+
+import "time"
+
+// Session — один вход пользователя: своя пара токенов на каждом устройстве.
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     uint32    `json:"user_id"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	// LastUsedAt — время последнего обновления токенов сессии
+	LastUsedAt time.Time `json:"last_used_at"`
+}
@@ -0,0 +1,20 @@
+package signingKey
+
+import "time"
+
+const (
+	AlgEdDSA = "EdDSA"
+	AlgRS256 = "RS256"
+)
+
+// SigningKey — ключ подписи access-токенов. PrivateKey хранится в PKCS#8 DER; если задан
+// WrapKeyID, он зашифрован мастер-ключом с этим идентификатором.
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey []byte
+	WrapKeyID  string
+	CreatedAt  time.Time
+	// RetireAt — когда ключ перестаёт публиковаться в JWKS; nil у действующего ключа
+	RetireAt *time.Time
+}
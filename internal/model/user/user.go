@@ -1,8 +1,13 @@
 package user
 
 type User struct {
-	ID       uint64 `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	ID            uint64 `json:"id"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	Password      string `json:"password"`
+	EmailVerified bool   `json:"email_verified"`
+	DisplayName   string `json:"display_name"`
+	AvatarURL     string `json:"avatar_url"`
+	TOTPEnabled   bool   `json:"totp_enabled"`
+	IsAdmin       bool   `json:"is_admin"`
 }
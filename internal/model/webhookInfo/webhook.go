@@ -0,0 +1,70 @@
+package webhookInfo
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventFileUploaded       = "file.uploaded"
+	EventFileShared         = "file.shared"
+	EventFileDeleted        = "file.deleted"
+	EventFileVersionCreated = "file.version_created"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+var EventTypes = []string{
+	EventFileUploaded,
+	EventFileShared,
+	EventFileDeleted,
+	EventFileVersionCreated,
+}
+
+type Webhook struct {
+	ID         uuid.UUID `json:"id"`
+	OwnerID    uint32    `json:"owner_id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"-"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Delivery — строка outbox-таблицы webhook_deliveries.
+type Delivery struct {
+	ID            uuid.UUID `json:"id"`
+	WebhookID     uuid.UUID `json:"webhook_id"`
+	EventType     string    `json:"event_type"`
+	Payload       []byte    `json:"payload"`
+	Status        string    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     string    `json:"last_error"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// Заполняются при выборке на отправку
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+// Event — тело запроса, которое получает подписчик.
+type Event struct {
+	ID         uuid.UUID   `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+func IsKnownEvent(eventType string) bool {
+	for _, e := range EventTypes {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"registration-service/internal/model/fileInfo"
+	"registration-service/internal/storage"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	// Interval — период фоновой сверки в файловом сервисе; 0 отключает её
+	Interval time.Duration `env:"RECONCILE_INTERVAL" env-default:"0"`
+	// Repair разрешает фоновой сверке удалять объекты-сироты и помечать строки без объектов
+	Repair bool `env:"RECONCILE_REPAIR" env-default:"false"`
+	// GracePeriod защищает свежие объекты и строки: загрузка может быть ещё в процессе
+	GracePeriod time.Duration `env:"RECONCILE_GRACE_PERIOD" env-default:"24h"`
+}
+
+// Repository — часть fileRepo.FileRepository, которая нужна сверке.
+type Repository interface {
+	ForEachObjectReference(ctx context.Context, fn func(fileInfo.ObjectReference) error) error
+	SetObjectMissing(ctx context.Context, versionID uint32, missing bool) error
+}
+
+type Report struct {
+	ReferencedKeys int
+	ScannedObjects int
+	// OrphanObjects — объекты, на которые не ссылается ни одна версия
+	OrphanObjects []storage.ObjectInfo
+	// MissingObjects — версии, объектов которых нет в хранилище
+	MissingObjects []fileInfo.ObjectReference
+	DeletedObjects int
+	FlaggedRows    int
+	RestoredRows   int
+}
+
+// Reconciler сравнивает file_versions.storage_key с содержимым горячего хранилища; версии
+// холодного уровня в нём не ищутся. Сначала читаются ссылки из БД, затем листинг хранилища:
+// объект, загруженный после снимка ссылок, моложе GracePeriod и поэтому не будет удалён.
+type Reconciler struct {
+	repo    Repository
+	objects storage.ObjectStore
+	cfg     Config
+}
+
+func New(repo Repository, objects storage.ObjectStore, cfg Config) *Reconciler {
+	return &Reconciler{repo: repo, objects: objects, cfg: cfg}
+}
+
+// Run периодически выполняет сверку с настройкой Repair из конфигурации.
+func (r *Reconciler) Run(ctx context.Context) {
+	if r.cfg.Interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := r.Reconcile(ctx, r.cfg.Repair)
+			if err != nil {
+				log.Printf("[reconcile.Reconciler] reconciliation failed: %v", err)
+				continue
+			}
+			log.Printf("[reconcile.Reconciler] referenced=%d scanned=%d orphans=%d missing=%d deleted=%d flagged=%d restored=%d",
+				report.ReferencedKeys, report.ScannedObjects, len(report.OrphanObjects), len(report.MissingObjects),
+				report.DeletedObjects, report.FlaggedRows, report.RestoredRows)
+		}
+	}
+}
+
+// Reconcile находит расхождения в обе стороны. В режиме repair удаляет объекты-сироты старше
+// GracePeriod и помечает строки старше GracePeriod, объекты которых отсутствуют; с найденных
+// снова строк пометка снимается.
+func (r *Reconciler) Reconcile(ctx context.Context, repair bool) (*Report, error) {
+	refs := make(map[string]*fileInfo.ObjectReference)
+	err := r.repo.ForEachObjectReference(ctx, func(ref fileInfo.ObjectReference) error {
+		refs[ref.StorageKey] = &ref
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load storage keys: %w", err)
+	}
+
+	report := &Report{ReferencedKeys: len(refs)}
+	cutoff := time.Now().Add(-r.cfg.GracePeriod)
+	seen := make(map[string]bool, len(refs))
+
+	err = r.objects.List(ctx, "", func(info storage.ObjectInfo) error {
+		report.ScannedObjects++
+		// объект версии, перенесённой в холодный уровень, в горячем хранилище — остаток переноса
+		if ref, ok := refs[info.Key]; ok && ref.Tier != fileInfo.TierCold {
+			seen[info.Key] = true
+			return nil
+		}
+		if isDerived(info.Key, refs) {
+			return nil
+		}
+		report.OrphanObjects = append(report.OrphanObjects, info)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+
+	for key, ref := range refs {
+		if ref.Tier == fileInfo.TierCold {
+			continue
+		}
+		if seen[key] {
+			if ref.Missing && repair {
+				if err := r.repo.SetObjectMissing(ctx, ref.VersionID, false); err != nil {
+					return report, fmt.Errorf("failed to clear missing flag of %s: %w", key, err)
+				}
+				report.RestoredRows++
+			}
+			continue
+		}
+		report.MissingObjects = append(report.MissingObjects, *ref)
+		// архив выгрузки не помечается: у него нет строки версии
+		if repair && ref.VersionID != 0 && !ref.Missing && ref.CreatedAt.Before(cutoff) {
+			if err := r.repo.SetObjectMissing(ctx, ref.VersionID, true); err != nil {
+				return report, fmt.Errorf("failed to flag %s: %w", key, err)
+			}
+			report.FlaggedRows++
+		}
+	}
+
+	if repair {
+		for _, orphan := range report.OrphanObjects {
+			if !orphan.LastModified.Before(cutoff) {
+				continue
+			}
+			if err := r.objects.Delete(ctx, orphan.Key); err != nil {
+				log.Printf("[reconcile.Reconciler] failed to delete orphan %s: %v", orphan.Key, err)
+				continue
+			}
+			report.DeletedObjects++
+		}
+	}
+	return report, nil
+}
+
+// isDerived сообщает, что объект лежит под ключом существующей версии (например, её превью).
+func isDerived(key string, refs map[string]*fileInfo.ObjectReference) bool {
+	for i := strings.LastIndex(key, "/"); i > 0; i = strings.LastIndex(key[:i], "/") {
+		if _, ok := refs[key[:i]]; ok {
+			return true
+		}
+	}
+	return false
+}
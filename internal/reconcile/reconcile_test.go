@@ -0,0 +1,129 @@
+package reconcile_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"registration-service/internal/model/fileInfo"
+	"registration-service/internal/reconcile"
+	"registration-service/internal/storage"
+	"registration-service/internal/storage/memory"
+)
+
+type fakeRepo struct {
+	refs []fileInfo.ObjectReference
+}
+
+func (r *fakeRepo) ForEachObjectReference(ctx context.Context, fn func(fileInfo.ObjectReference) error) error {
+	for _, ref := range r.refs {
+		if err := fn(ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepo) SetObjectMissing(ctx context.Context, versionID uint32, missing bool) error {
+	for i := range r.refs {
+		if r.refs[i].VersionID == versionID {
+			r.refs[i].Missing = missing
+		}
+	}
+	return nil
+}
+
+func put(t *testing.T, objects storage.ObjectStore, key string) {
+	require.NoError(t, objects.Put(context.Background(), key, strings.NewReader(key), int64(len(key)), ""))
+}
+
+func keys(infos []storage.ObjectInfo) []string {
+	var out []string
+	for _, info := range infos {
+		out = append(out, info.Key)
+	}
+	return out
+}
+
+func TestReconcile_ReportsBothDirections(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+	repo := &fakeRepo{refs: []fileInfo.ObjectReference{
+		{VersionID: 1, StorageKey: "a/v1", CreatedAt: old},
+		{VersionID: 2, StorageKey: "quarantine/a/v2", CreatedAt: old},
+		{VersionID: 3, StorageKey: "b/v1", CreatedAt: old},
+		{VersionID: 4, StorageKey: "c/v1", CreatedAt: time.Now()},
+	}}
+	objects := memory.New()
+	for _, key := range []string{"a/v1", "a/v1/thumb-64", "quarantine/a/v2", "orphan/v1", "orphan/v1/thumb-64"} {
+		put(t, objects, key)
+	}
+	r := reconcile.New(repo, objects, reconcile.Config{GracePeriod: time.Hour})
+
+	report, err := r.Reconcile(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.ReferencedKeys)
+	assert.Equal(t, 5, report.ScannedObjects)
+	assert.ElementsMatch(t, []string{"orphan/v1", "orphan/v1/thumb-64"}, keys(report.OrphanObjects))
+	require.Len(t, report.MissingObjects, 2)
+	assert.ElementsMatch(t, []string{"b/v1", "c/v1"},
+		[]string{report.MissingObjects[0].StorageKey, report.MissingObjects[1].StorageKey})
+
+	// без repair ничего не меняется
+	assert.Zero(t, report.DeletedObjects)
+	assert.Zero(t, report.FlaggedRows)
+	_, err = objects.Stat(context.Background(), "orphan/v1")
+	assert.NoError(t, err)
+}
+
+func TestReconcile_RepairRespectsGracePeriod(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+	repo := &fakeRepo{refs: []fileInfo.ObjectReference{
+		{VersionID: 1, StorageKey: "gone/v1", CreatedAt: old},
+		{VersionID: 2, StorageKey: "uploading/v1", CreatedAt: time.Now()},
+		{VersionID: 3, StorageKey: "back/v1", CreatedAt: old, Missing: true},
+	}}
+	objects := memory.New()
+	put(t, objects, "back/v1")
+	put(t, objects, "fresh-orphan/v1")
+	ctx := context.Background()
+
+	report, err := reconcile.New(repo, objects, reconcile.Config{GracePeriod: time.Hour}).Reconcile(ctx, true)
+	require.NoError(t, err)
+	assert.Zero(t, report.DeletedObjects, "fresh orphan is protected by the grace period")
+	assert.Equal(t, 1, report.FlaggedRows)
+	assert.Equal(t, 1, report.RestoredRows)
+	assert.True(t, repo.refs[0].Missing)
+	assert.False(t, repo.refs[1].Missing, "fresh row may still be uploading")
+	assert.False(t, repo.refs[2].Missing)
+
+	// с нулевым grace period только что созданный объект-сирота уже можно удалять
+	report, err = reconcile.New(repo, objects, reconcile.Config{GracePeriod: 0}).Reconcile(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.DeletedObjects)
+	_, err = objects.Stat(ctx, "fresh-orphan/v1")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	_, err = objects.Stat(ctx, "back/v1")
+	assert.NoError(t, err)
+}
+
+func TestReconcile_SkipsColdTier(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+	repo := &fakeRepo{refs: []fileInfo.ObjectReference{
+		{VersionID: 1, StorageKey: "a/v1", CreatedAt: old, Tier: fileInfo.TierCold},
+		{VersionID: 2, StorageKey: "b/v1", CreatedAt: old, Tier: fileInfo.TierCold},
+	}}
+	objects := memory.New()
+	// остаток переноса a/v1 и превью холодной версии в горячем хранилище
+	for _, key := range []string{"a/v1", "a/v1/thumb-64"} {
+		put(t, objects, key)
+	}
+	r := reconcile.New(repo, objects, reconcile.Config{GracePeriod: time.Hour})
+
+	report, err := r.Reconcile(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a/v1"}, keys(report.OrphanObjects))
+	assert.Empty(t, report.MissingObjects)
+}
@@ -0,0 +1,137 @@
+package replication
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"registration-service/internal/model/fileInfo"
+	"registration-service/internal/storage"
+)
+
+// Причины расхождения копии с оригиналом.
+const (
+	MismatchMissing = "missing"
+	MismatchSize    = "size"
+	MismatchHash    = "hash"
+)
+
+type Mismatch struct {
+	VersionID   uint32
+	StorageKey  string
+	Reason      string
+	PrimarySize int64
+	ReplicaSize int64
+}
+
+type VerifyReport struct {
+	Checked    int
+	Mismatches []Mismatch
+	// Requeued — сколько версий с расхождением снова поставлено в очередь копирования
+	Requeued int
+}
+
+// VerifyRepository — часть fileRepo.FileRepository, которая нужна проверке копий.
+type VerifyRepository interface {
+	ForEachReplicatedVersion(ctx context.Context, fn func(*fileInfo.FileVersion) error) error
+	RequeueReplication(ctx context.Context, versionID uint32) error
+}
+
+// Verifier сравнивает объекты реплицированных версий с их копиями по размеру и, при
+// необходимости, по SHA-256 содержимого.
+type Verifier struct {
+	repo    VerifyRepository
+	source  Source
+	replica storage.ObjectStore
+}
+
+func NewVerifier(repo VerifyRepository, source Source, replica storage.ObjectStore) *Verifier {
+	return &Verifier{repo: repo, source: source, replica: replica}
+}
+
+// Verify проверяет все реплицированные версии. compareHash включает сравнение содержимого
+// (читает оба объекта целиком); repair ставит версии с расхождением в очередь заново.
+func (v *Verifier) Verify(ctx context.Context, compareHash, repair bool) (*VerifyReport, error) {
+	report := &VerifyReport{}
+	err := v.repo.ForEachReplicatedVersion(ctx, func(version *fileInfo.FileVersion) error {
+		report.Checked++
+		mismatch, err := v.check(ctx, version, compareHash)
+		if err != nil {
+			return fmt.Errorf("failed to verify %s: %w", version.StorageKey, err)
+		}
+		if mismatch != nil {
+			report.Mismatches = append(report.Mismatches, *mismatch)
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	if repair {
+		for _, mismatch := range report.Mismatches {
+			if err := v.repo.RequeueReplication(ctx, mismatch.VersionID); err != nil {
+				return report, fmt.Errorf("failed to requeue %s: %w", mismatch.StorageKey, err)
+			}
+			report.Requeued++
+		}
+	}
+	return report, nil
+}
+
+func (v *Verifier) check(ctx context.Context, version *fileInfo.FileVersion, compareHash bool) (*Mismatch, error) {
+	mismatch := &Mismatch{VersionID: version.ID, StorageKey: version.StorageKey}
+	replicaInfo, err := v.replica.Stat(ctx, version.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		mismatch.Reason = MismatchMissing
+		return mismatch, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	mismatch.ReplicaSize = replicaInfo.Size
+
+	primary, primaryInfo, err := v.source.OpenStored(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	defer primary.Close()
+	mismatch.PrimarySize = primaryInfo.Size
+	if primaryInfo.Size != replicaInfo.Size {
+		mismatch.Reason = MismatchSize
+		return mismatch, nil
+	}
+	if !compareHash {
+		return nil, nil
+	}
+
+	primaryHash, err := hashOf(primary)
+	if err != nil {
+		return nil, err
+	}
+	replica, err := v.replica.Get(ctx, version.StorageKey)
+	if err != nil {
+		return nil, err
+	}
+	defer replica.Close()
+	replicaHash, err := hashOf(replica)
+	if err != nil {
+		return nil, err
+	}
+	if primaryHash != replicaHash {
+		mismatch.Reason = MismatchHash
+		return mismatch, nil
+	}
+	return nil, nil
+}
+
+func hashOf(r io.Reader) ([sha256.Size]byte, error) {
+	h := sha256.New()
+	var sum [sha256.Size]byte
+	if _, err := io.Copy(h, r); err != nil {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
@@ -0,0 +1,54 @@
+package replication_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"registration-service/internal/model/fileInfo"
+	"registration-service/internal/replication"
+	"registration-service/internal/storage/memory"
+)
+
+func TestVerifier_FindsMismatches(t *testing.T) {
+	ctx := context.Background()
+	repo, store, primary := setup(t)
+	for _, id := range []uint32{2, 3, 4} {
+		key := fmt.Sprintf("f/v%d", id)
+		require.NoError(t, primary.Put(ctx, key, strings.NewReader("payload"), 7, ""))
+		repo.versions[id] = &fileInfo.FileVersion{ID: id, StorageKey: key, ReplicationStatus: fileInfo.ReplicationReplicated}
+	}
+	repo.versions[1].ReplicationStatus = fileInfo.ReplicationReplicated
+
+	replica := memory.New()
+	stored, _, err := store.OpenStored(ctx, repo.versions[1])
+	require.NoError(t, err)
+	data, _ := io.ReadAll(stored)
+	stored.Close()
+	require.NoError(t, replica.Put(ctx, "f/v1", bytes.NewReader(data), int64(len(data)), ""))
+	require.NoError(t, replica.Put(ctx, "f/v2", strings.NewReader("payload"), 7, ""))
+	require.NoError(t, replica.Put(ctx, "f/v3", strings.NewReader("payloaX"), 7, ""))
+	// f/v4 в копии нет
+
+	verifier := replication.NewVerifier(repo, store, replica)
+	report, err := verifier.Verify(ctx, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Checked)
+	require.Len(t, report.Mismatches, 1)
+	assert.Equal(t, replication.MismatchMissing, report.Mismatches[0].Reason)
+
+	report, err = verifier.Verify(ctx, true, true)
+	require.NoError(t, err)
+	reasons := map[string]string{}
+	for _, m := range report.Mismatches {
+		reasons[m.StorageKey] = m.Reason
+	}
+	assert.Equal(t, map[string]string{"f/v3": replication.MismatchHash, "f/v4": replication.MismatchMissing}, reasons)
+	assert.Equal(t, 2, report.Requeued)
+	assert.ElementsMatch(t, []uint32{3, 4}, repo.requeued)
+}
@@ -0,0 +1,176 @@
+package replication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"registration-service/internal/MinIO"
+	"registration-service/internal/model/fileInfo"
+	"registration-service/internal/storage"
+	"registration-service/internal/storage/driver"
+	"registration-service/internal/webhook"
+	"time"
+)
+
+type Config struct {
+	Enabled bool `env:"REPLICATION_ENABLED" env-default:"false"`
+	// Storage и MinIO описывают вторичное хранилище: REPLICATION_STORAGE_DRIVER, REPLICATION_MINIO_ENDPOINT и т.д.
+	Storage      driver.Config `env-prefix:"REPLICATION_"`
+	MinIO        MinIO.Config  `env-prefix:"REPLICATION_"`
+	PollInterval time.Duration `env:"REPLICATION_POLL_INTERVAL" env-default:"5s"`
+	BatchSize    int           `env:"REPLICATION_BATCH_SIZE" env-default:"20"`
+	MaxAttempts  int           `env:"REPLICATION_MAX_ATTEMPTS" env-default:"10"`
+	BaseBackoff  time.Duration `env:"REPLICATION_BASE_BACKOFF" env-default:"10s"`
+	MaxBackoff   time.Duration `env:"REPLICATION_MAX_BACKOFF" env-default:"1h"`
+	// Lease — на сколько задача откладывается при выборке; должен покрывать копирование самого большого объекта
+	Lease time.Duration `env:"REPLICATION_LEASE" env-default:"30m"`
+}
+
+// Validate проверяет, что вторичное хранилище не совпадает с основным: копия в том же месте
+// перезаписала бы оригиналы.
+func (c Config) Validate(primary driver.Config, primaryMinIO MinIO.Config) error {
+	replicaDriver, primaryDriver := c.Storage.Driver, primary.Driver
+	if replicaDriver == "" {
+		replicaDriver = driver.MinIODriver
+	}
+	if primaryDriver == "" {
+		primaryDriver = driver.MinIODriver
+	}
+	if replicaDriver != primaryDriver {
+		return nil
+	}
+	switch replicaDriver {
+	case driver.FilesystemDriver:
+		if filepath.Clean(c.Storage.FilesystemRoot) == filepath.Clean(primary.FilesystemRoot) {
+			return fmt.Errorf("replica root %s is the primary storage root; set REPLICATION_STORAGE_FS_ROOT", c.Storage.FilesystemRoot)
+		}
+	case driver.MinIODriver:
+		if c.MinIO.MinioEndpoint == primaryMinIO.MinioEndpoint && c.MinIO.BucketName == primaryMinIO.BucketName {
+			return fmt.Errorf("replica bucket %s/%s is the primary bucket; set REPLICATION_MINIO_ENDPOINT or REPLICATION_MINIO_BUCKET_NAME",
+				c.MinIO.MinioEndpoint, c.MinIO.BucketName)
+		}
+	}
+	return nil
+}
+
+// NewReplica создаёт вторичное хранилище по конфигурации.
+func NewReplica(cfg Config) (storage.ObjectStore, error) {
+	return driver.New(cfg.Storage, cfg.MinIO)
+}
+
+// Repository — часть fileRepo.FileRepository, которая нужна репликации.
+type Repository interface {
+	GetFileVersionByID(ctx context.Context, versionID uint32) (*fileInfo.FileVersion, error)
+	ClaimReplicationJobs(ctx context.Context, limit int, lease time.Duration) ([]*fileInfo.ReplicationJob, error)
+	CompleteReplicationJob(ctx context.Context, job *fileInfo.ReplicationJob, attempts int) error
+	RetryReplicationJob(ctx context.Context, jobID int64, attempts int, nextAttemptAt time.Time, lastError string) error
+	FailReplicationJob(ctx context.Context, job *fileInfo.ReplicationJob, attempts int, lastError string) error
+}
+
+// Source — часть versionStore.VersionStore, которая нужна репликации.
+type Source interface {
+	OpenStored(ctx context.Context, version *fileInfo.FileVersion) (io.ReadCloser, storage.ObjectInfo, error)
+}
+
+// Worker разбирает очередь replication_jobs так же, как webhook.Dispatcher — outbox вебхуков:
+// копирует объекты версий во вторичное хранилище байт в байт (уже сжатыми и зашифрованными)
+// и удаляет копии удалённых файлов. Неудачные задачи повторяются с экспоненциальной задержкой,
+// после MaxAttempts попадают в dead-letter.
+type Worker struct {
+	repo    Repository
+	source  Source
+	replica storage.ObjectStore
+	cfg     Config
+}
+
+func NewWorker(repo Repository, source Source, replica storage.ObjectStore, cfg Config) *Worker {
+	return &Worker{repo: repo, source: source, replica: replica, cfg: cfg}
+}
+
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := w.ProcessOnce(ctx); err != nil {
+			log.Printf("[replication.Worker] replication error: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessOnce обрабатывает одну пачку задач и возвращает их количество.
+func (w *Worker) ProcessOnce(ctx context.Context) (int, error) {
+	jobs, err := w.repo.ClaimReplicationJobs(ctx, w.cfg.BatchSize, w.cfg.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim replication jobs: %w", err)
+	}
+	for _, job := range jobs {
+		w.process(ctx, job)
+	}
+	return len(jobs), nil
+}
+
+func (w *Worker) process(ctx context.Context, job *fileInfo.ReplicationJob) {
+	attempts := job.Attempts + 1
+	jobErr := w.apply(ctx, job)
+	if jobErr == nil {
+		if err := w.repo.CompleteReplicationJob(ctx, job, attempts); err != nil {
+			log.Printf("[replication.Worker] failed to complete job %d: %v", job.ID, err)
+		}
+		return
+	}
+
+	if attempts >= w.cfg.MaxAttempts {
+		log.Printf("[replication.Worker] job %d (%s %s) moved to dead-letter after %d attempts: %v",
+			job.ID, job.Operation, job.StorageKey, attempts, jobErr)
+		if err := w.repo.FailReplicationJob(ctx, job, attempts, jobErr.Error()); err != nil {
+			log.Printf("[replication.Worker] failed to mark job %d dead: %v", job.ID, err)
+		}
+		return
+	}
+
+	next := time.Now().Add(webhook.Backoff(attempts, w.cfg.BaseBackoff, w.cfg.MaxBackoff))
+	if err := w.repo.RetryReplicationJob(ctx, job.ID, attempts, next, jobErr.Error()); err != nil {
+		log.Printf("[replication.Worker] failed to schedule retry for job %d: %v", job.ID, err)
+	}
+}
+
+func (w *Worker) apply(ctx context.Context, job *fileInfo.ReplicationJob) error {
+	switch job.Operation {
+	case fileInfo.ReplicationPut:
+		version, err := w.repo.GetFileVersionByID(ctx, job.VersionID)
+		if err != nil {
+			return fmt.Errorf("failed to get version: %w", err)
+		}
+		if version == nil {
+			// версию удалили; удаление копии стоит в очереди отдельной задачей
+			return nil
+		}
+		return w.copy(ctx, version)
+	case fileInfo.ReplicationDelete:
+		if err := w.replica.Delete(ctx, job.StorageKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown replication operation %q", job.Operation)
+}
+
+func (w *Worker) copy(ctx context.Context, version *fileInfo.FileVersion) error {
+	object, info, err := w.source.OpenStored(ctx, version)
+	if err != nil {
+		return fmt.Errorf("failed to read source object: %w", err)
+	}
+	defer object.Close()
+	if err := w.replica.Put(ctx, version.StorageKey, object, info.Size, info.ContentType); err != nil {
+		return fmt.Errorf("failed to write replica: %w", err)
+	}
+	return nil
+}
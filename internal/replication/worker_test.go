@@ -0,0 +1,140 @@
+package replication_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"registration-service/internal/compression"
+	"registration-service/internal/model/fileInfo"
+	"registration-service/internal/replication"
+	"registration-service/internal/storage"
+	"registration-service/internal/storage/memory"
+	"registration-service/internal/versionStore"
+)
+
+type fakeRepo struct {
+	versions  map[uint32]*fileInfo.FileVersion
+	jobs      []*fileInfo.ReplicationJob
+	completed []int64
+	retried   map[int64]int
+	dead      []int64
+	requeued  []uint32
+}
+
+func (r *fakeRepo) GetFileVersionByID(ctx context.Context, versionID uint32) (*fileInfo.FileVersion, error) {
+	return r.versions[versionID], nil
+}
+
+func (r *fakeRepo) ClaimReplicationJobs(ctx context.Context, limit int, lease time.Duration) ([]*fileInfo.ReplicationJob, error) {
+	jobs := r.jobs
+	r.jobs = nil
+	return jobs, nil
+}
+
+func (r *fakeRepo) CompleteReplicationJob(ctx context.Context, job *fileInfo.ReplicationJob, attempts int) error {
+	r.completed = append(r.completed, job.ID)
+	if v, ok := r.versions[job.VersionID]; ok && job.Operation == fileInfo.ReplicationPut {
+		v.ReplicationStatus = fileInfo.ReplicationReplicated
+	}
+	return nil
+}
+
+func (r *fakeRepo) RetryReplicationJob(ctx context.Context, jobID int64, attempts int, nextAttemptAt time.Time, lastError string) error {
+	r.retried[jobID] = attempts
+	return nil
+}
+
+func (r *fakeRepo) FailReplicationJob(ctx context.Context, job *fileInfo.ReplicationJob, attempts int, lastError string) error {
+	r.dead = append(r.dead, job.ID)
+	return nil
+}
+
+func (r *fakeRepo) ForEachReplicatedVersion(ctx context.Context, fn func(*fileInfo.FileVersion) error) error {
+	for _, v := range r.versions {
+		if v.ReplicationStatus == fileInfo.ReplicationReplicated {
+			if err := fn(v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepo) RequeueReplication(ctx context.Context, versionID uint32) error {
+	r.requeued = append(r.requeued, versionID)
+	return nil
+}
+
+// failingStore отказывает в записи, имитируя недоступное вторичное хранилище.
+type failingStore struct{ storage.ObjectStore }
+
+func (failingStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	return errors.New("replica unavailable")
+}
+
+func setup(t *testing.T) (*fakeRepo, *versionStore.VersionStore, storage.ObjectStore) {
+	ctx := context.Background()
+	primary := memory.New()
+	store := versionStore.New(primary, nil, nil, compression.Config{Codec: compression.CodecZstd, MinSize: 1})
+	version := &fileInfo.FileVersion{ID: 1, StorageKey: "f/v1", Size: 2000, ContentType: "text/plain"}
+	require.NoError(t, store.Put(ctx, version, strings.NewReader(strings.Repeat("a", 2000))))
+	version.ReplicationStatus = fileInfo.ReplicationPending
+	return &fakeRepo{versions: map[uint32]*fileInfo.FileVersion{1: version}, retried: map[int64]int{}}, store, primary
+}
+
+func TestWorker_CopiesStoredBytesAndDeletes(t *testing.T) {
+	ctx := context.Background()
+	repo, store, primary := setup(t)
+	replica := memory.New()
+	require.NoError(t, replica.Put(ctx, "gone/v1", strings.NewReader("old"), 3, ""))
+	repo.jobs = []*fileInfo.ReplicationJob{
+		{ID: 1, VersionID: 1, StorageKey: "f/v1", Operation: fileInfo.ReplicationPut},
+		{ID: 2, StorageKey: "gone/v1", Operation: fileInfo.ReplicationDelete},
+		// версия удалена до копирования
+		{ID: 3, VersionID: 7, StorageKey: "deleted/v1", Operation: fileInfo.ReplicationPut},
+	}
+
+	n, err := replication.NewWorker(repo, store, replica, replication.Config{MaxAttempts: 3}).ProcessOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []int64{1, 2, 3}, repo.completed)
+	assert.Equal(t, fileInfo.ReplicationReplicated, repo.versions[1].ReplicationStatus)
+
+	want, err := primary.Get(ctx, "f/v1")
+	require.NoError(t, err)
+	wantBytes, _ := io.ReadAll(want)
+	got, err := replica.Get(ctx, "f/v1")
+	require.NoError(t, err)
+	gotBytes, _ := io.ReadAll(got)
+	assert.Equal(t, wantBytes, gotBytes, "replica holds the object as stored, compressed")
+
+	_, err = replica.Stat(ctx, "gone/v1")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	_, err = replica.Stat(ctx, "deleted/v1")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestWorker_RetriesThenDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	repo, store, _ := setup(t)
+	worker := replication.NewWorker(repo, store, failingStore{memory.New()}, replication.Config{
+		MaxAttempts: 2, BaseBackoff: time.Second, MaxBackoff: time.Minute,
+	})
+
+	repo.jobs = []*fileInfo.ReplicationJob{{ID: 1, VersionID: 1, StorageKey: "f/v1", Operation: fileInfo.ReplicationPut}}
+	_, err := worker.ProcessOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.retried[1])
+	assert.Empty(t, repo.dead)
+
+	repo.jobs = []*fileInfo.ReplicationJob{{ID: 1, VersionID: 1, StorageKey: "f/v1", Operation: fileInfo.ReplicationPut, Attempts: 1}}
+	_, err = worker.ProcessOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, repo.dead)
+}
@@ -2,11 +2,48 @@ package BlackListRepo
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/redis/go-redis/v9"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// RevocationChannel — канал, в который публикуется каждый отзыв. Сервисы, которые проверяют
+// токены сами, держат копию чёрного списка и обновляют её по этому каналу.
+const RevocationChannel = "blacklist:revocations"
+
+const keyPrefix = "blacklist:"
+
+// watermarkPrefix нарочно не начинается с keyPrefix: ListTokens сканирует blacklist:* и
+// принял бы отметки за токены.
+const watermarkPrefix = "revoked-before:"
+
+// Revocation — сообщение об отзыве; запись можно забыть после ExpiresAt. Отзывается либо один
+// токен Token, либо все токены пользователя UserID с iat раньше IssuedBefore (Unix-время в секундах).
+type Revocation struct {
+	Token        string    `json:"token,omitempty"`
+	UserID       uint32    `json:"user_id,omitempty"`
+	IssuedBefore int64     `json:"issued_before,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// RevocationID — под каким ID токен заносится в чёрный список: jti, а у токенов, выданных до
+// появления jti, — вся строка токена.
+func RevocationID(jti, token string) string {
+	if jti != "" {
+		return jti
+	}
+	return token
+}
+
+// SessionRevocationID — ID, под которым в чёрный список заносится отозванная сессия: все
+// access-токены с этим sid перестают приниматься, в том числе при локальной проверке.
+func SessionRevocationID(sessionID string) string {
+	return "sid:" + sessionID
+}
+
 type BlackListRepo struct {
 	Client *redis.Client
 }
@@ -21,13 +58,27 @@ func (r *BlackListRepo) buildKey(token string) string {
 	return fmt.Sprintf("blacklist:%s", token)
 }
 
+func (r *BlackListRepo) buildWatermarkKey(userID uint32) string {
+	return fmt.Sprintf("revoked-before:%d", userID)
+}
+
+// AddToken заносит токен в чёрный список до истечения его срока и оповещает подписчиков.
+// token — ID из RevocationID.
 func (r *BlackListRepo) AddToken(ctx context.Context, token string, expiresAt time.Time) error {
-	ttl := time.Until(expiresAt)
+	// срок округляется вверх до секунды: запись не должна пропасть раньше самого токена
+	ttl := (time.Until(expiresAt) + time.Second - 1).Truncate(time.Second)
 	if ttl <= 0 {
 		return nil
 	}
-	key := r.buildKey(token)
-	return r.Client.Set(ctx, key, "1", ttl).Err()
+	payload, err := json.Marshal(Revocation{Token: token, ExpiresAt: expiresAt})
+	if err != nil {
+		return err
+	}
+	pipe := r.Client.TxPipeline()
+	pipe.Set(ctx, r.buildKey(token), "1", ttl)
+	pipe.Publish(ctx, RevocationChannel, payload)
+	_, err = pipe.Exec(ctx)
+	return err
 }
 
 func (r *BlackListRepo) RemoveToken(ctx context.Context, token string) error {
@@ -46,3 +97,100 @@ func (r *BlackListRepo) IsTokenBlacklisted(ctx context.Context, token string) (b
 	}
 	return true, nil
 }
+
+// ListTokens возвращает весь текущий чёрный список; по нему подписчики восстанавливают свою
+// копию после (пере)подключения к каналу.
+func (r *BlackListRepo) ListTokens(ctx context.Context) ([]Revocation, error) {
+	var revocations []Revocation
+	iter := r.Client.Scan(ctx, 0, keyPrefix+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		ttl, err := r.Client.PTTL(ctx, key).Result()
+		if err != nil {
+			return nil, err
+		}
+		if ttl <= 0 {
+			// ключ уже истёк или удалён между SCAN и PTTL
+			continue
+		}
+		revocations = append(revocations, Revocation{
+			Token:     strings.TrimPrefix(key, keyPrefix),
+			ExpiresAt: time.Now().Add(ttl),
+		})
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return revocations, nil
+}
+
+// RevokeIssuedBefore отзывает все токены пользователя, выданные раньше issuedBefore, не храня
+// их по отдельности. Сравнение идёт с iat, то есть с точностью до секунды: токены, выданные сразу
+// после отзыва в ту же секунду, действуют. ttl — срок жизни access-токена, после него более
+// старые токены истекают сами и отметка не нужна.
+func (r *BlackListRepo) RevokeIssuedBefore(ctx context.Context, userID uint32, issuedBefore time.Time, ttl time.Duration) error {
+	before := issuedBefore.Unix()
+	payload, err := json.Marshal(Revocation{UserID: userID, IssuedBefore: before, ExpiresAt: time.Now().Add(ttl)})
+	if err != nil {
+		return err
+	}
+	pipe := r.Client.TxPipeline()
+	pipe.Set(ctx, r.buildWatermarkKey(userID), before, ttl)
+	pipe.Publish(ctx, RevocationChannel, payload)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// IssuedBefore возвращает отметку пользователя: токены с iat раньше неё отозваны. 0 — отметки нет.
+func (r *BlackListRepo) IssuedBefore(ctx context.Context, userID uint32) (int64, error) {
+	before, err := r.Client.Get(ctx, r.buildWatermarkKey(userID)).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return before, err
+}
+
+// ListWatermarks возвращает отметки всех пользователей; как и ListTokens, нужен подписчикам
+// для пересборки копии.
+func (r *BlackListRepo) ListWatermarks(ctx context.Context) ([]Revocation, error) {
+	var revocations []Revocation
+	iter := r.Client.Scan(ctx, 0, watermarkPrefix+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		userID, err := strconv.ParseUint(strings.TrimPrefix(key, watermarkPrefix), 10, 32)
+		if err != nil {
+			continue
+		}
+		pipe := r.Client.Pipeline()
+		get := pipe.Get(ctx, key)
+		pttl := pipe.PTTL(ctx, key)
+		if _, err := pipe.Exec(ctx); err == redis.Nil {
+			// ключ истёк между SCAN и GET
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		before, err := get.Int64()
+		if err != nil {
+			return nil, err
+		}
+		if pttl.Val() <= 0 {
+			continue
+		}
+		revocations = append(revocations, Revocation{
+			UserID:       uint32(userID),
+			IssuedBefore: before,
+			ExpiresAt:    time.Now().Add(pttl.Val()),
+		})
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return revocations, nil
+}
+
+// Subscribe подписывается на RevocationChannel. go-redis переподключается сам и после каждой
+// переподписки присылает *redis.Subscription — в этот момент копию нужно пересобрать.
+func (r *BlackListRepo) Subscribe(ctx context.Context) *redis.PubSub {
+	return r.Client.Subscribe(ctx, RevocationChannel)
+}
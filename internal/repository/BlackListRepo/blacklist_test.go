@@ -2,11 +2,15 @@ package BlackListRepo_test
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/go-redis/redismock/v9"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"registration-service/internal/repository/BlackListRepo"
 )
 
@@ -16,9 +20,16 @@ func TestBlackListRepo(t *testing.T) {
 	repo := BlackListRepo.NewBlackListRepo(db)
 
 	t.Run("AddToken success", func(t *testing.T) {
-		// Используем ExpectSet вместо ExpectSetEx, так как в коде используется Set с TTL
+		expiresAt := time.Now().Add(time.Hour)
+		payload, err := json.Marshal(BlackListRepo.Revocation{Token: "token123", ExpiresAt: expiresAt})
+		assert.NoError(t, err)
+
+		// TTL округляется вверх до целых секунд, поэтому ровно час
+		mock.ExpectTxPipeline()
 		mock.ExpectSet("blacklist:token123", "1", time.Hour).SetVal("OK")
-		err := repo.AddToken(ctx, "token123", time.Now().Add(time.Hour))
+		mock.ExpectPublish(BlackListRepo.RevocationChannel, payload).SetVal(1)
+		mock.ExpectTxPipelineExec()
+		err = repo.AddToken(ctx, "token123", expiresAt)
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -46,3 +57,37 @@ func TestBlackListRepo(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+func TestBlackListRepo_Watermarks(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	repo := BlackListRepo.NewBlackListRepo(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+
+	before, err := repo.IssuedBefore(ctx, 1)
+	require.NoError(t, err)
+	assert.Zero(t, before)
+
+	revokedAt := time.Now()
+	require.NoError(t, repo.RevokeIssuedBefore(ctx, 1, revokedAt, time.Hour))
+	require.NoError(t, repo.AddToken(ctx, "jti-1", time.Now().Add(time.Hour)))
+
+	before, err = repo.IssuedBefore(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, revokedAt.Unix(), before)
+
+	watermarks, err := repo.ListWatermarks(ctx)
+	require.NoError(t, err)
+	require.Len(t, watermarks, 1)
+	assert.Equal(t, uint32(1), watermarks[0].UserID)
+	assert.Equal(t, revokedAt.Unix(), watermarks[0].IssuedBefore)
+
+	tokens, err := repo.ListTokens(ctx)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1, "watermarks are not listed as tokens")
+	assert.Equal(t, "jti-1", tokens[0].Token)
+
+	mr.FastForward(2 * time.Hour)
+	before, err = repo.IssuedBefore(ctx, 1)
+	require.NoError(t, err)
+	assert.Zero(t, before, "watermark outlives access tokens only")
+}
@@ -0,0 +1,132 @@
+package exportRepo
+
+import (
+	"context"
+	"errors"
+	"registration-service/internal/model/fileInfo"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// exportColumns — столбцы exports в порядке, который ожидает scanExport.
+const exportColumns = `id, owner_id, status, storage_key, size, error, codec, encryption_key_id, wrapped_key,
+		 created_at, started_at, completed_at, expires_at`
+
+func scanExport(row pgx.Row) (*fileInfo.Export, error) {
+	var e fileInfo.Export
+	err := row.Scan(&e.ID, &e.OwnerID, &e.Status, &e.StorageKey, &e.Size, &e.Error, &e.Codec, &e.KeyID, &e.WrappedKey,
+		&e.CreatedAt, &e.StartedAt, &e.CompletedAt, &e.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+type ExportRepository struct {
+	conn *pgxpool.Pool
+}
+
+func New(db *pgxpool.Pool) *ExportRepository {
+	return &ExportRepository{conn: db}
+}
+
+func (r *ExportRepository) CreateExport(ctx context.Context, export *fileInfo.Export) error {
+	_, err := r.conn.Exec(ctx,
+		`INSERT INTO exports (id, owner_id, status, created_at) VALUES ($1, $2, $3, $4)`,
+		export.ID, export.OwnerID, export.Status, export.CreatedAt)
+	return err
+}
+
+func (r *ExportRepository) GetExport(ctx context.Context, exportID uuid.UUID) (*fileInfo.Export, error) {
+	return scanExport(r.conn.QueryRow(ctx,
+		`SELECT `+exportColumns+` FROM exports WHERE id = $1`, exportID))
+}
+
+// GetActiveExport возвращает ещё не собранную выгрузку пользователя, если она есть.
+func (r *ExportRepository) GetActiveExport(ctx context.Context, ownerID uint32) (*fileInfo.Export, error) {
+	return scanExport(r.conn.QueryRow(ctx,
+		`SELECT `+exportColumns+`
+		 FROM exports
+		 WHERE owner_id = $1 AND status IN ($2, $3)
+		 ORDER BY created_at DESC
+		 LIMIT 1`,
+		ownerID, fileInfo.ExportPending, fileInfo.ExportRunning))
+}
+
+// ClaimExport забирает самую старую ожидающую выгрузку или выгрузку, lease которой истёк
+// (собиравший её экземпляр упал), и продлевает lease. nil — очередь пуста.
+func (r *ExportRepository) ClaimExport(ctx context.Context, lease time.Duration) (*fileInfo.Export, error) {
+	return scanExport(r.conn.QueryRow(ctx,
+		`UPDATE exports
+		 SET status = $1, started_at = NOW(), lease_until = NOW() + make_interval(secs => $3)
+		 WHERE id = (
+		     SELECT id FROM exports
+		     WHERE status = $2 OR (status = $1 AND lease_until < NOW())
+		     ORDER BY created_at
+		     LIMIT 1
+		     FOR UPDATE SKIP LOCKED
+		 )
+		 RETURNING `+exportColumns,
+		fileInfo.ExportRunning, fileInfo.ExportPending, lease.Seconds()))
+}
+
+// CompleteExport сохраняет собранный архив. Возвращает false, если задачу тем временем
+// забрал другой экземпляр или выгрузку удалили: тогда объект архива никому не принадлежит.
+func (r *ExportRepository) CompleteExport(ctx context.Context, export *fileInfo.Export, expiresAt time.Time) (bool, error) {
+	tag, err := r.conn.Exec(ctx,
+		`UPDATE exports
+		 SET status = $1, storage_key = $2, size = $3, codec = $4, encryption_key_id = $5, wrapped_key = $6,
+		     completed_at = NOW(), expires_at = $7, lease_until = NULL, error = ''
+		 WHERE id = $8 AND status = $9 AND started_at = $10`,
+		fileInfo.ExportReady, export.StorageKey, export.Size, export.Codec, export.KeyID, export.WrappedKey,
+		expiresAt, export.ID, fileInfo.ExportRunning, export.StartedAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *ExportRepository) FailExport(ctx context.Context, export *fileInfo.Export, lastError string, expiresAt time.Time) error {
+	_, err := r.conn.Exec(ctx,
+		`UPDATE exports
+		 SET status = $1, error = $2, completed_at = NOW(), expires_at = $3, lease_until = NULL
+		 WHERE id = $4 AND status = $5 AND started_at = $6`,
+		fileInfo.ExportFailed, lastError, expiresAt, export.ID, fileInfo.ExportRunning, export.StartedAt)
+	return err
+}
+
+// ListExpiredExports возвращает завершённые выгрузки, срок хранения которых истёк.
+func (r *ExportRepository) ListExpiredExports(ctx context.Context, limit int) ([]*fileInfo.Export, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT `+exportColumns+`
+		 FROM exports
+		 WHERE status IN ($1, $2) AND expires_at < NOW()
+		 ORDER BY expires_at
+		 LIMIT $3`,
+		fileInfo.ExportReady, fileInfo.ExportFailed, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exports []*fileInfo.Export
+	for rows.Next() {
+		export, err := scanExport(rows)
+		if err != nil {
+			return nil, err
+		}
+		exports = append(exports, export)
+	}
+	return exports, rows.Err()
+}
+
+func (r *ExportRepository) DeleteExport(ctx context.Context, exportID uuid.UUID) error {
+	_, err := r.conn.Exec(ctx, "DELETE FROM exports WHERE id = $1", exportID)
+	return err
+}
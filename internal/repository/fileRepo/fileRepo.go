@@ -4,33 +4,108 @@ import (
 	"context"
 	"errors"
 	"registration-service/internal/model/fileInfo"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// fileMetadataColumns подгружает теги и свойства файла f вместе с самой строкой.
+const fileMetadataColumns = `ARRAY(SELECT t.tag FROM file_tags t WHERE t.file_id = f.id ORDER BY t.tag),
+		 COALESCE((SELECT jsonb_object_agg(p.key, p.value) FROM file_properties p WHERE p.file_id = f.id), '{}'::jsonb)`
+
+// fileFilterCondition оставляет файлы f, у которых есть все теги $2 и все пары ключ/значение из $3.
+// Пустой фильтр пропускает всё.
+const fileFilterCondition = `(cardinality($2::text[]) = 0 OR f.id IN (
+		     SELECT t.file_id FROM file_tags t WHERE t.tag = ANY($2::text[])
+		     GROUP BY t.file_id HAVING COUNT(*) = cardinality($2::text[])))
+		 AND NOT EXISTS (
+		     SELECT 1 FROM jsonb_each_text($3::jsonb) want
+		     WHERE NOT EXISTS (
+		         SELECT 1 FROM file_properties p
+		         WHERE p.file_id = f.id AND p.key = want.key AND p.value = want.value))`
+
+// fileVersionColumns — столбцы версии v в порядке, который ожидает scanFileVersion.
+const fileVersionColumns = `v.id, v.file_id, v.version_number, v.storage_key, v.size, v.content_type, v.created_at,
+		 v.encryption_key_id, v.wrapped_key, v.codec, v.stored_size, v.scan_status, v.scan_result, v.status, v.tier, v.replication_status`
+
+func scanFileVersion(row pgx.Row) (*fileInfo.FileVersion, error) {
+	var v fileInfo.FileVersion
+	err := row.Scan(&v.ID, &v.FileID, &v.VersionNumber, &v.StorageKey, &v.Size, &v.ContentType, &v.CreatedAt,
+		&v.KeyID, &v.WrappedKey, &v.Codec, &v.StoredSize, &v.ScanStatus, &v.ScanResult, &v.Status, &v.Tier, &v.ReplicationStatus)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// querier — общее у пула и транзакции, чтобы вставки можно было выполнять в обоих.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func insertFile(ctx context.Context, q querier, file *fileInfo.File, status string) error {
+	_, err := q.Exec(ctx,
+		`INSERT INTO files (id, owner_id, name, current_version, created_at, status) 
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		file.ID, file.OwnerID, file.Name, file.CurrentVersion, file.CreatedAt, status)
+	return err
+}
+
+func insertFileVersion(ctx context.Context, q querier, version *fileInfo.FileVersion) error {
+	return q.QueryRow(ctx,
+		`INSERT INTO file_versions (file_id, version_number, storage_key, size, content_type, created_at, encryption_key_id, wrapped_key, codec, stored_size, scan_status, status, tier)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		 RETURNING id`,
+		version.FileID, version.VersionNumber, version.StorageKey, version.Size, version.ContentType, version.CreatedAt,
+		version.KeyID, version.WrappedKey, version.Codec, version.StoredSize, version.ScanStatus, version.Status, version.Tier).Scan(&version.ID)
+}
+
 type FileRepository struct {
-	conn *pgx.Conn
+	conn *pgxpool.Pool
+	// replication — ставить ли проверенные версии в очередь replication_jobs
+	replication bool
 }
 
-func New(db *pgx.Conn) *FileRepository {
-	return &FileRepository{conn: db}
+func New(db *pgxpool.Pool, replication bool) *FileRepository {
+	return &FileRepository{conn: db, replication: replication}
 }
 
 func (r *FileRepository) CreateFile(ctx context.Context, file *fileInfo.File) error {
-	_, err := r.conn.Exec(ctx,
-		`INSERT INTO files (id, owner_id, name, current_version, created_at) 
-		 VALUES ($1, $2, $3, $4, $5)`,
-		file.ID, file.OwnerID, file.Name, file.CurrentVersion, file.CreatedAt)
-	return err
+	return insertFile(ctx, r.conn, file, fileInfo.StatusActive)
+}
+
+// CreatePendingUpload одной транзакцией создаёт файл и его первую версию в состоянии pending.
+// Активными их делает ActivateVersion после загрузки объекта.
+func (r *FileRepository) CreatePendingUpload(ctx context.Context, file *fileInfo.File, version *fileInfo.FileVersion) error {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := insertFile(ctx, tx, file, fileInfo.StatusPending); err != nil {
+		return err
+	}
+	version.Status = fileInfo.StatusPending
+	if err := insertFileVersion(ctx, tx, version); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 func (r *FileRepository) GetFileByID(ctx context.Context, fileID uuid.UUID) (*fileInfo.File, error) {
 	var file fileInfo.File
 	err := r.conn.QueryRow(ctx,
-		`SELECT id, owner_id, name, current_version, created_at 
-		 FROM files WHERE id = $1`, fileID).
-		Scan(&file.ID, &file.OwnerID, &file.Name, &file.CurrentVersion, &file.CreatedAt)
+		`SELECT f.id, f.owner_id, f.name, f.current_version, f.created_at, `+fileMetadataColumns+`
+		 FROM files f WHERE f.id = $1 AND f.status = 'active'`, fileID).
+		Scan(&file.ID, &file.OwnerID, &file.Name, &file.CurrentVersion, &file.CreatedAt, &file.Tags, &file.Properties)
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
@@ -65,6 +140,16 @@ func (r *FileRepository) DeleteFile(ctx context.Context, fileID uuid.UUID) error
 		storageKeys = append(storageKeys, key)
 	}
 
+	// копии удаляются вместе с файлом, если версии успели попасть в очередь репликации
+	_, err = tx.Exec(ctx,
+		`INSERT INTO replication_jobs (storage_key, operation)
+		 SELECT storage_key, $2 FROM file_versions
+		 WHERE file_id = $1 AND replication_status <> $3`,
+		fileID, fileInfo.ReplicationDelete, fileInfo.ReplicationNone)
+	if err != nil {
+		return err
+	}
+
 	_, err = tx.Exec(ctx, "DELETE FROM file_versions WHERE file_id = $1", fileID)
 	if err != nil {
 		return err
@@ -78,10 +163,11 @@ func (r *FileRepository) DeleteFile(ctx context.Context, fileID uuid.UUID) error
 	return tx.Commit(ctx)
 }
 
-func (r *FileRepository) ListFilesByOwner(ctx context.Context, ownerID int) ([]*fileInfo.File, error) {
+func (r *FileRepository) ListFilesByOwner(ctx context.Context, ownerID int, filter fileInfo.FileFilter) ([]*fileInfo.File, error) {
 	rows, err := r.conn.Query(ctx,
-		`SELECT id, owner_id, name, current_version, created_at
-		 FROM files WHERE owner_id = $1`, ownerID)
+		`SELECT f.id, f.owner_id, f.name, f.current_version, f.created_at, `+fileMetadataColumns+`
+		 FROM files f WHERE f.owner_id = $1 AND f.status = 'active' AND `+fileFilterCondition,
+		ownerID, filterTags(filter), filterProperties(filter))
 	if err != nil {
 		return nil, err
 	}
@@ -91,7 +177,7 @@ func (r *FileRepository) ListFilesByOwner(ctx context.Context, ownerID int) ([]*
 	for rows.Next() {
 		var file fileInfo.File
 		if err := rows.Scan(
-			&file.ID, &file.OwnerID, &file.Name, &file.CurrentVersion, &file.CreatedAt,
+			&file.ID, &file.OwnerID, &file.Name, &file.CurrentVersion, &file.CreatedAt, &file.Tags, &file.Properties,
 		); err != nil {
 			return nil, err
 		}
@@ -101,43 +187,53 @@ func (r *FileRepository) ListFilesByOwner(ctx context.Context, ownerID int) ([]*
 }
 
 func (r *FileRepository) CreateFileVersion(ctx context.Context, version *fileInfo.FileVersion) error {
-	_, err := r.conn.Exec(ctx,
-		`INSERT INTO file_versions (file_id, version_number, storage_key, size, created_at)
-		 VALUES ($1, $2, $3, $4, $5)`,
-		version.FileID, version.VersionNumber, version.StorageKey, version.Size, version.CreatedAt)
-	return err
+	return insertFileVersion(ctx, r.conn, version)
 }
 
 func (r *FileRepository) GetFileVersion(ctx context.Context, fileID uuid.UUID, version int) (*fileInfo.FileVersion, error) {
-	var fv fileInfo.FileVersion
-	err := r.conn.QueryRow(ctx,
-		`SELECT id, file_id, version_number, storage_key, size, created_at
-		 FROM file_versions 
-		 WHERE file_id = $1 AND version_number = $2`,
-		fileID, version).
-		Scan(&fv.ID, &fv.FileID, &fv.VersionNumber, &fv.StorageKey, &fv.Size, &fv.CreatedAt)
+	return scanFileVersion(r.conn.QueryRow(ctx,
+		`SELECT `+fileVersionColumns+`
+		 FROM file_versions v
+		 WHERE v.file_id = $1 AND v.version_number = $2 AND v.status = 'active'`,
+		fileID, version))
+}
 
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	}
-	return &fv, err
+func (r *FileRepository) GetFileVersionByID(ctx context.Context, versionID uint32) (*fileInfo.FileVersion, error) {
+	return scanFileVersion(r.conn.QueryRow(ctx,
+		`SELECT `+fileVersionColumns+`
+		 FROM file_versions v
+		 WHERE v.id = $1`,
+		versionID))
 }
 
 func (r *FileRepository) GetLatestFileVersion(ctx context.Context, fileID uuid.UUID) (*fileInfo.FileVersion, error) {
-	var fv fileInfo.FileVersion
-	err := r.conn.QueryRow(ctx,
-		`SELECT id, file_id, version_number, storage_key, size, created_at
-		 FROM file_versions 
-		 WHERE file_id = $1
-		 ORDER BY version_number DESC
+	return scanFileVersion(r.conn.QueryRow(ctx,
+		`SELECT `+fileVersionColumns+`
+		 FROM file_versions v
+		 WHERE v.file_id = $1 AND v.status = 'active'
+		 ORDER BY v.version_number DESC
 		 LIMIT 1`,
-		fileID).
-		Scan(&fv.ID, &fv.FileID, &fv.VersionNumber, &fv.StorageKey, &fv.Size, &fv.CreatedAt)
+		fileID))
+}
 
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
-	}
-	return &fv, err
+// NextVersionNumber возвращает номер для новой версии файла с учётом ещё не завершённых загрузок.
+func (r *FileRepository) NextVersionNumber(ctx context.Context, fileID uuid.UUID) (int, error) {
+	var next int
+	err := r.conn.QueryRow(ctx,
+		"SELECT COALESCE(MAX(version_number), 0) + 1 FROM file_versions WHERE file_id = $1",
+		fileID).Scan(&next)
+	return next, err
+}
+
+// GetCurrentFileVersion возвращает версию, на которую указывает files.current_version, то есть
+// последнюю проверенную сканером. nil — такой версии ещё нет.
+func (r *FileRepository) GetCurrentFileVersion(ctx context.Context, fileID uuid.UUID) (*fileInfo.FileVersion, error) {
+	return scanFileVersion(r.conn.QueryRow(ctx,
+		`SELECT `+fileVersionColumns+`
+		 FROM file_versions v
+		 JOIN files f ON f.id = v.file_id AND f.current_version = v.version_number
+		 WHERE v.file_id = $1`,
+		fileID))
 }
 
 func (r *FileRepository) UpdateCurrentVersion(ctx context.Context, fileID uuid.UUID, newVersion int) error {
@@ -149,10 +245,10 @@ func (r *FileRepository) UpdateCurrentVersion(ctx context.Context, fileID uuid.U
 
 func (r *FileRepository) GetFileVersions(ctx context.Context, fileID uuid.UUID) ([]*fileInfo.FileVersion, error) {
 	rows, err := r.conn.Query(ctx,
-		`SELECT id, file_id, version_number, storage_key, size, created_at
-		 FROM file_versions 
-		 WHERE file_id = $1
-		 ORDER BY version_number DESC`,
+		`SELECT `+fileVersionColumns+`
+		 FROM file_versions v
+		 WHERE v.file_id = $1 AND v.status = 'active'
+		 ORDER BY v.version_number DESC`,
 		fileID)
 	if err != nil {
 		return nil, err
@@ -161,11 +257,11 @@ func (r *FileRepository) GetFileVersions(ctx context.Context, fileID uuid.UUID)
 
 	var versions []*fileInfo.FileVersion
 	for rows.Next() {
-		var v fileInfo.FileVersion
-		if err := rows.Scan(&v.ID, &v.FileID, &v.VersionNumber, &v.StorageKey, &v.Size, &v.CreatedAt); err != nil {
+		v, err := scanFileVersion(rows)
+		if err != nil {
 			return nil, err
 		}
-		versions = append(versions, &v)
+		versions = append(versions, v)
 	}
 	return versions, nil
 }
@@ -231,12 +327,25 @@ func (r *FileRepository) CheckUserPermission(ctx context.Context, fileID uuid.UU
 	return permission, err
 }
 
-func (r *FileRepository) GetSharedFiles(ctx context.Context, userID int) ([]*fileInfo.File, error) {
+// LookupUser сообщает, есть ли пользователь и администратор ли он. found = false — нет.
+func (r *FileRepository) LookupUser(ctx context.Context, userID uint32) (isAdmin bool, found bool, err error) {
+	err = r.conn.QueryRow(ctx, `SELECT is_admin FROM users WHERE id = $1`, userID).Scan(&isAdmin)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	return isAdmin, true, nil
+}
+
+func (r *FileRepository) GetSharedFiles(ctx context.Context, userID int, filter fileInfo.FileFilter) ([]*fileInfo.File, error) {
 	rows, err := r.conn.Query(ctx,
-		`SELECT f.id, f.owner_id, f.name, f.current_version, f.created_at
+		`SELECT f.id, f.owner_id, f.name, f.current_version, f.created_at, `+fileMetadataColumns+`
 		 FROM files f
 		 JOIN file_permissions fp ON f.id = fp.file_id
-		 WHERE fp.user_id = $1`, userID)
+		 WHERE fp.user_id = $1 AND f.status = 'active' AND `+fileFilterCondition,
+		userID, filterTags(filter), filterProperties(filter))
 	if err != nil {
 		return nil, err
 	}
@@ -246,7 +355,7 @@ func (r *FileRepository) GetSharedFiles(ctx context.Context, userID int) ([]*fil
 	for rows.Next() {
 		var file fileInfo.File
 		if err := rows.Scan(
-			&file.ID, &file.OwnerID, &file.Name, &file.CurrentVersion, &file.CreatedAt,
+			&file.ID, &file.OwnerID, &file.Name, &file.CurrentVersion, &file.CreatedAt, &file.Tags, &file.Properties,
 		); err != nil {
 			return nil, err
 		}
@@ -269,3 +378,563 @@ func (r *FileRepository) FileExists(ctx context.Context, fileID uuid.UUID) (bool
 		fileID).Scan(&exists)
 	return exists, err
 }
+
+// UpsertSearchDocument сохраняет индекс версии; tsvector считается в БД. Сам текст (по нему
+// строятся фрагменты в выдаче) сохраняется только с keepContent, иначе в таблице остаётся
+// один tsvector.
+func (r *FileRepository) UpsertSearchDocument(ctx context.Context, fileID uuid.UUID, version uint32, content string, keepContent bool) error {
+	_, err := r.conn.Exec(ctx,
+		`INSERT INTO file_search (file_id, version_number, content, document, indexed_at)
+		 VALUES ($1, $2, CASE WHEN $4 THEN $3 ELSE '' END, to_tsvector('simple', $3), NOW())
+		 ON CONFLICT (file_id) DO UPDATE
+		 SET version_number = EXCLUDED.version_number,
+		     content = EXCLUDED.content,
+		     document = EXCLUDED.document,
+		     indexed_at = EXCLUDED.indexed_at`,
+		fileID, version, content, keepContent)
+	return err
+}
+
+// ListFilesNeedingIndex возвращает файлы, у которых индекс отсутствует или построен по старой версии.
+func (r *FileRepository) ListFilesNeedingIndex(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT f.id
+		 FROM files f
+		 LEFT JOIN file_search fs ON fs.file_id = f.id
+		 WHERE f.current_version > 0 AND (fs.file_id IS NULL OR fs.version_number <> f.current_version)
+		 LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// SearchFiles ищет по имени и содержимому среди файлов, которые пользователь может открыть:
+// свои и те, где у него есть право permission (то же правило, что в checkFileAccess).
+func (r *FileRepository) SearchFiles(ctx context.Context, userID int, permission int, query string, limit, offset int) ([]*fileInfo.SearchResult, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT f.id, f.owner_id, f.name, f.current_version, f.created_at,
+		        ts_rank(setweight(to_tsvector('simple', f.name), 'A') ||
+		                setweight(COALESCE(fs.document, ''::tsvector), 'B'), q) AS rank,
+		        CASE WHEN fs.document @@ q AND fs.content <> ''
+		             THEN ts_headline('simple', fs.content, q, 'StartSel=<b>, StopSel=</b>, MaxFragments=2, MaxWords=20, MinWords=5')
+		             ELSE ts_headline('simple', f.name, q, 'StartSel=<b>, StopSel=</b>, HighlightAll=true')
+		        END AS snippet
+		 FROM files f
+		 LEFT JOIN file_search fs ON fs.file_id = f.id,
+		      websearch_to_tsquery('simple', $1) q
+		 WHERE f.status = 'active'
+		   AND (to_tsvector('simple', f.name) @@ q OR fs.document @@ q)
+		   AND (f.owner_id = $2 OR EXISTS (
+		        SELECT 1 FROM file_permissions fp
+		        WHERE fp.file_id = f.id AND fp.user_id = $2 AND fp.permission = $3))
+		 ORDER BY rank DESC, f.created_at DESC
+		 LIMIT $4 OFFSET $5`,
+		query, userID, permission, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*fileInfo.SearchResult
+	for rows.Next() {
+		var file fileInfo.File
+		var result fileInfo.SearchResult
+		if err := rows.Scan(
+			&file.ID, &file.OwnerID, &file.Name, &file.CurrentVersion, &file.CreatedAt,
+			&result.Rank, &result.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		result.File = &file
+		results = append(results, &result)
+	}
+	return results, rows.Err()
+}
+
+func filterTags(filter fileInfo.FileFilter) []string {
+	if filter.Tags == nil {
+		return []string{}
+	}
+	return filter.Tags
+}
+
+func filterProperties(filter fileInfo.FileFilter) map[string]string {
+	if filter.Properties == nil {
+		return map[string]string{}
+	}
+	return filter.Properties
+}
+
+func (r *FileRepository) SetFileTags(ctx context.Context, fileID uuid.UUID, tags []string) error {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "DELETE FROM file_tags WHERE file_id = $1", fileID); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO file_tags (file_id, tag) VALUES ($1, $2)
+			 ON CONFLICT DO NOTHING`,
+			fileID, tag); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *FileRepository) SetFileProperties(ctx context.Context, fileID uuid.UUID, properties map[string]string) error {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "DELETE FROM file_properties WHERE file_id = $1", fileID); err != nil {
+		return err
+	}
+	for key, value := range properties {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO file_properties (file_id, key, value) VALUES ($1, $2, $3)`,
+			fileID, key, value); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// ListVersionsToRewrap возвращает зашифрованные версии, ключ данных которых обёрнут не активным
+// мастер-ключом. Выборка идёт по возрастанию id начиная после afterID, чтобы обходить таблицу страницами.
+func (r *FileRepository) ListVersionsToRewrap(ctx context.Context, activeKeyID string, afterID uint32, limit int) ([]*fileInfo.FileVersion, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT id, storage_key, encryption_key_id, wrapped_key
+		 FROM file_versions
+		 WHERE wrapped_key IS NOT NULL AND encryption_key_id <> $1 AND id > $2
+		 ORDER BY id
+		 LIMIT $3`,
+		activeKeyID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []*fileInfo.FileVersion
+	for rows.Next() {
+		var v fileInfo.FileVersion
+		if err := rows.Scan(&v.ID, &v.StorageKey, &v.KeyID, &v.WrappedKey); err != nil {
+			return nil, err
+		}
+		versions = append(versions, &v)
+	}
+	return versions, rows.Err()
+}
+
+// UpdateWrappedKey заменяет обёрнутый ключ, только если строку не переупаковали параллельно.
+func (r *FileRepository) UpdateWrappedKey(ctx context.Context, versionID uint32, oldKeyID, keyID string, wrapped []byte) (bool, error) {
+	tag, err := r.conn.Exec(ctx,
+		`UPDATE file_versions SET encryption_key_id = $1, wrapped_key = $2
+		 WHERE id = $3 AND encryption_key_id = $4`,
+		keyID, wrapped, versionID, oldKeyID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ListPendingScanVersions возвращает id версий, ожидающих проверки сканером, от старых к новым.
+func (r *FileRepository) ListPendingScanVersions(ctx context.Context, limit int) ([]uint32, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT id FROM file_versions
+		 WHERE scan_status = $1 AND status = 'active'
+		 ORDER BY id
+		 LIMIT $2`,
+		fileInfo.ScanPending, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uint32
+	for rows.Next() {
+		var id uint32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// MarkVersionClean помечает версию чистой и делает её текущей, если она новее текущей.
+// При включённой репликации в той же транзакции версия ставится в очередь копирования.
+// Возвращает false, если версия уже была обработана или удалена.
+func (r *FileRepository) MarkVersionClean(ctx context.Context, versionID uint32) (bool, error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var fileID uuid.UUID
+	var versionNumber int
+	err = tx.QueryRow(ctx,
+		`UPDATE file_versions SET scan_status = $1, scan_result = '', scanned_at = NOW()
+		 WHERE id = $2 AND scan_status = $3 AND status = 'active'
+		 RETURNING file_id, version_number`,
+		fileInfo.ScanClean, versionID, fileInfo.ScanPending).Scan(&fileID, &versionNumber)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(ctx,
+		"UPDATE files SET current_version = $1 WHERE id = $2 AND current_version < $1",
+		versionNumber, fileID); err != nil {
+		return false, err
+	}
+	if r.replication {
+		if err := enqueueReplication(ctx, tx, versionID); err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit(ctx)
+}
+
+// MarkVersionInfected помечает версию заражённой и запоминает ключ объекта в карантине.
+func (r *FileRepository) MarkVersionInfected(ctx context.Context, versionID uint32, threat, quarantineKey string) error {
+	_, err := r.conn.Exec(ctx,
+		`UPDATE file_versions SET scan_status = $1, scan_result = $2, storage_key = $3, scanned_at = NOW()
+		 WHERE id = $4`,
+		fileInfo.ScanInfected, threat, quarantineKey, versionID)
+	return err
+}
+
+// ForEachObjectReference перебирает ключи объектов всех версий, не загружая их в память разом.
+// Архивы готовых выгрузок тоже ссылаются на объекты; у таких ссылок VersionID равен 0.
+func (r *FileRepository) ForEachObjectReference(ctx context.Context, fn func(fileInfo.ObjectReference) error) error {
+	rows, err := r.conn.Query(ctx,
+		`SELECT id, file_id, storage_key, created_at, object_missing_at IS NOT NULL, tier
+		 FROM file_versions
+		 UNION ALL
+		 SELECT 0, '00000000-0000-0000-0000-000000000000'::uuid, storage_key, created_at, FALSE, 'hot'
+		 FROM exports WHERE storage_key <> ''`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ref fileInfo.ObjectReference
+		if err := rows.Scan(&ref.VersionID, &ref.FileID, &ref.StorageKey, &ref.CreatedAt, &ref.Missing, &ref.Tier); err != nil {
+			return err
+		}
+		if err := fn(ref); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// SetObjectMissing помечает версию, объект которой не найден в хранилище, или снимает пометку.
+func (r *FileRepository) SetObjectMissing(ctx context.Context, versionID uint32, missing bool) error {
+	_, err := r.conn.Exec(ctx,
+		`UPDATE file_versions
+		 SET object_missing_at = CASE WHEN $1 THEN COALESCE(object_missing_at, NOW()) END
+		 WHERE id = $2`,
+		missing, versionID)
+	return err
+}
+
+// ActivateVersion переводит загруженную версию и, если он ещё pending, её файл в состояние active
+// и сохраняет фактический размер объекта. Возвращает false, если pending-версии уже нет
+// (например, её откатил RecoveryWorker).
+func (r *FileRepository) ActivateVersion(ctx context.Context, version *fileInfo.FileVersion) (bool, error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var fileID uuid.UUID
+	err = tx.QueryRow(ctx,
+		`UPDATE file_versions SET status = $1, stored_size = $2
+		 WHERE id = $3 AND status = $4
+		 RETURNING file_id`,
+		fileInfo.StatusActive, version.StoredSize, version.ID, fileInfo.StatusPending).Scan(&fileID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(ctx,
+		"UPDATE files SET status = $1 WHERE id = $2 AND status = $3",
+		fileInfo.StatusActive, fileID, fileInfo.StatusPending); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	version.Status = fileInfo.StatusActive
+	return true, nil
+}
+
+// DeletePendingVersion удаляет незавершённую версию и её файл, если тот тоже pending и других
+// версий у него нет. Активные версии не трогает.
+func (r *FileRepository) DeletePendingVersion(ctx context.Context, versionID uint32) error {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var fileID uuid.UUID
+	err = tx.QueryRow(ctx,
+		`DELETE FROM file_versions WHERE id = $1 AND status = $2 RETURNING file_id`,
+		versionID, fileInfo.StatusPending).Scan(&fileID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM files f
+		 WHERE f.id = $1 AND f.status = $2
+		   AND NOT EXISTS (SELECT 1 FROM file_versions v WHERE v.file_id = f.id)`,
+		fileID, fileInfo.StatusPending); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// SetVersionCreatedAt переносит дату создания активной версии. Импорт восстанавливает
+// исходные даты только после загрузки: по created_at pending-версии RecoveryWorker решает,
+// что загрузка прервалась.
+func (r *FileRepository) SetVersionCreatedAt(ctx context.Context, versionID uint32, createdAt time.Time) error {
+	_, err := r.conn.Exec(ctx,
+		"UPDATE file_versions SET created_at = $1 WHERE id = $2 AND status = $3",
+		createdAt, versionID, fileInfo.StatusActive)
+	return err
+}
+
+// ListStalePendingVersions возвращает версии, оставшиеся pending с момента до before, от старых к новым.
+func (r *FileRepository) ListStalePendingVersions(ctx context.Context, before time.Time, limit int) ([]*fileInfo.FileVersion, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT `+fileVersionColumns+`
+		 FROM file_versions v
+		 WHERE v.status = $1 AND v.created_at < $2
+		 ORDER BY v.id
+		 LIMIT $3`,
+		fileInfo.StatusPending, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []*fileInfo.FileVersion
+	for rows.Next() {
+		version, err := scanFileVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
+// TouchFile отмечает скачивание файла для политики хранения. Время обновляется не чаще раза в час,
+// чтобы частые скачивания не превращались в поток записей.
+func (r *FileRepository) TouchFile(ctx context.Context, fileID uuid.UUID) error {
+	_, err := r.conn.Exec(ctx,
+		`UPDATE files SET last_accessed_at = NOW()
+		 WHERE id = $1 AND last_accessed_at < NOW() - INTERVAL '1 hour'`,
+		fileID)
+	return err
+}
+
+// ListVersionsForColdTier возвращает горячие проверенные версии, которые пора перенести в холодный
+// уровень: предыдущие версии файлов и все версии файлов, не скачивавшихся с accessedBefore.
+// Версии новее текущей ещё ждут проверки и не переносятся.
+func (r *FileRepository) ListVersionsForColdTier(ctx context.Context, accessedBefore time.Time, limit int) ([]*fileInfo.FileVersion, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT `+fileVersionColumns+`
+		 FROM file_versions v
+		 JOIN files f ON f.id = v.file_id
+		 WHERE v.tier = $1 AND v.status = $2 AND v.scan_status = $3 AND v.object_missing_at IS NULL
+		   AND (v.version_number < f.current_version OR f.last_accessed_at < $4)
+		 ORDER BY v.id
+		 LIMIT $5`,
+		fileInfo.TierHot, fileInfo.StatusActive, fileInfo.ScanClean, accessedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []*fileInfo.FileVersion
+	for rows.Next() {
+		version, err := scanFileVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
+// SetVersionTier переключает уровень версии, только если он не изменился с момента чтения.
+func (r *FileRepository) SetVersionTier(ctx context.Context, versionID uint32, fromTier, toTier string) (bool, error) {
+	tag, err := r.conn.Exec(ctx,
+		"UPDATE file_versions SET tier = $1 WHERE id = $2 AND tier = $3",
+		toTier, versionID, fromTier)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// enqueueReplication ставит объект версии в очередь копирования во вторичное хранилище.
+func enqueueReplication(ctx context.Context, q querier, versionID uint32) error {
+	if _, err := q.Exec(ctx,
+		"UPDATE file_versions SET replication_status = $1 WHERE id = $2",
+		fileInfo.ReplicationPending, versionID); err != nil {
+		return err
+	}
+	_, err := q.Exec(ctx,
+		`INSERT INTO replication_jobs (version_id, storage_key, operation)
+		 SELECT id, storage_key, $2 FROM file_versions WHERE id = $1`,
+		versionID, fileInfo.ReplicationPut)
+	return err
+}
+
+// RequeueReplication повторно ставит версию в очередь копирования (например, после VerifyReplica).
+func (r *FileRepository) RequeueReplication(ctx context.Context, versionID uint32) error {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := enqueueReplication(ctx, tx, versionID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ClaimReplicationJobs выбирает готовые к выполнению задачи и откладывает их на lease,
+// чтобы параллельный экземпляр не взял те же задачи.
+func (r *FileRepository) ClaimReplicationJobs(ctx context.Context, limit int, lease time.Duration) ([]*fileInfo.ReplicationJob, error) {
+	rows, err := r.conn.Query(ctx,
+		`UPDATE replication_jobs
+		 SET next_attempt_at = NOW() + make_interval(secs => $3)
+		 WHERE id IN (
+		     SELECT id FROM replication_jobs
+		     WHERE status = $1 AND next_attempt_at <= NOW()
+		     ORDER BY id
+		     LIMIT $2
+		     FOR UPDATE SKIP LOCKED
+		 )
+		 RETURNING id, COALESCE(version_id, 0), storage_key, operation, status, attempts, created_at`,
+		fileInfo.ReplicationJobPending, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*fileInfo.ReplicationJob
+	for rows.Next() {
+		var j fileInfo.ReplicationJob
+		if err := rows.Scan(&j.ID, &j.VersionID, &j.StorageKey, &j.Operation, &j.Status, &j.Attempts, &j.CreatedAt); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, &j)
+	}
+	return jobs, rows.Err()
+}
+
+// CompleteReplicationJob закрывает задачу и, для копирования, отмечает версию реплицированной.
+func (r *FileRepository) CompleteReplicationJob(ctx context.Context, job *fileInfo.ReplicationJob, attempts int) error {
+	return r.finishReplicationJob(ctx, job, fileInfo.ReplicationJobDone, fileInfo.ReplicationReplicated, attempts, "")
+}
+
+// FailReplicationJob переводит задачу в dead-letter и, для копирования, отмечает версию как неудачную.
+func (r *FileRepository) FailReplicationJob(ctx context.Context, job *fileInfo.ReplicationJob, attempts int, lastError string) error {
+	return r.finishReplicationJob(ctx, job, fileInfo.ReplicationJobDead, fileInfo.ReplicationFailed, attempts, lastError)
+}
+
+func (r *FileRepository) finishReplicationJob(ctx context.Context, job *fileInfo.ReplicationJob, jobStatus, versionStatus string, attempts int, lastError string) error {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE replication_jobs
+		 SET status = $1, attempts = $2, last_error = $3, completed_at = NOW()
+		 WHERE id = $4`,
+		jobStatus, attempts, lastError, job.ID); err != nil {
+		return err
+	}
+	if job.Operation == fileInfo.ReplicationPut {
+		// более поздняя задача по той же версии могла уже поставить её в очередь заново
+		if _, err := tx.Exec(ctx,
+			`UPDATE file_versions SET replication_status = $1
+			 WHERE id = $2 AND NOT EXISTS (
+			     SELECT 1 FROM replication_jobs j
+			     WHERE j.version_id = $2 AND j.status = $3 AND j.id > $4)`,
+			versionStatus, job.VersionID, fileInfo.ReplicationJobPending, job.ID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *FileRepository) RetryReplicationJob(ctx context.Context, jobID int64, attempts int, nextAttemptAt time.Time, lastError string) error {
+	_, err := r.conn.Exec(ctx,
+		`UPDATE replication_jobs
+		 SET attempts = $1, next_attempt_at = $2, last_error = $3
+		 WHERE id = $4`,
+		attempts, nextAttemptAt, lastError, jobID)
+	return err
+}
+
+// ForEachReplicatedVersion перебирает версии, копия которых считается записанной во вторичное хранилище.
+func (r *FileRepository) ForEachReplicatedVersion(ctx context.Context, fn func(*fileInfo.FileVersion) error) error {
+	rows, err := r.conn.Query(ctx,
+		`SELECT `+fileVersionColumns+`
+		 FROM file_versions v
+		 WHERE v.replication_status = $1
+		 ORDER BY v.id`,
+		fileInfo.ReplicationReplicated)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		version, err := scanFileVersion(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(version); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,69 @@
+package loginAttemptRepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// LoginAttemptRepo считает неудачные входы по ключу — аккаунту или адресу — и хранит блокировки.
+// Счётчик живёт, пока неудачи идут чаще window; блокировка снимается сама по истечении срока.
+type LoginAttemptRepo struct {
+	Client *redis.Client
+}
+
+func New(client *redis.Client) *LoginAttemptRepo {
+	return &LoginAttemptRepo{Client: client}
+}
+
+func (r *LoginAttemptRepo) buildFailuresKey(key string) string {
+	return fmt.Sprintf("login:failures:%s", key)
+}
+
+func (r *LoginAttemptRepo) buildBlockKey(key string) string {
+	return fmt.Sprintf("login:blocked:%s", key)
+}
+
+// Blocked возвращает, сколько ещё действует самая долгая из блокировок ключей; 0 — вход разрешён.
+func (r *LoginAttemptRepo) Blocked(ctx context.Context, keys ...string) (time.Duration, error) {
+	pipe := r.Client.Pipeline()
+	cmds := make([]*redis.DurationCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.PTTL(ctx, r.buildBlockKey(key))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	var longest time.Duration
+	for _, cmd := range cmds {
+		// для отсутствующего ключа PTTL отрицателен
+		if ttl := cmd.Val(); ttl > longest {
+			longest = ttl
+		}
+	}
+	return longest, nil
+}
+
+// RecordFailure увеличивает счётчик неудач и возвращает его значение.
+func (r *LoginAttemptRepo) RecordFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	failuresKey := r.buildFailuresKey(key)
+	pipe := r.Client.TxPipeline()
+	incr := pipe.Incr(ctx, failuresKey)
+	pipe.Expire(ctx, failuresKey, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// Block запрещает вход по ключу на d.
+func (r *LoginAttemptRepo) Block(ctx context.Context, key string, d time.Duration) error {
+	return r.Client.Set(ctx, r.buildBlockKey(key), "1", d).Err()
+}
+
+// Reset сбрасывает счётчик и снимает блокировку: после успешного входа или разблокировки
+// администратором.
+func (r *LoginAttemptRepo) Reset(ctx context.Context, key string) error {
+	return r.Client.Del(ctx, r.buildFailuresKey(key), r.buildBlockKey(key)).Err()
+}
@@ -0,0 +1,47 @@
+package loginAttemptRepo_test
+
+import (
+	"context"
+	"registration-service/internal/repository/loginAttemptRepo"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginAttemptRepo(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	repo := loginAttemptRepo.New(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+
+	wait, err := repo.Blocked(ctx, "user:alice", "ip:10.0.0.1")
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+
+	for i := int64(1); i <= 3; i++ {
+		failures, err := repo.RecordFailure(ctx, "user:alice", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, i, failures)
+	}
+	require.NoError(t, repo.Block(ctx, "user:alice", time.Minute))
+	require.NoError(t, repo.Block(ctx, "ip:10.0.0.1", time.Hour))
+	wait, err = repo.Blocked(ctx, "user:alice", "ip:10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, wait, "the longest block wins")
+
+	require.NoError(t, repo.Reset(ctx, "user:alice"))
+	wait, err = repo.Blocked(ctx, "user:alice")
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+	failures, err := repo.RecordFailure(ctx, "user:alice", time.Minute)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, failures, "reset clears the counter")
+
+	mr.FastForward(2 * time.Minute)
+	failures, err = repo.RecordFailure(ctx, "user:alice", time.Minute)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, failures, "counter expires after the window")
+}
@@ -0,0 +1,43 @@
+package mfaRepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+type MFARepo struct {
+	Client *redis.Client
+}
+
+func New(client *redis.Client) *MFARepo {
+	return &MFARepo{Client: client}
+}
+
+func (r *MFARepo) buildStepKey(userID uint32, step uint64) string {
+	return fmt.Sprintf("mfa:used:%d:%d", userID, step)
+}
+
+func (r *MFARepo) buildAttemptsKey(tokenID string) string {
+	return fmt.Sprintf("mfa:attempts:%s", tokenID)
+}
+
+// MarkStepUsed отмечает интервал TOTP пользователя использованным. false — код этого
+// интервала уже предъявляли, и повторно он не принимается.
+func (r *MFARepo) MarkStepUsed(ctx context.Context, userID uint32, step uint64, ttl time.Duration) (bool, error) {
+	return r.Client.SetNX(ctx, r.buildStepKey(userID, step), "1", ttl).Result()
+}
+
+// CountAttempt увеличивает счётчик попыток ввода кода для mfa-токена и возвращает его значение.
+// Счётчик живёт не дольше самого токена.
+func (r *MFARepo) CountAttempt(ctx context.Context, tokenID string, ttl time.Duration) (int64, error) {
+	key := r.buildAttemptsKey(tokenID)
+	pipe := r.Client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
@@ -0,0 +1,108 @@
+package resetToken
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+type ResetTokenRepo struct {
+	Client *redis.Client
+}
+
+func New(client *redis.Client) *ResetTokenRepo {
+	return &ResetTokenRepo{Client: client}
+}
+
+func (r *ResetTokenRepo) buildKey(tokenHash string) string {
+	return fmt.Sprintf("password_reset:%s", tokenHash)
+}
+
+func (r *ResetTokenRepo) buildUserKey(userID uint32) string {
+	return fmt.Sprintf("password_reset:user:%d", userID)
+}
+
+func (r *ResetTokenRepo) buildThrottleKey(userID uint32) string {
+	return fmt.Sprintf("password_reset:throttle:%d", userID)
+}
+
+// SaveToken сохраняет хеш токена сброса. У пользователя действует только последний
+// выданный токен: предыдущий удаляется.
+func (r *ResetTokenRepo) SaveToken(ctx context.Context, userID uint32, tokenHash string, ttl time.Duration) error {
+	previous, err := r.Client.Get(ctx, r.buildUserKey(userID)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	_, err = r.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if previous != "" {
+			pipe.Del(ctx, r.buildKey(previous))
+		}
+		pipe.Set(ctx, r.buildKey(tokenHash), userID, ttl)
+		pipe.Set(ctx, r.buildUserKey(userID), tokenHash, ttl)
+		return nil
+	})
+	return err
+}
+
+// consumeScript забирает токен вместе с оставшимся сроком жизни. Ссылка password_reset:user
+// остаётся: по ней RestoreToken узнаёт, не выдан ли с тех пор новый токен.
+var consumeScript = redis.NewScript(`
+local userID = redis.call('GET', KEYS[1])
+if not userID then
+	return false
+end
+local ttl = redis.call('PTTL', KEYS[1])
+redis.call('DEL', KEYS[1])
+return {userID, ttl}
+`)
+
+// restoreScript возвращает токен, если он всё ещё последний выданный пользователю.
+var restoreScript = redis.NewScript(`
+if redis.call('GET', KEYS[2]) ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+return 1
+`)
+
+// ConsumeToken атомарно забирает токен, так что воспользоваться им можно один раз, и
+// возвращает, сколько ему оставалось жить. found = false — токена нет, он истёк или уже
+// использован.
+func (r *ResetTokenRepo) ConsumeToken(ctx context.Context, tokenHash string) (userID uint32, ttl time.Duration, found bool, err error) {
+	res, err := consumeScript.Run(ctx, r.Client, []string{r.buildKey(tokenHash)}).Slice()
+	if errors.Is(err, redis.Nil) {
+		return 0, 0, false, nil
+	}
+	if err != nil {
+		return 0, 0, false, err
+	}
+	value, _ := res[0].(string)
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("malformed reset token record: %w", err)
+	}
+	millis, _ := res[1].(int64)
+	return uint32(id), time.Duration(millis) * time.Millisecond, true, nil
+}
+
+// RestoreToken возвращает забранный ConsumeToken токен с оставшимся сроком жизни, если
+// сброс пароля не удался. false — токен истёк или пользователю уже выдан новый.
+func (r *ResetTokenRepo) RestoreToken(ctx context.Context, userID uint32, tokenHash string, ttl time.Duration) (bool, error) {
+	if ttl <= 0 {
+		return false, nil
+	}
+	keys := []string{r.buildKey(tokenHash), r.buildUserKey(userID)}
+	restored, err := restoreScript.Run(ctx, r.Client, keys, tokenHash, userID, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return restored == 1, nil
+}
+
+// AllowRequest разрешает выдавать письмо со сбросом не чаще раза в interval.
+func (r *ResetTokenRepo) AllowRequest(ctx context.Context, userID uint32, interval time.Duration) (bool, error) {
+	return r.Client.SetNX(ctx, r.buildThrottleKey(userID), "1", interval).Result()
+}
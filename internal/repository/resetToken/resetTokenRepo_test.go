@@ -0,0 +1,97 @@
+package resetToken_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"registration-service/internal/repository/resetToken"
+)
+
+func setup(t *testing.T) (*resetToken.ResetTokenRepo, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	return resetToken.New(redis.NewClient(&redis.Options{Addr: mr.Addr()})), mr
+}
+
+func TestResetTokenRepo_SingleUse(t *testing.T) {
+	repo, _ := setup(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.SaveToken(ctx, 7, "hash-1", time.Minute))
+	userID, _, found, err := repo.ConsumeToken(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint32(7), userID)
+
+	_, _, found, err = repo.ConsumeToken(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.False(t, found, "token can be used only once")
+}
+
+func TestResetTokenRepo_RestoreKeepsRemainingTTL(t *testing.T) {
+	repo, mr := setup(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.SaveToken(ctx, 7, "hash-1", time.Minute))
+	mr.FastForward(20 * time.Second)
+	userID, ttl, found, err := repo.ConsumeToken(ctx, "hash-1")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.InDelta(t, 40*time.Second, ttl, float64(time.Second))
+
+	restored, err := repo.RestoreToken(ctx, userID, "hash-1", ttl)
+	require.NoError(t, err)
+	assert.True(t, restored)
+	mr.FastForward(41 * time.Second)
+	_, _, found, err = repo.ConsumeToken(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.False(t, found, "restored token does not outlive the original")
+}
+
+func TestResetTokenRepo_RestoreLosesToNewerToken(t *testing.T) {
+	repo, _ := setup(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.SaveToken(ctx, 7, "hash-1", time.Minute))
+	_, ttl, found, err := repo.ConsumeToken(ctx, "hash-1")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.NoError(t, repo.SaveToken(ctx, 7, "hash-2", time.Minute))
+
+	restored, err := repo.RestoreToken(ctx, 7, "hash-1", ttl)
+	require.NoError(t, err)
+	assert.False(t, restored)
+	_, _, found, err = repo.ConsumeToken(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestResetTokenRepo_NewTokenReplacesPrevious(t *testing.T) {
+	repo, _ := setup(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.SaveToken(ctx, 7, "hash-1", time.Minute))
+	require.NoError(t, repo.SaveToken(ctx, 7, "hash-2", time.Minute))
+
+	_, _, found, err := repo.ConsumeToken(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.False(t, found)
+	_, _, found, err = repo.ConsumeToken(ctx, "hash-2")
+	require.NoError(t, err)
+	assert.True(t, found)
+}
+
+func TestResetTokenRepo_Expires(t *testing.T) {
+	repo, mr := setup(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.SaveToken(ctx, 7, "hash-1", time.Minute))
+	mr.FastForward(2 * time.Minute)
+	_, _, found, err := repo.ConsumeToken(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
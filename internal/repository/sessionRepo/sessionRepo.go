@@ -0,0 +1,250 @@
+package sessionRepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"registration-service/internal/model/session"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var ErrNotFound = errors.New("session not found")
+
+// RotateResult — итог предъявления refresh-токена.
+type RotateResult int
+
+const (
+	// RotateInvalid — токен сессии неизвестен или сессии уже нет
+	RotateInvalid RotateResult = iota
+	// Rotated — токен был действующим и заменён новым
+	Rotated
+	// RotateReused — предъявлен уже заменённый токен; сессия удалена
+	RotateReused
+)
+
+// rotateScript заменяет хеш refresh-токена, если предъявлен действующий, и запоминает старый.
+// Повторное предъявление заменённого токена значит, что его копия у кого-то ещё: сессия вместе
+// со всеми её токенами удаляется.
+var rotateScript = redis.NewScript(`
+local current = redis.call('HGET', KEYS[1], 'token_hash')
+if not current then
+	return 0
+end
+if current == ARGV[1] then
+	redis.call('HSET', KEYS[1], 'token_hash', ARGV[2], 'last_used_at', ARGV[3])
+	redis.call('SADD', KEYS[2], ARGV[1])
+	redis.call('PEXPIRE', KEYS[1], ARGV[4])
+	redis.call('PEXPIRE', KEYS[2], ARGV[4])
+	redis.call('PEXPIRE', KEYS[3], ARGV[4])
+	return 1
+end
+if redis.call('SISMEMBER', KEYS[2], ARGV[1]) == 1 then
+	redis.call('DEL', KEYS[1], KEYS[2])
+	redis.call('SREM', KEYS[3], ARGV[5])
+	return 2
+end
+return 0
+`)
+
+// SessionRepo хранит сессию в хеше session:<id> со сроком жизни refresh-токена, хеши уже
+// заменённых refresh-токенов — в session:<id>:rotated, а ID сессий пользователя — в множестве
+// sessions:<userID>. Истёкшие ID вычищаются из множества при чтении.
+type SessionRepo struct {
+	Client *redis.Client
+}
+
+func New(client *redis.Client) *SessionRepo {
+	return &SessionRepo{Client: client}
+}
+
+func (r *SessionRepo) buildKey(sessionID string) string {
+	return fmt.Sprintf("session:%s", sessionID)
+}
+
+func (r *SessionRepo) buildRotatedKey(sessionID string) string {
+	return fmt.Sprintf("session:%s:rotated", sessionID)
+}
+
+func (r *SessionRepo) buildUserKey(userID uint32) string {
+	return fmt.Sprintf("sessions:%d", userID)
+}
+
+// Create сохраняет новую сессию вместе с хешем её refresh-токена.
+func (r *SessionRepo) Create(ctx context.Context, s *session.Session, tokenHash string, ttl time.Duration) error {
+	key := r.buildKey(s.ID)
+	userKey := r.buildUserKey(s.UserID)
+	pipe := r.Client.TxPipeline()
+	pipe.HSet(ctx, key,
+		"user_id", s.UserID,
+		"token_hash", tokenHash,
+		"device_name", s.DeviceName,
+		"user_agent", s.UserAgent,
+		"ip", s.IP,
+		"created_at", s.CreatedAt.UnixMilli(),
+		"last_used_at", s.LastUsedAt.UnixMilli(),
+	)
+	pipe.PExpire(ctx, key, ttl)
+	pipe.SAdd(ctx, userKey, s.ID)
+	pipe.PExpire(ctx, userKey, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Get возвращает сессию и хеш её refresh-токена.
+func (r *SessionRepo) Get(ctx context.Context, sessionID string) (*session.Session, string, error) {
+	fields, err := r.Client.HGetAll(ctx, r.buildKey(sessionID)).Result()
+	if err != nil {
+		return nil, "", err
+	}
+	s, err := parseSession(sessionID, fields)
+	if err != nil {
+		return nil, "", err
+	}
+	return s, fields["token_hash"], nil
+}
+
+func (r *SessionRepo) Exists(ctx context.Context, sessionID string) (bool, error) {
+	n, err := r.Client.Exists(ctx, r.buildKey(sessionID)).Result()
+	return n > 0, err
+}
+
+// Rotate заменяет refresh-токен сессии с хешем presentedHash на newHash и продлевает сессию.
+func (r *SessionRepo) Rotate(ctx context.Context, s *session.Session, presentedHash, newHash string, ttl time.Duration) (RotateResult, error) {
+	keys := []string{r.buildKey(s.ID), r.buildRotatedKey(s.ID), r.buildUserKey(s.UserID)}
+	now := time.Now().UnixMilli()
+	result, err := rotateScript.Run(ctx, r.Client, keys, presentedHash, newHash, now, ttl.Milliseconds(), s.ID).Int()
+	if err != nil {
+		return RotateInvalid, err
+	}
+	return RotateResult(result), nil
+}
+
+// List возвращает сессии пользователя, начиная с последней использованной.
+func (r *SessionRepo) List(ctx context.Context, userID uint32) ([]*session.Session, error) {
+	userKey := r.buildUserKey(userID)
+	ids, err := r.Client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	pipe := r.Client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = pipe.HGetAll(ctx, r.buildKey(id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	var sessions []*session.Session
+	var expired []interface{}
+	for i, cmd := range cmds {
+		s, err := parseSession(ids[i], cmd.Val())
+		if errors.Is(err, ErrNotFound) {
+			expired = append(expired, ids[i])
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	if len(expired) > 0 {
+		if err := r.Client.SRem(ctx, userKey, expired...).Err(); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt) })
+	return sessions, nil
+}
+
+// Delete удаляет сессию пользователя. false — такой сессии у пользователя нет.
+func (r *SessionRepo) Delete(ctx context.Context, userID uint32, sessionID string) (bool, error) {
+	s, _, err := r.Get(ctx, sessionID)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if s.UserID != userID {
+		return false, nil
+	}
+	pipe := r.Client.TxPipeline()
+	del := pipe.Del(ctx, r.buildKey(sessionID))
+	pipe.Del(ctx, r.buildRotatedKey(sessionID))
+	pipe.SRem(ctx, r.buildUserKey(userID), sessionID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	return del.Val() > 0, nil
+}
+
+// DeleteAll удаляет все сессии пользователя, кроме exceptID (пустая строка — все), и
+// возвращает ID удалённых.
+func (r *SessionRepo) DeleteAll(ctx context.Context, userID uint32, exceptID string) ([]string, error) {
+	userKey := r.buildUserKey(userID)
+	ids, err := r.Client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	var members []interface{}
+	for _, id := range ids {
+		if id != exceptID {
+			members = append(members, id)
+		}
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+	pipe := r.Client.TxPipeline()
+	dels := make(map[string]*redis.IntCmd, len(members))
+	for _, id := range ids {
+		if id == exceptID {
+			continue
+		}
+		dels[id] = pipe.Del(ctx, r.buildKey(id))
+		pipe.Del(ctx, r.buildRotatedKey(id))
+	}
+	pipe.SRem(ctx, userKey, members...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	var deleted []string
+	for id, del := range dels {
+		// ID истёкших сессий остаются в множестве до чтения, их не считаем
+		if del.Val() > 0 {
+			deleted = append(deleted, id)
+		}
+	}
+	return deleted, nil
+}
+
+func parseSession(sessionID string, fields map[string]string) (*session.Session, error) {
+	if len(fields) == 0 {
+		return nil, ErrNotFound
+	}
+	userID, err := strconv.ParseUint(fields["user_id"], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("session %s: bad user_id: %w", sessionID, err)
+	}
+	createdAt, err := strconv.ParseInt(fields["created_at"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("session %s: bad created_at: %w", sessionID, err)
+	}
+	lastUsedAt, err := strconv.ParseInt(fields["last_used_at"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("session %s: bad last_used_at: %w", sessionID, err)
+	}
+	return &session.Session{
+		ID:         sessionID,
+		UserID:     uint32(userID),
+		DeviceName: fields["device_name"],
+		UserAgent:  fields["user_agent"],
+		IP:         fields["ip"],
+		CreatedAt:  time.UnixMilli(createdAt),
+		LastUsedAt: time.UnixMilli(lastUsedAt),
+	}, nil
+}
@@ -0,0 +1,120 @@
+package sessionRepo_test
+
+import (
+	"context"
+	"registration-service/internal/model/session"
+	"registration-service/internal/repository/sessionRepo"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSession(id string, userID uint32, lastUsed time.Time) *session.Session {
+	return &session.Session{ID: id, UserID: userID, DeviceName: "phone-" + id, UserAgent: "grpc-go", IP: "10.0.0.1", CreatedAt: lastUsed, LastUsedAt: lastUsed}
+}
+
+func TestSessionRepo(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	repo := sessionRepo.New(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+
+	now := time.Now().Truncate(time.Millisecond)
+	require.NoError(t, repo.Create(ctx, newSession("a", 1, now.Add(-time.Hour)), "hash-a", time.Hour))
+	require.NoError(t, repo.Create(ctx, newSession("b", 1, now), "hash-b", time.Hour))
+	require.NoError(t, repo.Create(ctx, newSession("c", 2, now), "hash-c", time.Hour))
+
+	t.Run("Get", func(t *testing.T) {
+		s, hash, err := repo.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, newSession("a", 1, now.Add(-time.Hour)), s)
+		assert.Equal(t, "hash-a", hash)
+
+		_, _, err = repo.Get(ctx, "missing")
+		assert.ErrorIs(t, err, sessionRepo.ErrNotFound)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		sessions, err := repo.List(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, sessions, 2)
+		assert.Equal(t, "b", sessions[0].ID, "most recently used first")
+	})
+
+	t.Run("Rotate", func(t *testing.T) {
+		s, _, err := repo.Get(ctx, "a")
+		require.NoError(t, err)
+		result, err := repo.Rotate(ctx, s, "hash-a", "hash-a2", time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, sessionRepo.Rotated, result)
+
+		result, err = repo.Rotate(ctx, s, "never-issued", "hash-a3", time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, sessionRepo.RotateInvalid, result)
+
+		s, hash, err := repo.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, "hash-a2", hash)
+		assert.True(t, s.LastUsedAt.After(now.Add(-time.Hour)))
+	})
+
+	t.Run("Rotate detects reuse", func(t *testing.T) {
+		s := newSession("r", 4, now)
+		require.NoError(t, repo.Create(ctx, s, "hash-r1", time.Hour))
+		result, err := repo.Rotate(ctx, s, "hash-r1", "hash-r2", time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, sessionRepo.Rotated, result)
+
+		result, err = repo.Rotate(ctx, s, "hash-r1", "hash-r3", time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, sessionRepo.RotateReused, result)
+		assert.False(t, mr.Exists("session:r"))
+		assert.False(t, mr.Exists("session:r:rotated"))
+
+		result, err = repo.Rotate(ctx, s, "hash-r2", "hash-r4", time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, sessionRepo.RotateInvalid, result, "the newest token of the family is revoked too")
+	})
+
+	t.Run("Delete checks owner", func(t *testing.T) {
+		deleted, err := repo.Delete(ctx, 2, "a")
+		require.NoError(t, err)
+		assert.False(t, deleted)
+
+		deleted, err = repo.Delete(ctx, 1, "a")
+		require.NoError(t, err)
+		assert.True(t, deleted)
+		exists, err := repo.Exists(ctx, "a")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("DeleteAll", func(t *testing.T) {
+		require.NoError(t, repo.Create(ctx, newSession("d", 1, now), "hash-d", time.Hour))
+		revoked, err := repo.DeleteAll(ctx, 1, "d")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b"}, revoked)
+
+		sessions, err := repo.List(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, "d", sessions[0].ID)
+	})
+
+	t.Run("List drops expired sessions", func(t *testing.T) {
+		require.NoError(t, repo.Create(ctx, newSession("e", 3, now), "hash-e", time.Minute))
+		require.NoError(t, repo.Create(ctx, newSession("f", 3, now), "hash-f", time.Hour))
+		mr.FastForward(2 * time.Minute)
+
+		sessions, err := repo.List(ctx, 3)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, "f", sessions[0].ID)
+		members, err := mr.Members("sessions:3")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"f"}, members)
+	})
+}
@@ -0,0 +1,91 @@
+package signingKeyRepo
+
+import (
+	"context"
+	"errors"
+	"registration-service/internal/model/signingKey"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SigningKeyRepo struct {
+	conn *pgxpool.Pool
+}
+
+func New(conn *pgxpool.Pool) *SigningKeyRepo {
+	return &SigningKeyRepo{conn: conn}
+}
+
+// ListKeys возвращает действующий ключ и ключи, чей период перекрытия ещё не истёк, от новых к старым.
+func (r *SigningKeyRepo) ListKeys(ctx context.Context) ([]*signingKey.SigningKey, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT kid, algorithm, private_key, wrap_key_id, created_at, retire_at
+		 FROM signing_keys
+		 WHERE retire_at IS NULL OR retire_at > NOW()
+		 ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*signingKey.SigningKey
+	for rows.Next() {
+		var k signingKey.SigningKey
+		if err := rows.Scan(&k.ID, &k.Algorithm, &k.PrivateKey, &k.WrapKeyID, &k.CreatedAt, &k.RetireAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, &k)
+	}
+	return keys, rows.Err()
+}
+
+// RotateKey делает key действующим, если текущий действующий ключ создан не позже staleBefore
+// или ключа ещё нет. Прежний ключ публикуется ещё overlap. Экземпляры сервиса ротируют
+// параллельно, поэтому проверка и замена идут под advisory-блокировкой; false — ключ уже
+// сменил другой экземпляр.
+func (r *SigningKeyRepo) RotateKey(ctx context.Context, key *signingKey.SigningKey, staleBefore time.Time, overlap time.Duration) (bool, error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('signing_keys'))`); err != nil {
+		return false, err
+	}
+	var activeAlgorithm string
+	var activeCreatedAt time.Time
+	err = tx.QueryRow(ctx,
+		`SELECT algorithm, created_at FROM signing_keys WHERE retire_at IS NULL ORDER BY created_at DESC LIMIT 1`).
+		Scan(&activeAlgorithm, &activeCreatedAt)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+	case err != nil:
+		return false, err
+	case activeCreatedAt.After(staleBefore) && activeAlgorithm == key.Algorithm:
+		return false, nil
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE signing_keys SET retire_at = NOW() + $1 * INTERVAL '1 second' WHERE retire_at IS NULL`,
+		overlap.Seconds()); err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO signing_keys (kid, algorithm, private_key, wrap_key_id, created_at) VALUES ($1, $2, $3, $4, $5)`,
+		key.ID, key.Algorithm, key.PrivateKey, key.WrapKeyID, key.CreatedAt); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+// DeleteRetiredKeys удаляет ключи, период перекрытия которых истёк.
+func (r *SigningKeyRepo) DeleteRetiredKeys(ctx context.Context) (int64, error) {
+	tag, err := r.conn.Exec(ctx, `DELETE FROM signing_keys WHERE retire_at <= NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -2,20 +2,40 @@ package userRepo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"registration-service/internal/model/user"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// userColumns — столбцы users в порядке, который ожидают Scan в этом файле.
+const userColumns = `id, username, email, password_hash, email_verified_at IS NOT NULL, display_name, avatar_url,
+	totp_enabled_at IS NOT NULL, is_admin`
+
+// ErrConflict — имя пользователя или адрес уже заняты другим аккаунтом.
+var ErrConflict = errors.New("value is already taken")
+
 type UserRepo struct {
-	conn *pgx.Conn
+	conn *pgxpool.Pool
 }
 
-func New(conn *pgx.Conn) *UserRepo {
+func New(conn *pgxpool.Pool) *UserRepo {
 	return &UserRepo{conn: conn}
 }
 
+func scanUser(row pgx.Row) (*user.User, error) {
+	var u user.User
+	err := row.Scan(&u.ID, &u.Username, &u.Email, &u.Password, &u.EmailVerified, &u.DisplayName, &u.AvatarURL,
+		&u.TOTPEnabled, &u.IsAdmin)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
 func (r *UserRepo) Create(ctx context.Context, username, email, passwordHash string) (uint32, error) {
 	query := `INSERT INTO users (username, email, password_hash) VALUES ($1, $2, $3) RETURNING id`
 	var userID uint32
@@ -27,30 +47,28 @@ func (r *UserRepo) Create(ctx context.Context, username, email, passwordHash str
 }
 
 func (r *UserRepo) GetByID(ctx context.Context, id uint32) (*user.User, error) {
-	query := `SELECT id, username, email, password_hash FROM users WHERE id=$1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE id=$1`
 	row := r.conn.QueryRow(ctx, query, id)
 
-	var user user.User
-	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password)
+	user, err := scanUser(row)
 	if err != nil {
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
-	return &user, nil
+	return user, nil
 }
 
 func (r *UserRepo) GetUserByEmail(ctx context.Context, email string) (*user.User, error) {
-	query := `SELECT id, username, email, password_hash FROM users WHERE email=$1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE email=$1`
 	row := r.conn.QueryRow(ctx, query, email)
-	var user user.User
-	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password)
+	user, err := scanUser(row)
 	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+	return user, nil
 }
 
 func (r *UserRepo) GetByUsername(ctx context.Context, username string) ([]*user.User, error) {
-	query := `SELECT id, username, email, password_hash FROM users WHERE username=$1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE username=$1`
 	rows, err := r.conn.Query(ctx, query, username)
 	if err != nil {
 		return nil, err
@@ -59,11 +77,163 @@ func (r *UserRepo) GetByUsername(ctx context.Context, username string) ([]*user.
 
 	var users []*user.User
 	for rows.Next() {
-		var user user.User
-		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Password); err != nil {
+		user, err := scanUser(rows)
+		if err != nil {
 			return nil, err
 		}
-		users = append(users, &user)
+		users = append(users, user)
 	}
 	return users, nil
 }
+
+// MarkEmailVerified подтверждает адрес пользователя, если он всё ещё равен email: токен,
+// выданный на прежний адрес, не подтверждает новый. Возвращает false, если адрес сменился.
+func (r *UserRepo) MarkEmailVerified(ctx context.Context, id uint32, email string) (bool, error) {
+	tag, err := r.conn.Exec(ctx,
+		`UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW())
+		 WHERE id = $1 AND email = $2`,
+		id, email)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *UserRepo) UpdatePassword(ctx context.Context, id uint32, passwordHash string) error {
+	tag, err := r.conn.Exec(ctx, `UPDATE users SET password_hash = $1 WHERE id = $2`, passwordHash, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user %d not found", id)
+	}
+	return nil
+}
+
+// UpdateEmail меняет адрес и снимает его подтверждение: новый адрес подтверждается заново.
+func (r *UserRepo) UpdateEmail(ctx context.Context, id uint32, email string) error {
+	return r.update(ctx, id, `UPDATE users SET email = $1, email_verified_at = NULL WHERE id = $2`, email)
+}
+
+func (r *UserRepo) UpdateUsername(ctx context.Context, id uint32, username string) error {
+	return r.update(ctx, id, `UPDATE users SET username = $1 WHERE id = $2`, username)
+}
+
+func (r *UserRepo) UpdateProfile(ctx context.Context, id uint32, displayName, avatarURL string) error {
+	tag, err := r.conn.Exec(ctx,
+		`UPDATE users SET display_name = $1, avatar_url = $2 WHERE id = $3`,
+		displayName, avatarURL, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user %d not found", id)
+	}
+	return nil
+}
+
+// update выполняет UPDATE уникального столбца: нарушение уникальности превращается в ErrConflict.
+func (r *UserRepo) update(ctx context.Context, id uint32, query string, value string) error {
+	tag, err := r.conn.Exec(ctx, query, value, id)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrConflict
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user %d not found", id)
+	}
+	return nil
+}
+
+// SetPendingTOTPSecret сохраняет секрет, который ещё не подтверждён кодом: до ConfirmTOTP
+// вход по-прежнему выполняется только по паролю. Включённую 2FA так заменить нельзя.
+func (r *UserRepo) SetPendingTOTPSecret(ctx context.Context, id uint32, secret string) (bool, error) {
+	tag, err := r.conn.Exec(ctx,
+		`UPDATE users SET totp_secret = $1 WHERE id = $2 AND totp_enabled_at IS NULL`,
+		secret, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// GetTOTPSecret возвращает секрет пользователя и включена ли 2FA; пустой секрет — 2FA не настраивалась.
+func (r *UserRepo) GetTOTPSecret(ctx context.Context, id uint32) (string, bool, error) {
+	var secret *string
+	var enabled bool
+	err := r.conn.QueryRow(ctx,
+		`SELECT totp_secret, totp_enabled_at IS NOT NULL FROM users WHERE id = $1`, id).
+		Scan(&secret, &enabled)
+	if err != nil {
+		return "", false, err
+	}
+	if secret == nil {
+		return "", false, nil
+	}
+	return *secret, enabled, nil
+}
+
+// EnableTOTP одной транзакцией включает 2FA с подтверждённым секретом и заменяет коды
+// восстановления. false — секрет успели заменить или 2FA уже включена.
+func (r *UserRepo) EnableTOTP(ctx context.Context, id uint32, secret string, recoveryCodeHashes []string) (bool, error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx,
+		`UPDATE users SET totp_enabled_at = NOW()
+		 WHERE id = $1 AND totp_secret = $2 AND totp_enabled_at IS NULL`,
+		id, secret)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, id); err != nil {
+		return false, err
+	}
+	for _, hash := range recoveryCodeHashes {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, id, hash); err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit(ctx)
+}
+
+// ConsumeRecoveryCode удаляет код восстановления; true — код был и теперь использован.
+func (r *UserRepo) ConsumeRecoveryCode(ctx context.Context, id uint32, codeHash string) (bool, error) {
+	tag, err := r.conn.Exec(ctx,
+		`DELETE FROM mfa_recovery_codes WHERE user_id = $1 AND code_hash = $2`, id, codeHash)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// DisableTOTP выключает 2FA, удаляя секрет и оставшиеся коды восстановления.
+func (r *UserRepo) DisableTOTP(ctx context.Context, id uint32) error {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user %d not found", id)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,25 @@
+package verificationRepo
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+type VerificationRepo struct {
+	Client *redis.Client
+}
+
+func New(client *redis.Client) *VerificationRepo {
+	return &VerificationRepo{Client: client}
+}
+
+func (r *VerificationRepo) buildKey(userID uint32) string {
+	return fmt.Sprintf("verification:resend:%d", userID)
+}
+
+// AllowResend разрешает повторную отправку письма не чаще раза в interval.
+func (r *VerificationRepo) AllowResend(ctx context.Context, userID uint32, interval time.Duration) (bool, error) {
+	return r.Client.SetNX(ctx, r.buildKey(userID), "1", interval).Result()
+}
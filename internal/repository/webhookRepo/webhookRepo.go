@@ -0,0 +1,160 @@
+package webhookRepo
+
+import (
+	"context"
+	"errors"
+	"registration-service/internal/model/webhookInfo"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WebhookRepository struct {
+	conn *pgxpool.Pool
+}
+
+func New(db *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{conn: db}
+}
+
+func (r *WebhookRepository) CreateWebhook(ctx context.Context, hook *webhookInfo.Webhook) error {
+	_, err := r.conn.Exec(ctx,
+		`INSERT INTO webhooks (id, owner_id, url, secret, event_types, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		hook.ID, hook.OwnerID, hook.URL, hook.Secret, hook.EventTypes, hook.CreatedAt)
+	return err
+}
+
+func (r *WebhookRepository) ListWebhooksByOwner(ctx context.Context, ownerID uint32) ([]*webhookInfo.Webhook, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT id, owner_id, url, event_types, created_at
+		 FROM webhooks WHERE owner_id = $1
+		 ORDER BY created_at`, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hooks []*webhookInfo.Webhook
+	for rows.Next() {
+		var hook webhookInfo.Webhook
+		if err := rows.Scan(&hook.ID, &hook.OwnerID, &hook.URL, &hook.EventTypes, &hook.CreatedAt); err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, &hook)
+	}
+	return hooks, rows.Err()
+}
+
+// EnqueueEvent кладёт событие в outbox для всех вебхуков пользователей, подписанных на eventType.
+func (r *WebhookRepository) EnqueueEvent(ctx context.Context, userIDs []uint32, eventType string, payload []byte) error {
+	_, err := r.conn.Exec(ctx,
+		`INSERT INTO webhook_deliveries (id, webhook_id, event_type, payload, status, attempts, next_attempt_at, created_at)
+		 SELECT gen_random_uuid(), w.id, $2, $3, $4, 0, NOW(), NOW()
+		 FROM webhooks w
+		 WHERE w.owner_id = ANY($1) AND $2 = ANY(w.event_types)`,
+		userIDs, eventType, payload, webhookInfo.DeliveryPending)
+	return err
+}
+
+// ClaimDueDeliveries выбирает доставки, время которых подошло, и сдвигает их next_attempt_at на lease,
+// чтобы другой экземпляр сервиса не отправил их параллельно.
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*webhookInfo.Delivery, error) {
+	rows, err := r.conn.Query(ctx,
+		`UPDATE webhook_deliveries d
+		 SET next_attempt_at = NOW() + make_interval(secs => $3)
+		 FROM webhooks w
+		 WHERE d.webhook_id = w.id AND d.id IN (
+		     SELECT id FROM webhook_deliveries
+		     WHERE status = $1 AND next_attempt_at <= NOW()
+		     ORDER BY next_attempt_at
+		     LIMIT $2
+		     FOR UPDATE SKIP LOCKED
+		 )
+		 RETURNING d.id, d.webhook_id, d.event_type, d.payload, d.status, d.attempts, d.created_at, w.url, w.secret`,
+		webhookInfo.DeliveryPending, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*webhookInfo.Delivery
+	for rows.Next() {
+		var d webhookInfo.Delivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventType, &d.Payload, &d.Status, &d.Attempts, &d.CreatedAt, &d.URL, &d.Secret); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, deliveryID uuid.UUID, attempts int) error {
+	_, err := r.conn.Exec(ctx,
+		`UPDATE webhook_deliveries
+		 SET status = $1, attempts = $2, last_error = '', delivered_at = NOW()
+		 WHERE id = $3`,
+		webhookInfo.DeliveryDelivered, attempts, deliveryID)
+	return err
+}
+
+func (r *WebhookRepository) MarkRetry(ctx context.Context, deliveryID uuid.UUID, attempts int, nextAttemptAt time.Time, lastError string) error {
+	_, err := r.conn.Exec(ctx,
+		`UPDATE webhook_deliveries
+		 SET attempts = $1, next_attempt_at = $2, last_error = $3
+		 WHERE id = $4`,
+		attempts, nextAttemptAt, lastError, deliveryID)
+	return err
+}
+
+func (r *WebhookRepository) MarkDead(ctx context.Context, deliveryID uuid.UUID, attempts int, lastError string) error {
+	_, err := r.conn.Exec(ctx,
+		`UPDATE webhook_deliveries
+		 SET status = $1, attempts = $2, last_error = $3
+		 WHERE id = $4`,
+		webhookInfo.DeliveryDead, attempts, lastError, deliveryID)
+	return err
+}
+
+// ListDeadDeliveries возвращает dead-letter список владельца; webhookID == uuid.Nil — по всем его вебхукам.
+func (r *WebhookRepository) ListDeadDeliveries(ctx context.Context, ownerID uint32, webhookID uuid.UUID) ([]*webhookInfo.Delivery, error) {
+	rows, err := r.conn.Query(ctx,
+		`SELECT d.id, d.webhook_id, d.event_type, d.status, d.attempts, d.last_error, d.created_at
+		 FROM webhook_deliveries d
+		 JOIN webhooks w ON w.id = d.webhook_id
+		 WHERE w.owner_id = $1 AND d.status = $2 AND ($3 = '00000000-0000-0000-0000-000000000000'::uuid OR d.webhook_id = $3)
+		 ORDER BY d.created_at DESC`,
+		ownerID, webhookInfo.DeliveryDead, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*webhookInfo.Delivery
+	for rows.Next() {
+		var d webhookInfo.Delivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventType, &d.Status, &d.Attempts, &d.LastError, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
+
+// Redeliver возвращает доставку владельца в очередь. Возвращает false, если такой доставки нет.
+func (r *WebhookRepository) Redeliver(ctx context.Context, ownerID uint32, deliveryID uuid.UUID) (bool, error) {
+	var id uuid.UUID
+	err := r.conn.QueryRow(ctx,
+		`UPDATE webhook_deliveries d
+		 SET status = $1, attempts = 0, next_attempt_at = NOW(), last_error = ''
+		 FROM webhooks w
+		 WHERE d.webhook_id = w.id AND w.owner_id = $2 AND d.id = $3
+		 RETURNING d.id`,
+		webhookInfo.DeliveryPending, ownerID, deliveryID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
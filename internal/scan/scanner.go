@@ -0,0 +1,116 @@
+package scan
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+var ErrScanFailed = errors.New("scan failed")
+
+type Result struct {
+	Infected bool
+	// Threat — название сигнатуры, если найдена угроза
+	Threat string
+}
+
+// Scanner проверяет содержимое версии. Ошибка означает, что проверка не состоялась
+// (сканер недоступен, превышен лимит), а не то, что файл заражён.
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (Result, error)
+}
+
+// NopScanner считает чистым любое содержимое. Используется, когда сканер не настроен.
+type NopScanner struct{}
+
+func (NopScanner) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	return Result{}, nil
+}
+
+const defaultChunkSize = 64 * 1024
+
+// ClamdScanner отправляет содержимое в clamd командой INSTREAM: поток чанков с 4-байтовой
+// длиной в network order, завершённый чанком нулевой длины.
+type ClamdScanner struct {
+	addr      string
+	timeout   time.Duration
+	chunkSize int
+}
+
+func NewClamdScanner(addr string, timeout time.Duration) *ClamdScanner {
+	return &ClamdScanner{addr: addr, timeout: timeout, chunkSize: defaultChunkSize}
+}
+
+func (c *ClamdScanner) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	dialer := net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: failed to connect to clamd: %v", ErrScanFailed, err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(c.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return Result{}, err
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrScanFailed, err)
+	}
+	if err := c.stream(conn, r); err != nil {
+		return Result{}, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !(errors.Is(err, io.EOF) && reply != "") {
+		return Result{}, fmt.Errorf("%w: failed to read clamd reply: %v", ErrScanFailed, err)
+	}
+	return parseReply(reply)
+}
+
+func (c *ClamdScanner) stream(conn net.Conn, r io.Reader) error {
+	buf := make([]byte, 4+c.chunkSize)
+	for {
+		n, readErr := io.ReadFull(r, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf[:4], uint32(n))
+			if _, err := conn.Write(buf[:4+n]); err != nil {
+				// clamd закрывает соединение, когда поток превышает StreamMaxLength;
+				// причина придёт в ответе, поэтому ошибка записи здесь не окончательная
+				return nil
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return fmt.Errorf("failed to read content: %w", readErr)
+		}
+	}
+	// ошибка записи завершающего чанка не проверяется по той же причине
+	_, _ = conn.Write([]byte{0, 0, 0, 0})
+	return nil
+}
+
+// parseReply разбирает ответы вида "stream: OK", "stream: Eicar-Signature FOUND"
+// и "INSTREAM size limit exceeded. ERROR".
+func parseReply(reply string) (Result, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	status := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case status == "OK":
+		return Result{}, nil
+	case strings.HasSuffix(status, " FOUND"):
+		return Result{Infected: true, Threat: strings.TrimSuffix(status, " FOUND")}, nil
+	}
+	return Result{}, fmt.Errorf("%w: clamd replied %q", ErrScanFailed, reply)
+}
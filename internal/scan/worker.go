@@ -0,0 +1,149 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"registration-service/internal/model/fileInfo"
+	"time"
+)
+
+type Config struct {
+	// ClamdAddr — адрес clamd (host:port); пустое значение отключает проверку
+	ClamdAddr        string        `env:"SCAN_CLAMD_ADDR"`
+	Timeout          time.Duration `env:"SCAN_TIMEOUT" env-default:"5m"`
+	QuarantinePrefix string        `env:"SCAN_QUARANTINE_PREFIX" env-default:"quarantine/"`
+	QueueSize        int           `env:"SCAN_QUEUE_SIZE" env-default:"256"`
+	BackfillInterval time.Duration `env:"SCAN_BACKFILL_INTERVAL" env-default:"30s"`
+	BackfillBatch    int           `env:"SCAN_BACKFILL_BATCH" env-default:"100"`
+}
+
+// NewScanner возвращает clamd-сканер или NopScanner, если адрес не задан.
+func NewScanner(cfg Config) Scanner {
+	if cfg.ClamdAddr == "" {
+		return NopScanner{}
+	}
+	return NewClamdScanner(cfg.ClamdAddr, cfg.Timeout)
+}
+
+// Repository — часть fileRepo.FileRepository, которая нужна воркеру.
+type Repository interface {
+	GetFileVersionByID(ctx context.Context, versionID uint32) (*fileInfo.FileVersion, error)
+	ListPendingScanVersions(ctx context.Context, limit int) ([]uint32, error)
+	MarkVersionClean(ctx context.Context, versionID uint32) (bool, error)
+	MarkVersionInfected(ctx context.Context, versionID uint32, threat, quarantineKey string) error
+}
+
+// ObjectStore — часть versionStore.VersionStore, которая нужна воркеру.
+type ObjectStore interface {
+	Open(ctx context.Context, version *fileInfo.FileVersion) (io.ReadCloser, error)
+	Move(ctx context.Context, fromKey, toKey string) error
+}
+
+// Worker проверяет новые версии и переводит их из pending_scan в clean или infected.
+// Как и индексатор, он получает задачи через Enqueue и подбирает потерянные backfill'ом;
+// версия, проверка которой не удалась, остаётся в pending_scan до следующего прохода.
+type Worker struct {
+	repo    Repository
+	store   ObjectStore
+	scanner Scanner
+	cfg     Config
+	onClean func(*fileInfo.FileVersion)
+	jobs    chan uint32
+}
+
+// NewWorker создаёт воркер; onClean вызывается для каждой версии, ставшей чистой.
+func NewWorker(repo Repository, store ObjectStore, scanner Scanner, cfg Config, onClean func(*fileInfo.FileVersion)) *Worker {
+	return &Worker{
+		repo:    repo,
+		store:   store,
+		scanner: scanner,
+		cfg:     cfg,
+		onClean: onClean,
+		jobs:    make(chan uint32, cfg.QueueSize),
+	}
+}
+
+func (w *Worker) Enqueue(version *fileInfo.FileVersion) {
+	select {
+	case w.jobs <- version.ID:
+	default:
+		log.Printf("[scan.Worker] queue is full, version %s left for backfill", version.StorageKey)
+	}
+}
+
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.BackfillInterval)
+	defer ticker.Stop()
+	w.backfill(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case versionID := <-w.jobs:
+			if err := w.ScanVersion(ctx, versionID); err != nil {
+				log.Printf("[scan.Worker] failed to scan version %d: %v", versionID, err)
+			}
+		case <-ticker.C:
+			w.backfill(ctx)
+		}
+	}
+}
+
+func (w *Worker) backfill(ctx context.Context) {
+	ids, err := w.repo.ListPendingScanVersions(ctx, w.cfg.BackfillBatch)
+	if err != nil {
+		log.Printf("[scan.Worker] backfill query failed: %v", err)
+		return
+	}
+	for _, versionID := range ids {
+		if err := w.ScanVersion(ctx, versionID); err != nil {
+			log.Printf("[scan.Worker] failed to scan version %d: %v", versionID, err)
+		}
+	}
+}
+
+// ScanVersion проверяет одну версию. Заражённый объект переносится под QuarantinePrefix.
+func (w *Worker) ScanVersion(ctx context.Context, versionID uint32) error {
+	version, err := w.repo.GetFileVersionByID(ctx, versionID)
+	if err != nil {
+		return fmt.Errorf("failed to get version: %w", err)
+	}
+	if version == nil || version.ScanStatus != fileInfo.ScanPending {
+		// версию удалили или уже обработал другой экземпляр
+		return nil
+	}
+
+	reader, err := w.store.Open(ctx, version)
+	if err != nil {
+		return fmt.Errorf("failed to read object: %w", err)
+	}
+	result, err := w.scanner.Scan(ctx, reader)
+	reader.Close()
+	if err != nil {
+		return err
+	}
+
+	if result.Infected {
+		quarantineKey := w.cfg.QuarantinePrefix + version.StorageKey
+		if err := w.store.Move(ctx, version.StorageKey, quarantineKey); err != nil {
+			return fmt.Errorf("failed to quarantine object: %w", err)
+		}
+		if err := w.repo.MarkVersionInfected(ctx, version.ID, result.Threat, quarantineKey); err != nil {
+			return fmt.Errorf("failed to mark version infected: %w", err)
+		}
+		log.Printf("[scan.Worker] version %s is infected (%s), moved to %s", version.StorageKey, result.Threat, quarantineKey)
+		return nil
+	}
+
+	cleaned, err := w.repo.MarkVersionClean(ctx, version.ID)
+	if err != nil {
+		return fmt.Errorf("failed to mark version clean: %w", err)
+	}
+	if cleaned && w.onClean != nil {
+		version.ScanStatus = fileInfo.ScanClean
+		w.onClean(version)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package scan_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"registration-service/internal/model/fileInfo"
+	"registration-service/internal/scan"
+)
+
+type fakeRepo struct {
+	versions map[uint32]*fileInfo.FileVersion
+	current  map[string]uint32
+}
+
+func (r *fakeRepo) GetFileVersionByID(ctx context.Context, versionID uint32) (*fileInfo.FileVersion, error) {
+	v, ok := r.versions[versionID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *v
+	return &copied, nil
+}
+
+func (r *fakeRepo) ListPendingScanVersions(ctx context.Context, limit int) ([]uint32, error) {
+	var ids []uint32
+	for id, v := range r.versions {
+		if v.ScanStatus == fileInfo.ScanPending {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (r *fakeRepo) MarkVersionClean(ctx context.Context, versionID uint32) (bool, error) {
+	v := r.versions[versionID]
+	if v.ScanStatus != fileInfo.ScanPending {
+		return false, nil
+	}
+	v.ScanStatus = fileInfo.ScanClean
+	if r.current[v.FileID.String()] < v.VersionNumber {
+		r.current[v.FileID.String()] = v.VersionNumber
+	}
+	return true, nil
+}
+
+func (r *fakeRepo) MarkVersionInfected(ctx context.Context, versionID uint32, threat, quarantineKey string) error {
+	v := r.versions[versionID]
+	v.ScanStatus, v.ScanResult, v.StorageKey = fileInfo.ScanInfected, threat, quarantineKey
+	return nil
+}
+
+type fakeObjects map[string]string
+
+func (o fakeObjects) Open(ctx context.Context, version *fileInfo.FileVersion) (io.ReadCloser, error) {
+	content, ok := o[version.StorageKey]
+	if !ok {
+		return nil, errors.New("no such key")
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+func (o fakeObjects) Move(ctx context.Context, fromKey, toKey string) error {
+	o[toKey] = o[fromKey]
+	delete(o, fromKey)
+	return nil
+}
+
+// fakeScanner считает заражённым содержимое со словом "virus"
+type fakeScanner struct{ err error }
+
+func (s fakeScanner) Scan(ctx context.Context, r io.Reader) (scan.Result, error) {
+	if s.err != nil {
+		return scan.Result{}, s.err
+	}
+	data, _ := io.ReadAll(r)
+	if strings.Contains(string(data), "virus") {
+		return scan.Result{Infected: true, Threat: "Test.Virus"}, nil
+	}
+	return scan.Result{}, nil
+}
+
+func newFixture() (*fakeRepo, fakeObjects) {
+	repo := &fakeRepo{
+		versions: map[uint32]*fileInfo.FileVersion{
+			1: {ID: 1, VersionNumber: 1, StorageKey: "f/v1", ScanStatus: fileInfo.ScanPending},
+			2: {ID: 2, VersionNumber: 2, StorageKey: "f/v2", ScanStatus: fileInfo.ScanPending},
+		},
+		current: map[string]uint32{},
+	}
+	objects := fakeObjects{"f/v1": "plain report", "f/v2": "a virus inside"}
+	return repo, objects
+}
+
+func testConfig() scan.Config {
+	return scan.Config{QuarantinePrefix: "quarantine/", QueueSize: 4, BackfillBatch: 10}
+}
+
+func TestWorker_PromotesCleanAndQuarantinesInfected(t *testing.T) {
+	repo, objects := newFixture()
+	var cleaned []uint32
+	w := scan.NewWorker(repo, objects, fakeScanner{}, testConfig(), func(v *fileInfo.FileVersion) {
+		cleaned = append(cleaned, v.ID)
+	})
+	ctx := context.Background()
+
+	require.NoError(t, w.ScanVersion(ctx, 1))
+	require.NoError(t, w.ScanVersion(ctx, 2))
+
+	assert.Equal(t, fileInfo.ScanClean, repo.versions[1].ScanStatus)
+	assert.Equal(t, []uint32{1}, cleaned)
+	assert.Equal(t, uint32(1), repo.current[repo.versions[1].FileID.String()], "infected v2 must not become current")
+
+	infected := repo.versions[2]
+	assert.Equal(t, fileInfo.ScanInfected, infected.ScanStatus)
+	assert.Equal(t, "Test.Virus", infected.ScanResult)
+	assert.Equal(t, "quarantine/f/v2", infected.StorageKey)
+	assert.NotContains(t, objects, "f/v2")
+	assert.Contains(t, objects, "quarantine/f/v2")
+
+	// повторная обработка ничего не меняет
+	require.NoError(t, w.ScanVersion(ctx, 1))
+	assert.Equal(t, []uint32{1}, cleaned)
+}
+
+func TestWorker_LeavesVersionPendingWhenScannerFails(t *testing.T) {
+	repo, objects := newFixture()
+	w := scan.NewWorker(repo, objects, fakeScanner{err: scan.ErrScanFailed}, testConfig(), nil)
+
+	err := w.ScanVersion(context.Background(), 1)
+	assert.ErrorIs(t, err, scan.ErrScanFailed)
+	assert.Equal(t, fileInfo.ScanPending, repo.versions[1].ScanStatus)
+	assert.Empty(t, repo.current)
+}
@@ -0,0 +1,40 @@
+package search
+
+import (
+	"io"
+	"mime"
+	"strings"
+)
+
+// IsTextContent сообщает, есть ли смысл извлекать текст из содержимого такого типа.
+func IsTextContent(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	if strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript",
+		"application/x-yaml", "application/yaml", "application/csv", "application/x-sh",
+		"application/sql", "application/toml":
+		return true
+	}
+	return false
+}
+
+// ExtractText читает не больше limit байт и приводит их к тексту, который примет Postgres:
+// без NUL-символов и невалидных UTF-8 последовательностей.
+func ExtractText(r io.Reader, limit int64) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit))
+	if err != nil {
+		return "", err
+	}
+	// ToValidUTF8 заодно убирает символ, разорванный обрезкой по лимиту
+	text := strings.ToValidUTF8(string(data), "")
+	return strings.ReplaceAll(text, "\x00", " "), nil
+}
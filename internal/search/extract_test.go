@@ -0,0 +1,36 @@
+package search_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"registration-service/internal/search"
+)
+
+func TestIsTextContent(t *testing.T) {
+	assert.True(t, search.IsTextContent("text/plain"))
+	assert.True(t, search.IsTextContent("text/csv; charset=utf-8"))
+	assert.True(t, search.IsTextContent("application/json"))
+	assert.True(t, search.IsTextContent("application/ld+json"))
+	assert.True(t, search.IsTextContent("APPLICATION/XML"))
+
+	assert.False(t, search.IsTextContent("image/png"))
+	assert.False(t, search.IsTextContent("application/octet-stream"))
+	assert.False(t, search.IsTextContent(""))
+}
+
+func TestExtractText(t *testing.T) {
+	t.Run("Strips NUL and invalid UTF-8", func(t *testing.T) {
+		text, err := search.ExtractText(strings.NewReader("abc\x00def\xff"), 1024)
+		assert.NoError(t, err)
+		assert.Equal(t, "abc def", text)
+	})
+
+	t.Run("Respects limit without breaking runes", func(t *testing.T) {
+		// "привет" — по 2 байта на символ, 5 байт обрезают третий символ
+		text, err := search.ExtractText(strings.NewReader("привет"), 5)
+		assert.NoError(t, err)
+		assert.Equal(t, "пр", text)
+	})
+}
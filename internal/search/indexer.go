@@ -0,0 +1,110 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"registration-service/internal/repository/fileRepo"
+	"registration-service/internal/versionStore"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Config struct {
+	QueueSize        int           `env:"SEARCH_QUEUE_SIZE" env-default:"256"`
+	MaxContentBytes  int64         `env:"SEARCH_MAX_CONTENT_BYTES" env-default:"1048576"`
+	BackfillInterval time.Duration `env:"SEARCH_BACKFILL_INTERVAL" env-default:"1m"`
+	BackfillBatch    int           `env:"SEARCH_BACKFILL_BATCH" env-default:"100"`
+}
+
+// Indexer строит полнотекстовый индекс в фоне. Задачи приходят через Enqueue после загрузки
+// или новой версии; периодический backfill подбирает всё, что потерялось из-за переполнения
+// очереди или рестарта, так что состояние индекса всегда восстанавливается по БД.
+type Indexer struct {
+	fileRepo *fileRepo.FileRepository
+	store    *versionStore.VersionStore
+	cfg      Config
+	jobs     chan uuid.UUID
+}
+
+func NewIndexer(fileRepo *fileRepo.FileRepository, store *versionStore.VersionStore, cfg Config) *Indexer {
+	return &Indexer{
+		fileRepo: fileRepo,
+		store:    store,
+		cfg:      cfg,
+		jobs:     make(chan uuid.UUID, cfg.QueueSize),
+	}
+}
+
+// Enqueue не блокирует вызывающего: если очередь полна, файл будет проиндексирован backfill'ом.
+func (i *Indexer) Enqueue(fileID uuid.UUID) {
+	select {
+	case i.jobs <- fileID:
+	default:
+		log.Printf("[search.Indexer] queue is full, file %s left for backfill", fileID)
+	}
+}
+
+func (i *Indexer) Run(ctx context.Context) {
+	ticker := time.NewTicker(i.cfg.BackfillInterval)
+	defer ticker.Stop()
+	i.backfill(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case fileID := <-i.jobs:
+			if err := i.IndexFile(ctx, fileID); err != nil {
+				log.Printf("[search.Indexer] failed to index file %s: %v", fileID, err)
+			}
+		case <-ticker.C:
+			i.backfill(ctx)
+		}
+	}
+}
+
+func (i *Indexer) backfill(ctx context.Context) {
+	ids, err := i.fileRepo.ListFilesNeedingIndex(ctx, i.cfg.BackfillBatch)
+	if err != nil {
+		log.Printf("[search.Indexer] backfill query failed: %v", err)
+		return
+	}
+	for _, fileID := range ids {
+		if err := i.IndexFile(ctx, fileID); err != nil {
+			log.Printf("[search.Indexer] failed to index file %s: %v", fileID, err)
+		}
+	}
+}
+
+// IndexFile индексирует текущую версию файла. Для нетекстовых типов сохраняется пустой
+// документ, чтобы файл находился по имени и не попадал в backfill повторно. Текст зашифрованных
+// версий в БД не сохраняется, только tsvector: иначе шифрование хранилища теряет смысл.
+func (i *Indexer) IndexFile(ctx context.Context, fileID uuid.UUID) error {
+	version, err := i.fileRepo.GetCurrentFileVersion(ctx, fileID)
+	if err != nil {
+		return fmt.Errorf("failed to get current version: %w", err)
+	}
+	if version == nil {
+		// файл успели удалить или его версия ещё не проверена сканером
+		return nil
+	}
+
+	var content string
+	if IsTextContent(version.ContentType) {
+		reader, err := i.store.Open(ctx, version)
+		if err != nil {
+			return fmt.Errorf("failed to read object: %w", err)
+		}
+		content, err = ExtractText(reader, i.cfg.MaxContentBytes)
+		reader.Close()
+		if err != nil {
+			return fmt.Errorf("failed to extract text: %w", err)
+		}
+	}
+
+	if err := i.fileRepo.UpsertSearchDocument(ctx, fileID, version.VersionNumber, content, len(version.WrappedKey) == 0); err != nil {
+		return fmt.Errorf("failed to save search document: %w", err)
+	}
+	return nil
+}
@@ -4,12 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"regexp"
+	"registration-service/internal/config"
+	"registration-service/internal/jwtkeys"
+	"registration-service/internal/mail"
 	"registration-service/internal/model/user"
 	"registration-service/internal/repository/BlackListRepo"
+	"registration-service/internal/repository/loginAttemptRepo"
+	"registration-service/internal/repository/mfaRepo"
 	"registration-service/internal/repository/refreshToken"
-	"registration-service/internal/repository/userRepo"
+	"registration-service/internal/repository/resetToken"
+	"registration-service/internal/repository/sessionRepo"
+	"registration-service/internal/repository/verificationRepo"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -25,33 +34,82 @@ const (
 	jwtTokenExpireTime     = 3 * time.Hour
 )
 
+// UserRepository — часть userRepo.UserRepo, которая нужна сервису.
+type UserRepository interface {
+	Create(ctx context.Context, username, email, passwordHash string) (uint32, error)
+	GetByID(ctx context.Context, id uint32) (*user.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*user.User, error)
+	GetByUsername(ctx context.Context, username string) ([]*user.User, error)
+	MarkEmailVerified(ctx context.Context, id uint32, email string) (bool, error)
+	UpdatePassword(ctx context.Context, id uint32, passwordHash string) error
+	UpdateEmail(ctx context.Context, id uint32, email string) error
+	UpdateUsername(ctx context.Context, id uint32, username string) error
+	UpdateProfile(ctx context.Context, id uint32, displayName, avatarURL string) error
+	SetPendingTOTPSecret(ctx context.Context, id uint32, secret string) (bool, error)
+	GetTOTPSecret(ctx context.Context, id uint32) (string, bool, error)
+	EnableTOTP(ctx context.Context, id uint32, secret string, recoveryCodeHashes []string) (bool, error)
+	ConsumeRecoveryCode(ctx context.Context, id uint32, codeHash string) (bool, error)
+	DisableTOTP(ctx context.Context, id uint32) error
+}
+
 type AuthService struct {
-	userRepo      *userRepo.UserRepo
-	jwtSecretKey  string
-	refreshRepo   *refreshToken.RefreshTokenRepo
-	blacklistRepo *BlackListRepo.BlackListRepo
+	userRepo         UserRepository
+	jwtSecretKey     string
+	keys             *jwtkeys.KeyRing
+	refreshRepo      *refreshToken.RefreshTokenRepo
+	sessionRepo      *sessionRepo.SessionRepo
+	blacklistRepo    *BlackListRepo.BlackListRepo
+	verificationRepo *verificationRepo.VerificationRepo
+	resetRepo        *resetToken.ResetTokenRepo
+	mfaRepo          *mfaRepo.MFARepo
+	loginAttemptRepo *loginAttemptRepo.LoginAttemptRepo
+	mailer           mail.Mailer
+	verification     config.VerificationConfig
+	passwordReset    config.PasswordResetConfig
+	mfa              config.MFAConfig
+	loginThrottle    config.LoginThrottleConfig
 }
 
-func New(userRepo *userRepo.UserRepo, jwtString string, tokenRepo *refreshToken.RefreshTokenRepo, blacklistrepo *BlackListRepo.BlackListRepo) *AuthService {
-	return &AuthService{userRepo: userRepo, jwtSecretKey: jwtString, refreshRepo: tokenRepo, blacklistRepo: blacklistrepo}
+func New(userRepo UserRepository, jwtString string, keys *jwtkeys.KeyRing, tokenRepo *refreshToken.RefreshTokenRepo, sessionRepo *sessionRepo.SessionRepo, blacklistrepo *BlackListRepo.BlackListRepo, verificationRepo *verificationRepo.VerificationRepo, resetRepo *resetToken.ResetTokenRepo, mfaRepo *mfaRepo.MFARepo, loginAttemptRepo *loginAttemptRepo.LoginAttemptRepo, mailer mail.Mailer, verification config.VerificationConfig, passwordReset config.PasswordResetConfig, mfa config.MFAConfig, loginThrottle config.LoginThrottleConfig) *AuthService {
+	return &AuthService{
+		userRepo:         userRepo,
+		jwtSecretKey:     jwtString,
+		keys:             keys,
+		refreshRepo:      tokenRepo,
+		sessionRepo:      sessionRepo,
+		blacklistRepo:    blacklistrepo,
+		verificationRepo: verificationRepo,
+		resetRepo:        resetRepo,
+		mfaRepo:          mfaRepo,
+		loginAttemptRepo: loginAttemptRepo,
+		mailer:           mailer,
+		verification:     verification,
+		passwordReset:    passwordReset,
+		mfa:              mfa,
+		loginThrottle:    loginThrottle,
+	}
 }
 
 func (s *AuthService) Register(ctx context.Context, username, email, password string) (uint32, error) {
-	if username == "" || email == "" || password == "" {
-		return 0, fmt.Errorf("invalid format")
+	username = strings.TrimSpace(username)
+	if email == "" || password == "" {
+		return 0, fmt.Errorf("%w: email and password are required", ErrInvalidArgument)
+	}
+	if err := validateUsername(username); err != nil {
+		return 0, err
 	}
 
 	if !emailRegex.MatchString(email) {
-		return 0, fmt.Errorf("invalid email format")
+		return 0, fmt.Errorf("%w: invalid email format", ErrInvalidArgument)
 	}
 
 	if existingUser, err := s.userRepo.GetUserByEmail(ctx, email); err == nil && existingUser != nil {
-		return 0, fmt.Errorf("email already exists")
+		return 0, ErrEmailTaken
 	}
 
 	usersWithSameUsername, _ := s.userRepo.GetByUsername(ctx, username)
 	if len(usersWithSameUsername) > 0 {
-		return 0, fmt.Errorf("username already taken")
+		return 0, ErrUsernameTaken
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -63,13 +121,34 @@ func (s *AuthService) Register(ctx context.Context, username, email, password st
 	if err != nil {
 		return 0, fmt.Errorf("failed to create user: %w", err)
 	}
+	// аккаунт уже создан; если письмо не ушло, его можно запросить через ResendVerification
+	if err := s.sendVerification(ctx, userID, email); err != nil {
+		log.Printf("[AuthService.Register] failed to send verification to user %d: %v", userID, err)
+	}
 	return userID, nil
 }
 
-func (s *AuthService) Login(ctx context.Context, username, password string) (string, string, uint32, error) {
+// Login проверяет пароль и открывает новую сессию для устройства client; сессии на других
+// устройствах не затрагиваются. Если у пользователя включена 2FA, токены не выдаются: вместо них
+// возвращается mfa-токен, с которым вход завершает VerifyMFA. Неудачные попытки замедляют и
+// затем блокируют вход, см. config.LoginThrottleConfig.
+func (s *AuthService) Login(ctx context.Context, username, password string, client ClientInfo) (string, string, string, uint32, error) {
 	users, err := s.userRepo.GetByUsername(ctx, username)
-	if err != nil || users == nil {
-		return "", "", 0, errors.New("user not found")
+	if err != nil {
+		return "", "", "", 0, errors.New("user not found")
+	}
+	// имя уникально, так что пользователь не больше одного
+	var known *user.User
+	if len(users) > 0 {
+		known = users[0]
+	}
+	account := loginAccountKey(known, username)
+	if err := s.checkLoginAllowed(ctx, account, client); err != nil {
+		return "", "", "", 0, err
+	}
+	if known == nil {
+		s.recordLoginFailure(ctx, account, client)
+		return "", "", "", 0, errors.New("user not found")
 	}
 
 	var matchedUser *user.User
@@ -81,120 +160,226 @@ func (s *AuthService) Login(ctx context.Context, username, password string) (str
 	}
 
 	if matchedUser == nil {
-		return "", "", 0, errors.New("invalid credentials")
+		s.recordLoginFailure(ctx, account, client)
+		return "", "", "", 0, errors.New("invalid credentials")
 	}
-
-	accessToken, err := s.generateJWT(matchedUser)
-	if err != nil {
-		return "", "", 0, fmt.Errorf("failed to generate access token: %w", err)
+	if s.verification.Required && !matchedUser.EmailVerified {
+		return "", "", "", 0, ErrEmailNotVerified
+	}
+	if matchedUser.TOTPEnabled {
+		mfaToken, err := s.generateMFAToken(uint32(matchedUser.ID))
+		if err != nil {
+			return "", "", "", 0, fmt.Errorf("failed to generate mfa token: %w", err)
+		}
+		return "", "", mfaToken, uint32(matchedUser.ID), nil
 	}
 
-	refreshToken, err := s.generateRefreshToken(ctx, uint32(matchedUser.ID))
+	s.resetLoginFailures(ctx, matchedUser)
+	accessToken, refreshToken, err := s.startSession(ctx, matchedUser, client)
 	if err != nil {
-		return "", "", 0, fmt.Errorf("failed to generate refresh token: %w", err)
+		return "", "", "", 0, err
 	}
-
-	return accessToken, refreshToken, uint32(matchedUser.ID), nil
+	return accessToken, refreshToken, "", uint32(matchedUser.ID), nil
 }
 
-func (s *AuthService) generateJWT(user *user.User) (string, error) {
-	payload := jwt.RegisteredClaims{
-		Subject:   strconv.FormatUint(uint64(user.ID), 10),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(jwtTokenExpireTime)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
+func (s *AuthService) generateJWT(user *user.User, sessionID string) (string, error) {
+	payload := accessClaims{
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(jwtTokenExpireTime)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
-	tokenStr, err := token.SignedString([]byte(s.jwtSecretKey))
+	return s.keys.Sign(payload)
+}
+
+// parseAccessToken проверяет подпись и срок access-токена. Токены подписываются ключами из
+// связки, другие сервисы проверяют их по JWKS.
+func (s *AuthService) parseAccessToken(token string) (*accessClaims, error) {
+	payload := &accessClaims{}
+	parsedToken, err := jwt.ParseWithClaims(token, payload, s.keys.VerificationKeyfunc([]byte(s.jwtSecretKey)),
+		jwt.WithValidMethods(s.keys.ValidMethods()))
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if !parsedToken.Valid {
+		return nil, errors.New("invalid token")
 	}
-	return tokenStr, nil
+	return payload, nil
+}
+
+// JWKS возвращает открытые ключи, которыми проверяются access-токены.
+func (s *AuthService) JWKS() jwtkeys.JWKS {
+	return s.keys.JWKS()
 }
 
 func (s *AuthService) GetUIDByToken(ctx context.Context, token string) (uint32, bool) {
-	blacklisted, err := s.blacklistRepo.IsTokenBlacklisted(ctx, token)
-	if err != nil || blacklisted {
-		return 0, false
-	}
+	uid, _, ok := s.GetSessionByToken(ctx, token)
+	return uid, ok
+}
 
-	payload := &jwt.RegisteredClaims{}
-	parsedToken, err := jwt.ParseWithClaims(token, payload, func(t *jwt.Token) (interface{}, error) {
-		return []byte(s.jwtSecretKey), nil
-	})
+// GetSessionByToken проверяет access-токен и возвращает ID пользователя и сессии. Не
+// принимаются токены из чёрного списка, выданные до отметки RevokeAllTokens и токены
+// отозванной сессии; у токенов старого формата ID сессии пуст.
+func (s *AuthService) GetSessionByToken(ctx context.Context, token string) (uint32, string, bool) {
+	payload, err := s.parseAccessToken(token)
+	if err != nil {
+		return 0, "", false
+	}
 
-	if err != nil || !parsedToken.Valid {
-		return 0, false
+	blacklisted, err := s.blacklistRepo.IsTokenBlacklisted(ctx, BlackListRepo.RevocationID(payload.ID, token))
+	if err != nil || blacklisted {
+		return 0, "", false
 	}
 
 	uid, err := strconv.ParseUint(payload.Subject, 10, 32)
 	if err != nil {
-		return 0, false
+		return 0, "", false
 	}
 
-	return uint32(uid), true
-}
-
-func (s *AuthService) generateRefreshToken(ctx context.Context, userID uint32) (string, error) {
-	refreshToken := uuid.NewString()
-	err := s.refreshRepo.SaveToken(ctx, userID, refreshToken, refreshTokenExpireTime)
+	issuedBefore, err := s.blacklistRepo.IssuedBefore(ctx, uint32(uid))
 	if err != nil {
-		return "", err
+		return 0, "", false
+	}
+	if issuedBefore > 0 && (payload.IssuedAt == nil || payload.IssuedAt.Unix() < issuedBefore) {
+		return 0, "", false
+	}
+
+	if payload.SessionID != "" {
+		exists, err := s.sessionRepo.Exists(ctx, payload.SessionID)
+		if err != nil || !exists {
+			return 0, "", false
+		}
 	}
-	return refreshToken, nil
 
+	return uint32(uid), payload.SessionID, true
 }
 
+// Logout завершает только ту сессию, которой выдан accessToken.
 func (s *AuthService) Logout(ctx context.Context, userID uint32, accessToken string) error {
-	if err := s.refreshRepo.DeleteToken(ctx, userID); err != nil {
-		return fmt.Errorf("failed to delete refresh token: %w", err)
-	}
-
-	payload := &jwt.RegisteredClaims{}
-	_, err := jwt.ParseWithClaims(accessToken, payload, func(t *jwt.Token) (interface{}, error) {
-		return []byte(s.jwtSecretKey), nil
-	})
+	payload, err := s.parseAccessToken(accessToken)
 	if err != nil {
 		return fmt.Errorf("invalid token: %w", err)
 	}
-	if err := s.blacklistRepo.AddToken(ctx, accessToken, payload.ExpiresAt.Time); err != nil {
+
+	if payload.SessionID != "" {
+		if _, err := s.sessionRepo.Delete(ctx, userID, payload.SessionID); err != nil {
+			return fmt.Errorf("failed to delete session: %w", err)
+		}
+		// остальные access-токены сессии, выданные при обновлениях, тоже больше не действуют
+		if err := s.revokeSessionTokens(ctx, payload.SessionID); err != nil {
+			return err
+		}
+	} else if err := s.refreshRepo.DeleteToken(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete refresh token: %w", err)
+	}
+
+	if err := s.blacklistRepo.AddToken(ctx, BlackListRepo.RevocationID(payload.ID, accessToken), payload.ExpiresAt.Time); err != nil {
 		return fmt.Errorf("failed to blacklist token: %w", err)
 	}
 
 	return nil
 }
 
+// RefreshToken выдаёт новую пару токенов той же сессии; предъявленный refresh-токен перестаёт
+// действовать. Все токены сессии — одно семейство: если предъявлен уже заменённый токен, его
+// украли или скопировали, и сессия отзывается целиком, у вора и у владельца сразу, вместе с
+// уже выданными access-токенами.
 func (s *AuthService) RefreshToken(ctx context.Context, userID uint32, oldRefreshToken string) (string, string, error) {
-	valid, err := s.refreshRepo.ValidateToken(ctx, userID, oldRefreshToken)
-	if err != nil || !valid {
-		return "", "", fmt.Errorf("expired refresh token")
+	sessionID, secret, ok := splitRefreshToken(oldRefreshToken)
+	if !ok {
+		return s.refreshLegacyToken(ctx, userID, oldRefreshToken)
+	}
+	sess, _, err := s.sessionRepo.Get(ctx, sessionID)
+	if errors.Is(err, sessionRepo.ErrNotFound) {
+		return "", "", ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to load session: %w", err)
+	}
+	if sess.UserID != userID {
+		return "", "", ErrInvalidRefreshToken
 	}
 
-	user, err := s.userRepo.GetByID(ctx, userID)
+	newSecret, err := generateRefreshSecret()
 	if err != nil {
 		return "", "", err
 	}
+	result, err := s.sessionRepo.Rotate(ctx, sess, hashRefreshSecret(secret), hashRefreshSecret(newSecret), refreshTokenExpireTime)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	switch result {
+	case sessionRepo.RotateReused:
+		log.Printf("[AuthService.RefreshToken] reused refresh token for session %s of user %d, session revoked", sess.ID, userID)
+		// access-токены, уже выданные вору в этом семействе, отзываются вместе с сессией
+		if err := s.revokeSessionTokens(ctx, sess.ID); err != nil {
+			return "", "", err
+		}
+		return "", "", ErrRefreshTokenReused
+	case sessionRepo.RotateInvalid:
+		return "", "", ErrInvalidRefreshToken
+	}
 
-	newAccessToken, err := s.generateJWT(user)
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return "", "", err
 	}
 
-	newRefreshToken, err := s.generateRefreshToken(ctx, userID)
+	newAccessToken, err := s.generateJWT(user, sess.ID)
 	if err != nil {
 		return "", "", err
 	}
 
-	return newAccessToken, newRefreshToken, nil
+	return newAccessToken, sess.ID + "." + newSecret, nil
 }
 
 // для тестов
 // ---------------------------------------
 func (s *AuthService) GenerateJWT(user *user.User) (string, error) {
-	return s.generateJWT(user)
+	return s.generateJWT(user, "")
+}
+
+func (s *AuthService) GenerateJWTForSession(user *user.User, sessionID string) (string, error) {
+	return s.generateJWT(user, sessionID)
+}
+
+func (s *AuthService) StartSession(ctx context.Context, u *user.User, client ClientInfo) (string, string, error) {
+	return s.startSession(ctx, u, client)
+}
+
+func (s *AuthService) GenerateVerificationToken(userID uint32, email string) (string, error) {
+	return s.generateVerificationToken(userID, email)
+}
+
+func (s *AuthService) GenerateMFAToken(userID uint32) (string, error) {
+	return s.generateMFAToken(userID)
 }
 
 func (s *AuthService) BlacklistRepo() *BlackListRepo.BlackListRepo {
 	return s.blacklistRepo
 }
 
+func (s *AuthService) SessionRepo() *sessionRepo.SessionRepo {
+	return s.sessionRepo
+}
+
+func (s *AuthService) RecordLoginFailure(ctx context.Context, username string, client ClientInfo) {
+	s.recordLoginFailure(ctx, s.loginAccount(ctx, username), client)
+}
+
+func (s *AuthService) CheckLoginAllowed(ctx context.Context, username string, client ClientInfo) error {
+	return s.checkLoginAllowed(ctx, s.loginAccount(ctx, username), client)
+}
+
+func (s *AuthService) loginAccount(ctx context.Context, username string) string {
+	users, _ := s.userRepo.GetByUsername(ctx, username)
+	if len(users) > 0 {
+		return loginAccountKey(users[0], username)
+	}
+	return loginAccountKey(nil, username)
+}
+
 //---------------------------------------
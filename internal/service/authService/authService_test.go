@@ -2,20 +2,51 @@ package authService_test
 
 import (
 	"context"
+	"fmt"
 	"registration-service/internal/model/user"
 	"registration-service/internal/service/authService"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"registration-service/internal/config"
+	"registration-service/internal/jwtkeys"
+	"registration-service/internal/mail"
+	"registration-service/internal/model/signingKey"
 	"registration-service/internal/repository/BlackListRepo"
+	"registration-service/internal/repository/loginAttemptRepo"
+	"registration-service/internal/repository/mfaRepo"
 	"registration-service/internal/repository/refreshToken"
+	"registration-service/internal/repository/resetToken"
+	"registration-service/internal/repository/sessionRepo"
+	"registration-service/internal/repository/userRepo"
+	"registration-service/internal/repository/verificationRepo"
 )
 
 func setupService(t *testing.T) *authService.AuthService {
+	s, _ := setupServiceWithRedis(t)
+	return s
+}
+
+func setupServiceWithRedis(t *testing.T) (*authService.AuthService, *miniredis.Miniredis) {
+	s, env := setupEnv(t)
+	return s, env.redis
+}
+
+// testEnv — зависимости сервиса, которые тесты проверяют напрямую.
+type testEnv struct {
+	users  *fakeUserRepo
+	mailer *fakeMailer
+	redis  *miniredis.Miniredis
+}
+
+func setupEnv(t *testing.T) (*authService.AuthService, *testEnv) {
 	// стартуем miniredis
 	mr, err := miniredis.Run()
 	if err != nil {
@@ -28,8 +59,236 @@ func setupService(t *testing.T) *authService.AuthService {
 	// репозитории
 	refRepo := refreshToken.New(cli)
 	blRepo := BlackListRepo.NewBlackListRepo(cli)
-	// userRepo нам не нужен для этих тестов, передаём nil, но не будем вызывать методы, где он нужен
-	return authService.New(nil, "test-jwt-secret", refRepo, blRepo)
+	// токены подписываются Ed25519; HS256-токены без kid принимаются, как во время миграции
+	keys, err := jwtkeys.NewKeyRing(jwtkeys.NewMemoryStore(), nil, jwtkeys.Config{
+		Algorithm: signingKey.AlgEdDSA, RotationInterval: time.Hour, Overlap: time.Hour, AcceptHS256: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	env := &testEnv{users: newFakeUserRepo(), mailer: &fakeMailer{}, redis: mr}
+	s := authService.New(env.users, "test-jwt-secret", keys, refRepo, sessionRepo.New(cli), blRepo, verificationRepo.New(cli), resetToken.New(cli),
+		mfaRepo.New(cli), loginAttemptRepo.New(cli), env.mailer, config.VerificationConfig{TokenTTL: time.Hour},
+		config.PasswordResetConfig{TokenTTL: time.Minute}, config.MFAConfig{TokenTTL: time.Minute, Skew: 1, MaxAttempts: 2},
+		config.LoginThrottleConfig{
+			Window: time.Hour, SlowdownAfter: 2, SlowdownDelay: time.Second, MaxDelay: 2 * time.Second,
+			MaxAccountFailures: 6, MaxIPFailures: 3, Lockout: time.Hour,
+		})
+	return s, env
+}
+
+// fakeUserRepo хранит пользователей в памяти вместо Postgres.
+type fakeUserRepo struct {
+	mu            sync.Mutex
+	nextID        uint32
+	users         map[uint32]*user.User
+	totpSecrets   map[uint32]string
+	recoveryCodes map[uint32]map[string]bool
+	// updatePasswordErr, если задана, возвращается из UpdatePassword
+	updatePasswordErr error
+}
+
+func newFakeUserRepo() *fakeUserRepo {
+	return &fakeUserRepo{users: map[uint32]*user.User{}, totpSecrets: map[uint32]string{}, recoveryCodes: map[uint32]map[string]bool{}}
+}
+
+// add заводит пользователя с паролем password и возвращает его копию.
+func (r *fakeUserRepo) add(t *testing.T, username, email, password string) *user.User {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := r.Create(context.Background(), username, email, string(hash))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := r.GetByID(context.Background(), id)
+	return u
+}
+
+func (r *fakeUserRepo) Create(ctx context.Context, username, email, passwordHash string) (uint32, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.Username == username || u.Email == email {
+			return 0, userRepo.ErrConflict
+		}
+	}
+	r.nextID++
+	r.users[r.nextID] = &user.User{ID: uint64(r.nextID), Username: username, Email: email, Password: passwordHash}
+	return r.nextID, nil
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id uint32) (*user.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return nil, fmt.Errorf("user not found: %w", pgx.ErrNoRows)
+	}
+	cp := *u
+	return &cp, nil
+}
+
+func (r *fakeUserRepo) GetUserByEmail(ctx context.Context, email string) (*user.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.Email == email {
+			cp := *u
+			return &cp, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (r *fakeUserRepo) GetByUsername(ctx context.Context, username string) ([]*user.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var users []*user.User
+	for _, u := range r.users {
+		if u.Username == username {
+			cp := *u
+			users = append(users, &cp)
+		}
+	}
+	return users, nil
+}
+
+func (r *fakeUserRepo) MarkEmailVerified(ctx context.Context, id uint32, email string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok || u.Email != email {
+		return false, nil
+	}
+	u.EmailVerified = true
+	return true, nil
+}
+
+func (r *fakeUserRepo) UpdatePassword(ctx context.Context, id uint32, passwordHash string) error {
+	if r.updatePasswordErr != nil {
+		return r.updatePasswordErr
+	}
+	return r.update(id, func(u *user.User) { u.Password = passwordHash })
+}
+
+func (r *fakeUserRepo) UpdateEmail(ctx context.Context, id uint32, email string) error {
+	if r.taken(id, func(u *user.User) bool { return u.Email == email }) {
+		return userRepo.ErrConflict
+	}
+	return r.update(id, func(u *user.User) { u.Email, u.EmailVerified = email, false })
+}
+
+func (r *fakeUserRepo) UpdateUsername(ctx context.Context, id uint32, username string) error {
+	if r.taken(id, func(u *user.User) bool { return u.Username == username }) {
+		return userRepo.ErrConflict
+	}
+	return r.update(id, func(u *user.User) { u.Username = username })
+}
+
+func (r *fakeUserRepo) UpdateProfile(ctx context.Context, id uint32, displayName, avatarURL string) error {
+	return r.update(id, func(u *user.User) { u.DisplayName, u.AvatarURL = displayName, avatarURL })
+}
+
+func (r *fakeUserRepo) SetPendingTOTPSecret(ctx context.Context, id uint32, secret string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok || u.TOTPEnabled {
+		return false, nil
+	}
+	r.totpSecrets[id] = secret
+	return true, nil
+}
+
+func (r *fakeUserRepo) GetTOTPSecret(ctx context.Context, id uint32) (string, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return "", false, pgx.ErrNoRows
+	}
+	return r.totpSecrets[id], u.TOTPEnabled, nil
+}
+
+func (r *fakeUserRepo) EnableTOTP(ctx context.Context, id uint32, secret string, recoveryCodeHashes []string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok || u.TOTPEnabled || r.totpSecrets[id] != secret {
+		return false, nil
+	}
+	u.TOTPEnabled = true
+	r.recoveryCodes[id] = map[string]bool{}
+	for _, hash := range recoveryCodeHashes {
+		r.recoveryCodes[id][hash] = true
+	}
+	return true, nil
+}
+
+func (r *fakeUserRepo) ConsumeRecoveryCode(ctx context.Context, id uint32, codeHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.recoveryCodes[id][codeHash] {
+		return false, nil
+	}
+	delete(r.recoveryCodes[id], codeHash)
+	return true, nil
+}
+
+func (r *fakeUserRepo) DisableTOTP(ctx context.Context, id uint32) error {
+	err := r.update(id, func(u *user.User) { u.TOTPEnabled = false })
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.totpSecrets, id)
+	delete(r.recoveryCodes, id)
+	return err
+}
+
+func (r *fakeUserRepo) update(id uint32, apply func(u *user.User)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return fmt.Errorf("user %d not found", id)
+	}
+	apply(u)
+	return nil
+}
+
+// taken — значение уже занято пользователем, отличным от id.
+func (r *fakeUserRepo) taken(id uint32, match func(u *user.User) bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for uid, u := range r.users {
+		if uid != id && match(u) {
+			return true
+		}
+	}
+	return false
+}
+
+// fakeMailer запоминает отправленные письма.
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent []mail.Message
+}
+
+func (m *fakeMailer) Send(ctx context.Context, msg mail.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func (m *fakeMailer) messages() []mail.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]mail.Message(nil), m.sent...)
 }
 
 func TestGenerateJWT_And_GetUIDByToken(t *testing.T) {
@@ -124,3 +383,10 @@ func TestLogout_BlacklistsAccessToken(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, blacklisted)
 }
+
+func TestResetPassword_UnknownToken(t *testing.T) {
+	s := setupService(t)
+
+	err := s.ResetPassword(context.Background(), "never-issued", "new-password")
+	assert.ErrorIs(t, err, authService.ErrInvalidResetToken)
+}
@@ -0,0 +1,131 @@
+package authService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"registration-service/internal/config"
+	"registration-service/internal/model/user"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrTooManyLoginAttempts = errors.New("too many failed login attempts")
+
+// LoginThrottledError — вход временно запрещён; повторить можно через RetryAfter.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("%v, retry in %s", ErrTooManyLoginAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrTooManyLoginAttempts
+}
+
+// penalty — на сколько запретить вход после failures неудач подряд.
+func penalty(c config.LoginThrottleConfig, failures int64, maxFailures int) time.Duration {
+	if maxFailures > 0 && failures >= int64(maxFailures) {
+		return c.Lockout
+	}
+	over := failures - int64(c.SlowdownAfter)
+	if over <= 0 || c.SlowdownDelay <= 0 {
+		return 0
+	}
+	delay := c.SlowdownDelay
+	for i := int64(1); i < over && delay < c.MaxDelay; i++ {
+		delay *= 2
+	}
+	if c.MaxDelay > 0 && delay > c.MaxDelay {
+		delay = c.MaxDelay
+	}
+	return delay
+}
+
+// loginAccountKey — ключ счётчика неудач аккаунта. У существующего пользователя (u != nil) ключ
+// строится по ID: переименование не сбрасывает счётчик, а занявший освободившееся имя не
+// наследует чужую блокировку. Попытки с несуществующими именами ограничиваются по имени, так
+// что ответ не выдаёт, какие аккаунты есть.
+func loginAccountKey(u *user.User, username string) string {
+	if u != nil {
+		return "uid:" + strconv.FormatUint(u.ID, 10)
+	}
+	return "user:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func loginIPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// checkLoginAllowed возвращает *LoginThrottledError, если аккаунт или адрес заблокированы;
+// account — ключ из loginAccountKey.
+func (s *AuthService) checkLoginAllowed(ctx context.Context, account string, client ClientInfo) error {
+	keys := []string{account}
+	if client.IP != "" {
+		keys = append(keys, loginIPKey(client.IP))
+	}
+	wait, err := s.loginAttemptRepo.Blocked(ctx, keys...)
+	if err != nil {
+		return fmt.Errorf("failed to check login attempts: %w", err)
+	}
+	if wait > 0 {
+		return &LoginThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// recordLoginFailure учитывает неудачный вход и при необходимости блокирует аккаунт и адрес.
+func (s *AuthService) recordLoginFailure(ctx context.Context, account string, client ClientInfo) {
+	s.throttleLogin(ctx, account, s.loginThrottle.MaxAccountFailures)
+	if client.IP != "" {
+		s.throttleLogin(ctx, loginIPKey(client.IP), s.loginThrottle.MaxIPFailures)
+	}
+}
+
+func (s *AuthService) throttleLogin(ctx context.Context, key string, maxFailures int) {
+	failures, err := s.loginAttemptRepo.RecordFailure(ctx, key, s.loginThrottle.Window)
+	if err != nil {
+		log.Printf("[AuthService.Login] failed to record failed login for %s: %v", key, err)
+		return
+	}
+	delay := penalty(s.loginThrottle, failures, maxFailures)
+	if delay <= 0 {
+		return
+	}
+	if err := s.loginAttemptRepo.Block(ctx, key, delay); err != nil {
+		log.Printf("[AuthService.Login] failed to block %s: %v", key, err)
+		return
+	}
+	if maxFailures > 0 && failures >= int64(maxFailures) {
+		log.Printf("[AuthService.Login] %s locked for %s after %d failed logins", key, delay, failures)
+	}
+}
+
+// resetLoginFailures обнуляет счётчик неудач аккаунта. Вызывается только после полной
+// аутентификации: верный пароль без кода 2FA счётчик не сбрасывает.
+func (s *AuthService) resetLoginFailures(ctx context.Context, u *user.User) {
+	if err := s.loginAttemptRepo.Reset(ctx, loginAccountKey(u, "")); err != nil {
+		log.Printf("[AuthService.Login] failed to reset failed logins of user %d: %v", u.ID, err)
+	}
+}
+
+// UnlockAccount снимает блокировку входа с аккаунта и обнуляет счётчик неудач. Доступно только
+// администраторам; блокировки адресов не затрагиваются.
+func (s *AuthService) UnlockAccount(ctx context.Context, adminID, userID uint32) error {
+	if err := s.requireAdmin(ctx, adminID); err != nil {
+		return err
+	}
+	target, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.loginAttemptRepo.Reset(ctx, loginAccountKey(target, "")); err != nil {
+		return fmt.Errorf("failed to unlock account: %w", err)
+	}
+	log.Printf("[AuthService.UnlockAccount] admin %d unlocked user %d", adminID, userID)
+	return nil
+}
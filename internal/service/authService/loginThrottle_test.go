@@ -0,0 +1,126 @@
+package authService_test
+
+import (
+	"context"
+	"errors"
+	"registration-service/internal/service/authService"
+	"registration-service/internal/totp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func retryAfter(t *testing.T, err error) time.Duration {
+	t.Helper()
+	var throttled *authService.LoginThrottledError
+	require.True(t, errors.As(err, &throttled), "expected throttling, got %v", err)
+	assert.ErrorIs(t, err, authService.ErrTooManyLoginAttempts)
+	return throttled.RetryAfter
+}
+
+func TestLoginThrottle_SlowsDownThenLocksAccount(t *testing.T) {
+	s, mr := setupServiceWithRedis(t)
+	ctx := context.Background()
+	client := authService.ClientInfo{}
+
+	// SlowdownAfter 2, SlowdownDelay 1s, MaxDelay 2s, MaxAccountFailures 6, Lockout 1h
+	for i := 0; i < 2; i++ {
+		s.RecordLoginFailure(ctx, "alice", client)
+		require.NoError(t, s.CheckLoginAllowed(ctx, "alice", client))
+	}
+	for _, want := range []time.Duration{time.Second, 2 * time.Second, 2 * time.Second} {
+		s.RecordLoginFailure(ctx, "alice", client)
+		wait := retryAfter(t, s.CheckLoginAllowed(ctx, "Alice", client))
+		assert.InDelta(t, want, wait, float64(100*time.Millisecond))
+		mr.FastForward(want)
+		require.NoError(t, s.CheckLoginAllowed(ctx, "alice", client))
+	}
+
+	s.RecordLoginFailure(ctx, "alice", client)
+	// Login отказывает ещё до проверки пароля
+	_, _, _, _, err := s.Login(ctx, "alice", "correct-password", client)
+	assert.InDelta(t, time.Hour, retryAfter(t, err), float64(time.Second))
+	assert.NoError(t, s.CheckLoginAllowed(ctx, "bob", client), "other accounts are not affected")
+}
+
+func TestLoginThrottle_LocksSourceIP(t *testing.T) {
+	s := setupService(t)
+	ctx := context.Background()
+	attacker := authService.ClientInfo{IP: "203.0.113.7"}
+
+	// перебор по разным аккаунтам с одного адреса; MaxIPFailures 3
+	for _, username := range []string{"u1", "u2", "u3"} {
+		s.RecordLoginFailure(ctx, username, attacker)
+	}
+	assert.InDelta(t, time.Hour, retryAfter(t, s.CheckLoginAllowed(ctx, "u4", attacker)), float64(time.Second))
+	assert.NoError(t, s.CheckLoginAllowed(ctx, "u4", authService.ClientInfo{IP: "198.51.100.1"}))
+}
+
+func TestLoginThrottle_CountsFailedMFACodes(t *testing.T) {
+	s, env := setupEnv(t)
+	ctx := context.Background()
+	client := authService.ClientInfo{}
+	u := env.users.add(t, "alice", "alice@example.com", "correct-password")
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	_, err = env.users.SetPendingTOTPSecret(ctx, uint32(u.ID), secret)
+	require.NoError(t, err)
+	_, err = env.users.EnableTOTP(ctx, uint32(u.ID), secret, nil)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, _, _, _, err := s.Login(ctx, "alice", "wrong-password", client)
+		require.Error(t, err)
+	}
+	// верный пароль без второго фактора счётчик не сбрасывает
+	_, _, mfaToken, _, err := s.Login(ctx, "alice", "correct-password", client)
+	require.NoError(t, err)
+	require.NotEmpty(t, mfaToken)
+
+	_, _, _, err = s.VerifyMFA(ctx, mfaToken, "aaaaa-bbbbb", client)
+	assert.ErrorIs(t, err, authService.ErrInvalidMFACode)
+	// третья неудача подряд — SlowdownAfter 2
+	retryAfter(t, s.CheckLoginAllowed(ctx, "alice", client))
+	_, _, _, err = s.VerifyMFA(ctx, mfaToken, "aaaaa-bbbbb", client)
+	retryAfter(t, err)
+
+	env.redis.FastForward(time.Second)
+	code, err := totp.Code(secret, time.Now())
+	require.NoError(t, err)
+	access, _, _, err := s.VerifyMFA(ctx, mfaToken, code, client)
+	require.NoError(t, err)
+	assert.NotEmpty(t, access)
+
+	// после полного входа счётчик обнулён: две неудачи снова не замедляют вход
+	for i := 0; i < 2; i++ {
+		s.RecordLoginFailure(ctx, "alice", client)
+	}
+	assert.NoError(t, s.CheckLoginAllowed(ctx, "alice", client))
+}
+
+func TestLoginThrottle_LockFollowsAccountAcrossRename(t *testing.T) {
+	s, env := setupEnv(t)
+	ctx := context.Background()
+	client := authService.ClientInfo{}
+	alice := env.users.add(t, "alice", "alice@example.com", "correct-password")
+
+	// MaxAccountFailures 6; пауза после каждой неудачи не длиннее MaxDelay 2s
+	for i := 0; i < 6; i++ {
+		env.redis.FastForward(2 * time.Second)
+		_, _, _, _, err := s.Login(ctx, "alice", "wrong-password", client)
+		require.Error(t, err)
+	}
+	require.NoError(t, s.ChangeUsername(ctx, uint32(alice.ID), "alicia"))
+
+	// переименование не снимает блокировку
+	_, _, _, _, err := s.Login(ctx, "alicia", "correct-password", client)
+	assert.InDelta(t, time.Hour, retryAfter(t, err), float64(time.Second))
+
+	// новый владелец освободившегося имени не наследует чужую блокировку
+	env.users.add(t, "alice", "new-alice@example.com", "another-password")
+	access, _, _, _, err := s.Login(ctx, "alice", "another-password", client)
+	require.NoError(t, err)
+	assert.NotEmpty(t, access)
+}
@@ -0,0 +1,285 @@
+package authService
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"registration-service/internal/mail"
+	"registration-service/internal/totp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const mfaAudience = "mfa-login"
+
+var (
+	ErrMFAAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled     = errors.New("two-factor authentication enrollment was not started")
+	ErrInvalidMFACode     = errors.New("invalid authentication code")
+	ErrInvalidMFAToken    = errors.New("invalid or expired mfa token")
+	ErrTooManyMFAAttempts = errors.New("too many invalid codes, sign in again")
+	ErrNotAdmin           = errors.New("administrator rights required")
+)
+
+// mfaKey отличается от ключа access-токенов: mfa-токен подтверждает только пароль и не
+// должен открывать доступ к API.
+func (s *AuthService) mfaKey() []byte {
+	return []byte(mfaAudience + ":" + s.jwtSecretKey)
+}
+
+func (s *AuthService) generateMFAToken(userID uint32) (string, error) {
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		Subject:   strconv.FormatUint(uint64(userID), 10),
+		Audience:  jwt.ClaimStrings{mfaAudience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(s.mfa.TokenTTL)),
+		IssuedAt:  jwt.NewNumericDate(now),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.mfaKey())
+}
+
+func (s *AuthService) parseMFAToken(token string) (uint32, string, error) {
+	claims := &jwt.RegisteredClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return s.mfaKey(), nil
+	}, jwt.WithAudience(mfaAudience), jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !parsed.Valid || claims.ID == "" {
+		return 0, "", ErrInvalidMFAToken
+	}
+	uid, err := strconv.ParseUint(claims.Subject, 10, 32)
+	if err != nil {
+		return 0, "", ErrInvalidMFAToken
+	}
+	return uint32(uid), claims.ID, nil
+}
+
+// EnrollTOTP выдаёт новый секрет и otpauth-ссылку для приложения. 2FA включается только
+// после ConfirmTOTP, повторный EnrollTOTP до этого заменяет секрет.
+func (s *AuthService) EnrollTOTP(ctx context.Context, userID uint32) (string, string, error) {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+	if u.TOTPEnabled {
+		return "", "", ErrMFAAlreadyEnabled
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	stored, err := s.userRepo.SetPendingTOTPSecret(ctx, userID, secret)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to save secret: %w", err)
+	}
+	if !stored {
+		return "", "", ErrMFAAlreadyEnabled
+	}
+	return secret, totp.URI(s.mfa.Issuer, u.Email, secret), nil
+}
+
+// ConfirmTOTP включает 2FA, если код из приложения сходится с выданным секретом, и
+// возвращает коды восстановления. Коды показываются один раз, хранятся только их хеши.
+func (s *AuthService) ConfirmTOTP(ctx context.Context, userID uint32, code string) ([]string, error) {
+	secret, enabled, err := s.userRepo.GetTOTPSecret(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if secret == "" {
+		return nil, ErrMFANotEnrolled
+	}
+	if err := s.checkTOTP(ctx, userID, secret, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := s.generateRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+	ok, err := s.userRepo.EnableTOTP(ctx, userID, secret, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+	if !ok {
+		// параллельный EnrollTOTP заменил секрет
+		return nil, ErrMFANotEnrolled
+	}
+	return codes, nil
+}
+
+// VerifyMFA завершает вход: mfa-токен из Login плюс код из приложения или код восстановления.
+// На один mfa-токен даётся MaxAttempts попыток, дальше нужно снова ввести пароль. Неверные коды
+// учитываются вместе с неудачными входами аккаунта, так что перебор кодов с новыми mfa-токенами
+// упирается в ту же блокировку.
+func (s *AuthService) VerifyMFA(ctx context.Context, mfaToken, code string, client ClientInfo) (string, string, uint32, error) {
+	userID, tokenID, err := s.parseMFAToken(mfaToken)
+	if err != nil {
+		return "", "", 0, err
+	}
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return "", "", 0, err
+	}
+	account := loginAccountKey(u, u.Username)
+	if err := s.checkLoginAllowed(ctx, account, client); err != nil {
+		return "", "", 0, err
+	}
+	attempts, err := s.mfaRepo.CountAttempt(ctx, tokenID, s.mfa.TokenTTL)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("failed to count attempts: %w", err)
+	}
+	if attempts > int64(s.mfa.MaxAttempts) {
+		return "", "", 0, ErrTooManyMFAAttempts
+	}
+
+	secret, enabled, err := s.userRepo.GetTOTPSecret(ctx, userID)
+	if err != nil {
+		return "", "", 0, err
+	}
+	if !enabled {
+		// 2FA сбросили после ввода пароля
+		return "", "", 0, ErrInvalidMFAToken
+	}
+	if isTOTPCode(code) {
+		err = s.checkTOTP(ctx, userID, secret, code)
+	} else {
+		err = s.consumeRecoveryCode(ctx, userID, code)
+	}
+	if errors.Is(err, ErrInvalidMFACode) {
+		s.recordLoginFailure(ctx, account, client)
+	}
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	s.resetLoginFailures(ctx, u)
+	accessToken, refreshToken, err := s.startSession(ctx, u, client)
+	if err != nil {
+		return "", "", 0, err
+	}
+	return accessToken, refreshToken, userID, nil
+}
+
+// ResetMFA выключает 2FA пользователя, потерявшего и приложение, и коды восстановления.
+// Доступно только администраторам; пользователь получает уведомление на почту.
+func (s *AuthService) ResetMFA(ctx context.Context, adminID, userID uint32) error {
+	if err := s.requireAdmin(ctx, adminID); err != nil {
+		return err
+	}
+	target, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.DisableTOTP(ctx, userID); err != nil {
+		return fmt.Errorf("failed to reset two-factor authentication: %w", err)
+	}
+	log.Printf("[AuthService.ResetMFA] admin %d reset two-factor authentication of user %d", adminID, userID)
+
+	notice := mail.Message{
+		To:      target.Email,
+		Subject: "Two-factor authentication was turned off",
+		Body:    "An administrator turned off two-factor authentication for your account. Set it up again after signing in.",
+	}
+	if err := s.mailer.Send(ctx, notice); err != nil {
+		log.Printf("[AuthService.ResetMFA] failed to notify user %d: %v", userID, err)
+	}
+	return nil
+}
+
+// requireAdmin возвращает ErrNotAdmin, если пользователь не администратор.
+func (s *AuthService) requireAdmin(ctx context.Context, userID uint32) error {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !u.IsAdmin {
+		return ErrNotAdmin
+	}
+	return nil
+}
+
+// checkTOTP проверяет код и запрещает его повторное использование в пределах окна.
+func (s *AuthService) checkTOTP(ctx context.Context, userID uint32, secret, code string) error {
+	step, ok := totp.Validate(secret, strings.TrimSpace(code), time.Now(), s.mfa.Skew)
+	if !ok {
+		return ErrInvalidMFACode
+	}
+	window := time.Duration(2*s.mfa.Skew+1) * totp.Period
+	first, err := s.mfaRepo.MarkStepUsed(ctx, userID, step, window)
+	if err != nil {
+		return fmt.Errorf("failed to check code reuse: %w", err)
+	}
+	if !first {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (s *AuthService) consumeRecoveryCode(ctx context.Context, userID uint32, code string) error {
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return ErrInvalidMFACode
+	}
+	used, err := s.userRepo.ConsumeRecoveryCode(ctx, userID, hashRecoveryCode(normalized))
+	if err != nil {
+		return fmt.Errorf("failed to check recovery code: %w", err)
+	}
+	if !used {
+		return ErrInvalidMFACode
+	}
+	log.Printf("[AuthService.VerifyMFA] user %d signed in with a recovery code", userID)
+	return nil
+}
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateRecoveryCodes возвращает коды вида xxxxx-xxxxx и их хеши для хранения.
+func (s *AuthService) generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, s.mfa.RecoveryCodes)
+	hashes := make([]string, s.mfa.RecoveryCodes)
+	for i := range codes {
+		raw := make([]byte, 10)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(recoveryEncoding.EncodeToString(raw))[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = hashRecoveryCode(code)
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// hashRecoveryCode — коды случайные и длинные, поэтому достаточно SHA-256 без соли.
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+func isTOTPCode(code string) bool {
+	code = strings.TrimSpace(code)
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
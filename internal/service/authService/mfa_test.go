@@ -0,0 +1,29 @@
+package authService_test
+
+import (
+	"context"
+	"registration-service/internal/model/user"
+	"registration-service/internal/service/authService"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMFAToken_NotInterchangeableWithAccessToken(t *testing.T) {
+	s := setupService(t)
+	ctx := context.Background()
+
+	mfaToken, err := s.GenerateMFAToken(5)
+	require.NoError(t, err)
+	_, valid := s.GetUIDByToken(ctx, mfaToken)
+	assert.False(t, valid, "mfa token must not authenticate requests before the second factor")
+
+	access, err := s.GenerateJWT(&user.User{ID: 5})
+	require.NoError(t, err)
+	_, _, _, err = s.VerifyMFA(ctx, access, "123456", authService.ClientInfo{})
+	assert.ErrorIs(t, err, authService.ErrInvalidMFAToken)
+
+	_, _, _, err = s.VerifyMFA(ctx, "not-a-token", "123456", authService.ClientInfo{})
+	assert.ErrorIs(t, err, authService.ErrInvalidMFAToken)
+}
@@ -0,0 +1,100 @@
+package authService
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"registration-service/internal/mail"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+
+// hashResetToken — в Redis хранится только хеш: утечка базы не даёт готовых токенов.
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RequestPasswordReset отправляет письмо с одноразовым токеном сброса пароля. Ответ не
+// зависит от того, зарегистрирован ли адрес, чтобы по нему нельзя было перебирать аккаунты;
+// письмо уходит в фоне, чтобы и время ответа этого не выдавало.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil || user == nil {
+		return nil
+	}
+	allowed, err := s.resetRepo.AllowRequest(ctx, uint32(user.ID), s.passwordReset.RequestInterval)
+	if err != nil {
+		log.Printf("[AuthService.RequestPasswordReset] failed to check request interval for user %d: %v", user.ID, err)
+		return nil
+	}
+	if !allowed {
+		return nil
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	if err := s.resetRepo.SaveToken(ctx, uint32(user.ID), hashResetToken(token), s.passwordReset.TokenTTL); err != nil {
+		return fmt.Errorf("failed to save reset token: %w", err)
+	}
+
+	go s.sendResetMail(context.WithoutCancel(ctx), uint32(user.ID), user.Email, token)
+	return nil
+}
+
+func (s *AuthService) sendResetMail(ctx context.Context, userID uint32, email, token string) {
+	body := "Use this token to reset your password:\n\n" + token
+	if s.passwordReset.URL != "" {
+		body = "Reset your password by opening this link:\n\n" + s.passwordReset.URL + "?token=" + url.QueryEscape(token)
+	}
+	body += fmt.Sprintf("\n\nThe token can be used once and expires in %s. If you did not ask to reset your password, ignore this email.",
+		s.passwordReset.TokenTTL)
+	if err := s.mailer.Send(ctx, mail.Message{To: email, Subject: "Reset your password", Body: body}); err != nil {
+		log.Printf("[AuthService.RequestPasswordReset] failed to send reset email to user %d: %v", userID, err)
+	}
+}
+
+// ResetPassword меняет пароль по токену из письма и отзывает все токены пользователя. Токен
+// забирается до смены пароля, так что два параллельных запроса с одним токеном не сменят
+// пароль дважды. Если сменить пароль не удалось, токен возвращается; если тем временем выдан
+// новый, пользователю придётся воспользоваться им.
+func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if newPassword == "" {
+		return fmt.Errorf("invalid format")
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	tokenHash := hashResetToken(token)
+	userID, ttl, found, err := s.resetRepo.ConsumeToken(ctx, tokenHash)
+	if err != nil {
+		return fmt.Errorf("failed to claim reset token: %w", err)
+	}
+	if !found {
+		return ErrInvalidResetToken
+	}
+	if err := s.userRepo.UpdatePassword(ctx, userID, string(hashedPassword)); err != nil {
+		restored, restoreErr := s.resetRepo.RestoreToken(context.WithoutCancel(ctx), userID, tokenHash, ttl)
+		if restoreErr != nil || !restored {
+			log.Printf("[AuthService.ResetPassword] reset token of user %d was not restored: %v", userID, restoreErr)
+		}
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	if err := s.revokeIssuedTokens(ctx, userID); err != nil {
+		return err
+	}
+	return nil
+}
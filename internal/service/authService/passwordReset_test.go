@@ -0,0 +1,89 @@
+package authService_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"registration-service/internal/mail"
+	"registration-service/internal/service/authService"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resetMail ждёт письмо со сбросом: оно отправляется в фоне.
+func resetMail(t *testing.T, env *testEnv) mail.Message {
+	require.Eventually(t, func() bool { return len(env.mailer.messages()) > 0 }, time.Second, 10*time.Millisecond)
+	return env.mailer.messages()[0]
+}
+
+func TestResetPassword_ChangesPasswordAndRevokesSessions(t *testing.T) {
+	s, env := setupEnv(t)
+	ctx := context.Background()
+	env.users.add(t, "alice", "alice@example.com", "old-password")
+	access, _, _, _, err := s.Login(ctx, "alice", "old-password", authService.ClientInfo{})
+	require.NoError(t, err)
+
+	require.NoError(t, s.RequestPasswordReset(ctx, "alice@example.com"))
+	msg := resetMail(t, env)
+	assert.Equal(t, "alice@example.com", msg.To)
+	token := tokenFromMail(t, msg.Body)
+
+	require.NoError(t, s.ResetPassword(ctx, token, "new-password"))
+	_, valid := s.GetUIDByToken(ctx, access)
+	assert.False(t, valid, "sessions opened with the old password are revoked")
+	_, _, _, _, err = s.Login(ctx, "alice", "old-password", authService.ClientInfo{})
+	assert.Error(t, err)
+	_, _, _, _, err = s.Login(ctx, "alice", "new-password", authService.ClientInfo{})
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, s.ResetPassword(ctx, token, "another-password"), authService.ErrInvalidResetToken)
+}
+
+func TestResetPassword_KeepsTokenWhenUpdateFails(t *testing.T) {
+	s, env := setupEnv(t)
+	ctx := context.Background()
+	env.users.add(t, "alice", "alice@example.com", "old-password")
+	require.NoError(t, s.RequestPasswordReset(ctx, "alice@example.com"))
+	token := tokenFromMail(t, resetMail(t, env).Body)
+
+	env.users.updatePasswordErr = errors.New("database is down")
+	assert.Error(t, s.ResetPassword(ctx, token, "new-password"))
+
+	env.users.updatePasswordErr = nil
+	assert.NoError(t, s.ResetPassword(ctx, token, "new-password"), "failed reset does not burn the token")
+}
+
+func TestRequestPasswordReset_UnknownEmail(t *testing.T) {
+	s, env := setupEnv(t)
+	require.NoError(t, s.RequestPasswordReset(context.Background(), "nobody@example.com"))
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, env.mailer.messages())
+}
+
+func TestResetPassword_TokenIsSingleUseUnderConcurrency(t *testing.T) {
+	s, env := setupEnv(t)
+	ctx := context.Background()
+	env.users.add(t, "alice", "alice@example.com", "old-password")
+	require.NoError(t, s.RequestPasswordReset(ctx, "alice@example.com"))
+	token := tokenFromMail(t, resetMail(t, env).Body)
+
+	const attempts = 8
+	results := make(chan error, attempts)
+	for i := 0; i < attempts; i++ {
+		go func(i int) {
+			results <- s.ResetPassword(ctx, token, fmt.Sprintf("new-password-%d", i))
+		}(i)
+	}
+	succeeded := 0
+	for i := 0; i < attempts; i++ {
+		if err := <-results; err == nil {
+			succeeded++
+		} else {
+			assert.ErrorIs(t, err, authService.ErrInvalidResetToken)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+}
@@ -0,0 +1,164 @@
+package authService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"registration-service/internal/mail"
+	"registration-service/internal/model/user"
+	"registration-service/internal/repository/userRepo"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	maxDisplayNameLength = 100
+	maxAvatarURLLength   = 2048
+)
+
+// usernameRegex — правило для имени при регистрации и смене: от 3 до 32 латинских букв, цифр
+// и символов . _ -; имя входит в ключи блокировки входа и не должно допускать неоднозначностей.
+var usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9._-]{3,32}$`)
+
+var (
+	ErrInvalidArgument = errors.New("invalid argument")
+	ErrWrongPassword   = errors.New("current password is incorrect")
+	ErrEmailTaken      = errors.New("email already exists")
+	ErrUsernameTaken   = errors.New("username already taken")
+)
+
+// GetProfile возвращает данные аккаунта без хеша пароля.
+func (s *AuthService) GetProfile(ctx context.Context, userID uint32) (*user.User, error) {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	u.Password = ""
+	return u, nil
+}
+
+// UpdateProfile меняет отображаемое имя и аватар; пустые значения очищают поля.
+func (s *AuthService) UpdateProfile(ctx context.Context, userID uint32, displayName, avatarURL string) (*user.User, error) {
+	displayName = strings.TrimSpace(displayName)
+	avatarURL = strings.TrimSpace(avatarURL)
+	if utf8.RuneCountInString(displayName) > maxDisplayNameLength {
+		return nil, fmt.Errorf("%w: display name is longer than %d characters", ErrInvalidArgument, maxDisplayNameLength)
+	}
+	if avatarURL != "" {
+		if len(avatarURL) > maxAvatarURLLength {
+			return nil, fmt.Errorf("%w: avatar URL is longer than %d characters", ErrInvalidArgument, maxAvatarURLLength)
+		}
+		parsed, err := url.Parse(avatarURL)
+		if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return nil, fmt.Errorf("%w: avatar URL must be an absolute http(s) URL", ErrInvalidArgument)
+		}
+	}
+	if err := s.userRepo.UpdateProfile(ctx, userID, displayName, avatarURL); err != nil {
+		return nil, fmt.Errorf("failed to update profile: %w", err)
+	}
+	return s.GetProfile(ctx, userID)
+}
+
+// ChangePassword меняет пароль после проверки текущего и отзывает все токены пользователя.
+// Вызывающий получает новую сессию на том же устройстве, что и sessionID, с новой парой токенов.
+func (s *AuthService) ChangePassword(ctx context.Context, userID uint32, sessionID, currentPassword, newPassword string) (string, string, error) {
+	if newPassword == "" {
+		return "", "", fmt.Errorf("%w: new password is empty", ErrInvalidArgument)
+	}
+	u, err := s.checkPassword(ctx, userID, currentPassword)
+	if err != nil {
+		return "", "", err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := s.userRepo.UpdatePassword(ctx, userID, string(hashedPassword)); err != nil {
+		return "", "", fmt.Errorf("failed to update password: %w", err)
+	}
+
+	var client ClientInfo
+	if sessionID != "" {
+		if current, _, err := s.sessionRepo.Get(ctx, sessionID); err == nil {
+			client = ClientInfo{DeviceName: current.DeviceName, UserAgent: current.UserAgent, IP: current.IP}
+		}
+	}
+	if err := s.revokeIssuedTokens(ctx, userID); err != nil {
+		return "", "", err
+	}
+
+	return s.startSession(ctx, u, client)
+}
+
+// ChangeEmail меняет адрес после проверки пароля. Новый адрес считается неподтверждённым,
+// на него уходит письмо подтверждения, а на прежний — уведомление о смене.
+func (s *AuthService) ChangeEmail(ctx context.Context, userID uint32, currentPassword, newEmail string) error {
+	newEmail = strings.TrimSpace(newEmail)
+	if !emailRegex.MatchString(newEmail) {
+		return fmt.Errorf("%w: invalid email format", ErrInvalidArgument)
+	}
+	u, err := s.checkPassword(ctx, userID, currentPassword)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(u.Email, newEmail) {
+		return fmt.Errorf("%w: new email is the same as the current one", ErrInvalidArgument)
+	}
+
+	if err := s.userRepo.UpdateEmail(ctx, userID, newEmail); err != nil {
+		if errors.Is(err, userRepo.ErrConflict) {
+			return ErrEmailTaken
+		}
+		return fmt.Errorf("failed to update email: %w", err)
+	}
+	if err := s.sendVerification(ctx, userID, newEmail); err != nil {
+		log.Printf("[AuthService.ChangeEmail] failed to send verification to user %d: %v", userID, err)
+	}
+	notice := mail.Message{
+		To:      u.Email,
+		Subject: "Your email address was changed",
+		Body:    "The email address of your account was changed. If you did not do this, reset your password.",
+	}
+	if err := s.mailer.Send(ctx, notice); err != nil {
+		log.Printf("[AuthService.ChangeEmail] failed to notify previous address of user %d: %v", userID, err)
+	}
+	return nil
+}
+
+func validateUsername(username string) error {
+	if !usernameRegex.MatchString(username) {
+		return fmt.Errorf("%w: username must be 3 to 32 characters: letters, digits, '.', '_' or '-'", ErrInvalidArgument)
+	}
+	return nil
+}
+
+func (s *AuthService) ChangeUsername(ctx context.Context, userID uint32, username string) error {
+	username = strings.TrimSpace(username)
+	if err := validateUsername(username); err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdateUsername(ctx, userID, username); err != nil {
+		if errors.Is(err, userRepo.ErrConflict) {
+			return ErrUsernameTaken
+		}
+		return fmt.Errorf("failed to update username: %w", err)
+	}
+	return nil
+}
+
+func (s *AuthService) checkPassword(ctx context.Context, userID uint32, password string) (*user.User, error) {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)); err != nil {
+		return nil, ErrWrongPassword
+	}
+	return u, nil
+}
@@ -0,0 +1,47 @@
+package authService_test
+
+import (
+	"context"
+	"registration-service/internal/service/authService"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateProfile_RejectsInvalidInput(t *testing.T) {
+	s := setupService(t)
+	ctx := context.Background()
+
+	_, err := s.UpdateProfile(ctx, 1, strings.Repeat("я", 101), "")
+	assert.ErrorIs(t, err, authService.ErrInvalidArgument)
+
+	for _, avatar := range []string{"javascript:alert(1)", "/relative/avatar.png", "https://", "https://cdn.example.com/" + strings.Repeat("a", 2048)} {
+		_, err = s.UpdateProfile(ctx, 1, "", avatar)
+		assert.ErrorIs(t, err, authService.ErrInvalidArgument, avatar)
+	}
+}
+
+func TestChangeEmail_RejectsInvalidAddress(t *testing.T) {
+	s := setupService(t)
+	err := s.ChangeEmail(context.Background(), 1, "password", "not-an-email")
+	assert.ErrorIs(t, err, authService.ErrInvalidArgument)
+}
+
+func TestUsernameValidation_SharedByRegisterAndChangeUsername(t *testing.T) {
+	s, env := setupEnv(t)
+	ctx := context.Background()
+	u := env.users.add(t, "alice", "alice@example.com", "password")
+
+	for _, username := range []string{"", "ab", strings.Repeat("a", 33), "has space", "имя", "bob<script>", "a/b"} {
+		_, err := s.Register(ctx, username, "new@example.com", "password")
+		assert.ErrorIs(t, err, authService.ErrInvalidArgument, "register %q", username)
+		err = s.ChangeUsername(ctx, uint32(u.ID), username)
+		assert.ErrorIs(t, err, authService.ErrInvalidArgument, "change to %q", username)
+	}
+
+	_, err := s.Register(ctx, " bob.smith-2_ ", "bob@example.com", "password")
+	assert.NoError(t, err)
+	assert.ErrorIs(t, s.ChangeUsername(ctx, uint32(u.ID), "bob.smith-2_"), authService.ErrUsernameTaken)
+	assert.NoError(t, s.ChangeUsername(ctx, uint32(u.ID), "alice_2"))
+}
@@ -0,0 +1,189 @@
+package authService
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"registration-service/internal/model/session"
+	"registration-service/internal/model/user"
+	"registration-service/internal/repository/BlackListRepo"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrInvalidRefreshToken = errors.New("expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, session revoked")
+)
+
+// ClientInfo описывает устройство, с которого выполняется вход; сохраняется в сессии.
+type ClientInfo struct {
+	DeviceName string
+	UserAgent  string
+	IP         string
+}
+
+// accessClaims — содержимое access-токена. sid связывает токен с сессией: после отзыва сессии
+// GetUIDByToken перестаёт его принимать. У токенов, выданных до появления сессий, sid пуст.
+type accessClaims struct {
+	SessionID string `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Refresh-токен имеет вид <sessionID>.<секрет>; в сессии хранится только хеш секрета.
+func generateRefreshSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashRefreshSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func splitRefreshToken(token string) (string, string, bool) {
+	sessionID, secret, ok := strings.Cut(token, ".")
+	if !ok || sessionID == "" || secret == "" {
+		return "", "", false
+	}
+	return sessionID, secret, true
+}
+
+// startSession заводит сессию для нового входа и выдаёт её токены.
+func (s *AuthService) startSession(ctx context.Context, u *user.User, client ClientInfo) (string, string, error) {
+	secret, err := generateRefreshSecret()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	now := time.Now()
+	sess := &session.Session{
+		ID:         uuid.NewString(),
+		UserID:     uint32(u.ID),
+		DeviceName: client.DeviceName,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}
+	if err := s.sessionRepo.Create(ctx, sess, hashRefreshSecret(secret), refreshTokenExpireTime); err != nil {
+		return "", "", fmt.Errorf("failed to save session: %w", err)
+	}
+	accessToken, err := s.generateJWT(u, sess.ID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate access token: %w", err)
+	}
+	return accessToken, sess.ID + "." + secret, nil
+}
+
+// ListSessions возвращает активные сессии пользователя.
+func (s *AuthService) ListSessions(ctx context.Context, userID uint32) ([]*session.Session, error) {
+	sessions, err := s.sessionRepo.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession завершает одну сессию пользователя: её refresh-токен больше не обновляется, а
+// sid сессии попадает в чёрный список, так что её access-токены отклоняются и здесь, и в
+// сервисах, проверяющих токены локально (там — как только дойдёт сообщение из канала отзывов).
+func (s *AuthService) RevokeSession(ctx context.Context, userID uint32, sessionID string) error {
+	deleted, err := s.sessionRepo.Delete(ctx, userID, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if !deleted {
+		return ErrSessionNotFound
+	}
+	return s.revokeSessionTokens(ctx, sessionID)
+}
+
+// RevokeAllOtherSessions завершает все сессии пользователя, кроме текущей, так же как
+// RevokeSession, и возвращает их число.
+func (s *AuthService) RevokeAllOtherSessions(ctx context.Context, userID uint32, currentSessionID string) (int64, error) {
+	revoked, err := s.sessionRepo.DeleteAll(ctx, userID, currentSessionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if err := s.revokeSessionTokens(ctx, revoked...); err != nil {
+		return 0, err
+	}
+	return int64(len(revoked)), nil
+}
+
+// revokeSessionTokens заносит sid сессий в чёрный список на срок жизни access-токена: более
+// старые токены сессии к этому времени истекут сами.
+func (s *AuthService) revokeSessionTokens(ctx context.Context, sessionIDs ...string) error {
+	expiresAt := time.Now().Add(jwtTokenExpireTime)
+	for _, sessionID := range sessionIDs {
+		if err := s.blacklistRepo.AddToken(ctx, BlackListRepo.SessionRevocationID(sessionID), expiresAt); err != nil {
+			return fmt.Errorf("failed to revoke access tokens of session %s: %w", sessionID, err)
+		}
+	}
+	return nil
+}
+
+// RevokeAllTokens отзывает все токены пользователя userID (0 — самого вызывающего): access-токены,
+// выданные до этого момента, больше не принимаются ни здесь, ни при локальной проверке, сессии
+// завершаются. Свои токены можно отозвать самому, чужие — только администратору.
+func (s *AuthService) RevokeAllTokens(ctx context.Context, callerID, userID uint32) error {
+	if userID == 0 {
+		userID = callerID
+	}
+	if userID != callerID {
+		if err := s.requireAdmin(ctx, callerID); err != nil {
+			return err
+		}
+		log.Printf("[AuthService.RevokeAllTokens] admin %d revoked all tokens of user %d", callerID, userID)
+	}
+	return s.revokeIssuedTokens(ctx, userID)
+}
+
+// revokeIssuedTokens ставит отметку отзыва для access-токенов и завершает все сессии.
+func (s *AuthService) revokeIssuedTokens(ctx context.Context, userID uint32) error {
+	if err := s.blacklistRepo.RevokeIssuedBefore(ctx, userID, time.Now(), jwtTokenExpireTime); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+	if err := s.revokeAllSessions(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// revokeAllSessions завершает все сессии пользователя, включая refresh-токен старого формата.
+// Их access-токены отзывает отметка, которую ставит revokeIssuedTokens.
+func (s *AuthService) revokeAllSessions(ctx context.Context, userID uint32) error {
+	if _, err := s.sessionRepo.DeleteAll(ctx, userID, ""); err != nil {
+		return err
+	}
+	return s.refreshRepo.DeleteToken(ctx, userID)
+}
+
+// refreshLegacyToken принимает refresh-токен, выданный до появления сессий, и переводит его
+// владельца на сессию. Старый ключ удаляется, так что токен срабатывает один раз.
+func (s *AuthService) refreshLegacyToken(ctx context.Context, userID uint32, token string) (string, string, error) {
+	valid, err := s.refreshRepo.ValidateToken(ctx, userID, token)
+	if err != nil || !valid {
+		return "", "", ErrInvalidRefreshToken
+	}
+	if err := s.refreshRepo.DeleteToken(ctx, userID); err != nil {
+		return "", "", fmt.Errorf("failed to delete refresh token: %w", err)
+	}
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+	return s.startSession(ctx, u, ClientInfo{})
+}
@@ -0,0 +1,197 @@
+package authService_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"registration-service/internal/jwtkeys"
+	"registration-service/internal/model/user"
+	"registration-service/internal/service/authService"
+	"registration-service/internal/tokenverify"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fileVerifier проверяет токены так, как это делает файловый сервис: по JWKS и копии чёрного
+// списка. Копия синхронизируется явно перед каждой проверкой вместо подписки на канал.
+type fileVerifier struct {
+	verifier    *tokenverify.Verifier
+	revocations *tokenverify.RevocationList
+}
+
+func newFileVerifier(t *testing.T, s *authService.AuthService) *fileVerifier {
+	revocations := tokenverify.NewRevocationList(s.BlacklistRepo())
+	source := func(ctx context.Context) (jwtkeys.JWKS, error) { return s.JWKS(), nil }
+	verifier := tokenverify.New(source, nil, revocations, tokenverify.Config{})
+	require.NoError(t, verifier.Refresh(context.Background()))
+	return &fileVerifier{verifier: verifier, revocations: revocations}
+}
+
+func (v *fileVerifier) accepts(t *testing.T, token string) bool {
+	ctx := context.Background()
+	require.NoError(t, v.revocations.Sync(ctx))
+	_, err := v.verifier.Verify(ctx, token)
+	return err == nil
+}
+
+func TestSessions_LogoutEndsOnlyCurrentSession(t *testing.T) {
+	s := setupService(t)
+	ctx := context.Background()
+	u := &user.User{ID: 11}
+
+	laptop, _, err := s.StartSession(ctx, u, authService.ClientInfo{DeviceName: "laptop"})
+	require.NoError(t, err)
+	phone, _, err := s.StartSession(ctx, u, authService.ClientInfo{DeviceName: "phone"})
+	require.NoError(t, err)
+
+	sessions, err := s.ListSessions(ctx, 11)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 2)
+
+	// второй access-токен той же сессии, как после обновления
+	laptopSession := sessionOf(t, s, laptop)
+	laptopRefreshed, err := s.GenerateJWTForSession(u, laptopSession)
+	require.NoError(t, err)
+	files := newFileVerifier(t, s)
+	require.True(t, files.accepts(t, laptopRefreshed))
+
+	require.NoError(t, s.Logout(ctx, 11, laptop))
+	_, valid := s.GetUIDByToken(ctx, laptop)
+	assert.False(t, valid)
+	assert.False(t, files.accepts(t, laptopRefreshed), "file service rejects every token of the session")
+	assert.True(t, files.accepts(t, phone))
+	uid, valid := s.GetUIDByToken(ctx, phone)
+	assert.True(t, valid, "other device stays signed in")
+	assert.Equal(t, uint32(11), uid)
+
+	sessions, err = s.ListSessions(ctx, 11)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "phone", sessions[0].DeviceName)
+}
+
+func TestSessions_Revoke(t *testing.T) {
+	s := setupService(t)
+	ctx := context.Background()
+	u := &user.User{ID: 12}
+
+	current, _, err := s.StartSession(ctx, u, authService.ClientInfo{DeviceName: "current"})
+	require.NoError(t, err)
+	other, _, err := s.StartSession(ctx, u, authService.ClientInfo{DeviceName: "other"})
+	require.NoError(t, err)
+	stranger, _, err := s.StartSession(ctx, &user.User{ID: 13}, authService.ClientInfo{})
+	require.NoError(t, err)
+	files := newFileVerifier(t, s)
+	require.True(t, files.accepts(t, other))
+
+	_, strangerSession, ok := s.GetSessionByToken(ctx, stranger)
+	require.True(t, ok)
+	assert.ErrorIs(t, s.RevokeSession(ctx, 12, strangerSession), authService.ErrSessionNotFound)
+
+	_, currentSession, ok := s.GetSessionByToken(ctx, current)
+	require.True(t, ok)
+	revoked, err := s.RevokeAllOtherSessions(ctx, 12, currentSession)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, revoked)
+	_, valid := s.GetUIDByToken(ctx, other)
+	assert.False(t, valid, "access tokens of a revoked session are rejected")
+	assert.False(t, files.accepts(t, other), "file service rejects them too")
+	assert.True(t, files.accepts(t, current))
+
+	require.NoError(t, s.RevokeSession(ctx, 12, currentSession))
+	_, valid = s.GetUIDByToken(ctx, current)
+	assert.False(t, valid)
+	assert.False(t, files.accepts(t, current))
+	_, valid = s.GetUIDByToken(ctx, stranger)
+	assert.True(t, valid)
+	assert.True(t, files.accepts(t, stranger))
+}
+
+func TestRefreshToken_RejectsForeignSession(t *testing.T) {
+	s := setupService(t)
+	ctx := context.Background()
+
+	_, refresh, err := s.StartSession(ctx, &user.User{ID: 14}, authService.ClientInfo{})
+	require.NoError(t, err)
+	_, _, err = s.RefreshToken(ctx, 15, refresh)
+	assert.ErrorIs(t, err, authService.ErrInvalidRefreshToken)
+	_, _, err = s.RefreshToken(ctx, 14, refresh+"x")
+	assert.ErrorIs(t, err, authService.ErrInvalidRefreshToken)
+}
+
+func TestRefreshToken_ReuseRevokesSession(t *testing.T) {
+	s := setupService(t)
+	ctx := context.Background()
+
+	u := &user.User{ID: 16}
+	access, stolen, err := s.StartSession(ctx, u, authService.ClientInfo{})
+	require.NoError(t, err)
+	sessionID, secret, _ := strings.Cut(stolen, ".")
+	// access-токен, который вор получил, обновив токены украденным refresh-токеном
+	thiefAccess, err := s.GenerateJWTForSession(u, sessionID)
+	require.NoError(t, err)
+	files := newFileVerifier(t, s)
+	require.True(t, files.accepts(t, thiefAccess))
+
+	// владелец уже обновил токены: украденный refresh-токен заменён
+	sess, _, err := s.SessionRepo().Get(ctx, sessionID)
+	require.NoError(t, err)
+	sum := sha256.Sum256([]byte(secret))
+	_, err = s.SessionRepo().Rotate(ctx, sess, hex.EncodeToString(sum[:]), "next", time.Hour)
+	require.NoError(t, err)
+
+	_, _, err = s.RefreshToken(ctx, 16, stolen)
+	assert.ErrorIs(t, err, authService.ErrRefreshTokenReused)
+	_, valid := s.GetUIDByToken(ctx, access)
+	assert.False(t, valid, "the whole session is revoked")
+	assert.False(t, files.accepts(t, thiefAccess), "file service rejects access tokens of the family")
+	assert.False(t, files.accepts(t, access))
+	sessions, err := s.ListSessions(ctx, 16)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+func TestRevokeAllTokens(t *testing.T) {
+	s := setupService(t)
+	ctx := context.Background()
+	u := &user.User{ID: 17}
+
+	// токен, выданный до отзыва; iat с точностью до секунды, поэтому берём заведомо раньше
+	old, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   "17",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		IssuedAt:  jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+	}).SignedString([]byte("test-jwt-secret"))
+	require.NoError(t, err)
+	session, _, err := s.StartSession(ctx, u, authService.ClientInfo{})
+	require.NoError(t, err)
+	other, _, err := s.StartSession(ctx, &user.User{ID: 18}, authService.ClientInfo{})
+	require.NoError(t, err)
+
+	require.NoError(t, s.RevokeAllTokens(ctx, 17, 0))
+	_, valid := s.GetUIDByToken(ctx, old)
+	assert.False(t, valid)
+	_, valid = s.GetUIDByToken(ctx, session)
+	assert.False(t, valid)
+	sessions, err := s.ListSessions(ctx, 17)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	_, valid = s.GetUIDByToken(ctx, other)
+	assert.True(t, valid, "other users are not affected")
+	fresh, _, err := s.StartSession(ctx, u, authService.ClientInfo{})
+	require.NoError(t, err)
+	_, valid = s.GetUIDByToken(ctx, fresh)
+	assert.True(t, valid, "tokens issued after the revocation are accepted")
+}
+
+func sessionOf(t *testing.T, s *authService.AuthService, token string) string {
+	_, sessionID, ok := s.GetSessionByToken(context.Background(), token)
+	require.True(t, ok)
+	return sessionID
+}
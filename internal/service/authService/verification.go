@@ -0,0 +1,107 @@
+package authService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"registration-service/internal/mail"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const verificationAudience = "email-verification"
+
+var (
+	ErrEmailNotVerified         = errors.New("email is not verified")
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+)
+
+type verificationClaims struct {
+	Email string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// verificationKey отличается от ключа access-токенов, поэтому токен подтверждения нельзя
+// предъявить вместо access-токена и наоборот.
+func (s *AuthService) verificationKey() []byte {
+	if s.verification.Secret != "" {
+		return []byte(s.verification.Secret)
+	}
+	return []byte(verificationAudience + ":" + s.jwtSecretKey)
+}
+
+func (s *AuthService) generateVerificationToken(userID uint32, email string) (string, error) {
+	now := time.Now()
+	claims := verificationClaims{
+		Email: email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			Audience:  jwt.ClaimStrings{verificationAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.verification.TokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.verificationKey())
+}
+
+func (s *AuthService) sendVerification(ctx context.Context, userID uint32, email string) error {
+	token, err := s.generateVerificationToken(userID, email)
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+	body := "Confirm your email address with this token:\n\n" + token
+	if s.verification.URL != "" {
+		body = "Confirm your email address by opening this link:\n\n" + s.verification.URL + "?token=" + url.QueryEscape(token)
+	}
+	body += fmt.Sprintf("\n\nThe token expires in %s. If you did not register, ignore this email.", s.verification.TokenTTL)
+	return s.mailer.Send(ctx, mail.Message{To: email, Subject: "Confirm your email address", Body: body})
+}
+
+// VerifyEmail подтверждает адрес по токену из письма. Повторное подтверждение не ошибка.
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) (uint32, error) {
+	claims := &verificationClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return s.verificationKey(), nil
+	}, jwt.WithAudience(verificationAudience), jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !parsed.Valid {
+		return 0, ErrInvalidVerificationToken
+	}
+	uid, err := strconv.ParseUint(claims.Subject, 10, 32)
+	if err != nil {
+		return 0, ErrInvalidVerificationToken
+	}
+
+	verified, err := s.userRepo.MarkEmailVerified(ctx, uint32(uid), claims.Email)
+	if err != nil {
+		return 0, fmt.Errorf("failed to verify email: %w", err)
+	}
+	if !verified {
+		// адрес сменился после выдачи токена или пользователя нет
+		return 0, ErrInvalidVerificationToken
+	}
+	return uint32(uid), nil
+}
+
+// ResendVerification отправляет письмо повторно. Ответ не зависит от того, есть ли такой
+// адрес и подтверждён ли он, чтобы по нему нельзя было перебирать аккаунты.
+func (s *AuthService) ResendVerification(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil || user == nil || user.EmailVerified {
+		return nil
+	}
+	allowed, err := s.verificationRepo.AllowResend(ctx, uint32(user.ID), s.verification.ResendInterval)
+	if err != nil {
+		return fmt.Errorf("failed to check resend interval: %w", err)
+	}
+	if !allowed {
+		return nil
+	}
+	if err := s.sendVerification(ctx, uint32(user.ID), user.Email); err != nil {
+		log.Printf("[AuthService.ResendVerification] failed to send verification to user %d: %v", user.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package authService_test
+
+import (
+	"context"
+	"registration-service/internal/model/user"
+	"registration-service/internal/service/authService"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerificationToken_NotInterchangeableWithAccessToken(t *testing.T) {
+	s := setupService(t)
+	ctx := context.Background()
+
+	verification, err := s.GenerateVerificationToken(5, "user@example.com")
+	require.NoError(t, err)
+	_, valid := s.GetUIDByToken(ctx, verification)
+	assert.False(t, valid, "verification token must not authenticate requests")
+
+	access, err := s.GenerateJWT(&user.User{ID: 5})
+	require.NoError(t, err)
+	_, err = s.VerifyEmail(ctx, access)
+	assert.ErrorIs(t, err, authService.ErrInvalidVerificationToken)
+}
+
+func TestVerifyEmail_RejectsMalformedToken(t *testing.T) {
+	s := setupService(t)
+	_, err := s.VerifyEmail(context.Background(), "not-a-token")
+	assert.ErrorIs(t, err, authService.ErrInvalidVerificationToken)
+}
+
+func TestVerifyEmail_MarksAddressVerified(t *testing.T) {
+	s, env := setupEnv(t)
+	ctx := context.Background()
+	u := env.users.add(t, "alice", "alice@example.com", "secret")
+
+	require.NoError(t, s.ResendVerification(ctx, "alice@example.com"))
+	sent := env.mailer.messages()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "alice@example.com", sent[0].To)
+
+	uid, err := s.VerifyEmail(ctx, tokenFromMail(t, sent[0].Body))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(u.ID), uid)
+	verified, err := env.users.GetByID(ctx, uid)
+	require.NoError(t, err)
+	assert.True(t, verified.EmailVerified)
+
+	// уже подтверждённому адресу письмо повторно не отправляется
+	require.NoError(t, s.ResendVerification(ctx, "alice@example.com"))
+	assert.Len(t, env.mailer.messages(), 1)
+}
+
+// tokenFromMail достаёт токен из письма без URL: он стоит отдельным абзацем после первой строки.
+func tokenFromMail(t *testing.T, body string) string {
+	parts := strings.Split(body, "\n\n")
+	require.GreaterOrEqual(t, len(parts), 2, "unexpected mail body: %s", body)
+	return strings.TrimSpace(parts[1])
+}
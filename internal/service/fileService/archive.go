@@ -0,0 +1,247 @@
+package fileService
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"registration-service/internal/archive"
+	"registration-service/internal/model/fileInfo"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	archiveManifestName = "manifest.json"
+	maxArchiveFiles     = 1000
+)
+
+var (
+	ErrInvalidArchiveRequest = errors.New("invalid archive request")
+	ErrArchiveRejected       = errors.New("archive rejected")
+)
+
+type archiveManifest struct {
+	Files   []archiveManifestFile `json:"files"`
+	Skipped []archiveSkippedFile  `json:"skipped"`
+}
+
+type archiveManifestFile struct {
+	FileID  string `json:"file_id"`
+	Name    string `json:"name"`
+	Entry   string `json:"entry"`
+	Version uint32 `json:"version"`
+	Size    int64  `json:"size"`
+}
+
+type archiveSkippedFile struct {
+	FileID string `json:"file_id"`
+	Reason string `json:"reason"`
+}
+
+// WriteArchive пишет в w ZIP с текущими версиями файлов. Объекты копируются из хранилища
+// потоком, без буферизации целиком. Файлы без доступа пропускаются и перечисляются в
+// manifest.json — последней записи архива.
+func (s *FileService) WriteArchive(ctx context.Context, rawFileIDs []string, w io.Writer) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get user ID: %v", err)
+	}
+	if len(rawFileIDs) == 0 {
+		return fmt.Errorf("%w: no files requested", ErrInvalidArchiveRequest)
+	}
+	if len(rawFileIDs) > maxArchiveFiles {
+		return fmt.Errorf("%w: at most %d files per archive", ErrInvalidArchiveRequest, maxArchiveFiles)
+	}
+
+	zw := zip.NewWriter(w)
+	names := archive.NewNameSet()
+	names.Reserve(archiveManifestName)
+	manifest := archiveManifest{Files: []archiveManifestFile{}, Skipped: []archiveSkippedFile{}}
+	seen := make(map[uuid.UUID]struct{}, len(rawFileIDs))
+
+	for _, rawID := range rawFileIDs {
+		fileID, err := uuid.Parse(rawID)
+		if err != nil {
+			manifest.Skipped = append(manifest.Skipped, archiveSkippedFile{FileID: rawID, Reason: "invalid file id"})
+			continue
+		}
+		if _, dup := seen[fileID]; dup {
+			continue
+		}
+		seen[fileID] = struct{}{}
+
+		file, version, reason, err := s.archivableVersion(ctx, fileID, userID)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			manifest.Skipped = append(manifest.Skipped, archiveSkippedFile{FileID: rawID, Reason: reason})
+			continue
+		}
+
+		entry := names.Unique(file.Name)
+		if err := s.writeArchiveEntry(ctx, zw, entry, version); err != nil {
+			return fmt.Errorf("failed to add %s to archive: %w", fileID, err)
+		}
+		manifest.Files = append(manifest.Files, archiveManifestFile{
+			FileID:  fileID.String(),
+			Name:    file.Name,
+			Entry:   entry,
+			Version: version.VersionNumber,
+			Size:    version.Size,
+		})
+	}
+
+	manifestWriter, err := zw.Create(archiveManifestName)
+	if err != nil {
+		return fmt.Errorf("failed to add manifest: %w", err)
+	}
+	encoder := json.NewEncoder(manifestWriter)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return zw.Close()
+}
+
+// archivableVersion повторяет проверки DownloadFile; непустой reason означает, что файл
+// пропускается. Ошибка означает сбой хранилища метаданных: такой файл нельзя молча пропустить,
+// и архив целиком не собирается.
+func (s *FileService) archivableVersion(ctx context.Context, fileID uuid.UUID, userID uint32) (*fileInfo.File, *fileInfo.FileVersion, string, error) {
+	file, err := s.fileRepo.GetFileByID(ctx, fileID)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to get file %s: %w", fileID, err)
+	}
+	if file == nil {
+		return nil, nil, "file not found", nil
+	}
+	hasAccess, err := s.checkFileAccess(ctx, fileID, int(userID), PermissionRead)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to check access to %s: %w", fileID, err)
+	}
+	if !hasAccess {
+		return nil, nil, "access denied", nil
+	}
+	version, err := s.currentVersion(ctx, fileID)
+	if errors.Is(err, errVersionNotFound) || errors.Is(err, ErrScanPending) || errors.Is(err, ErrFileInfected) {
+		return nil, nil, err.Error(), nil
+	}
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to get version of %s: %w", fileID, err)
+	}
+	return file, version, "", nil
+}
+
+func (s *FileService) writeArchiveEntry(ctx context.Context, zw *zip.Writer, entry string, version *fileInfo.FileVersion) error {
+	reader, err := s.store.Open(ctx, version)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	header := &zip.FileHeader{
+		Name:     entry,
+		Method:   zip.Deflate,
+		Modified: version.CreatedAt,
+	}
+	if archive.IsPrecompressed(version.ContentType) {
+		header.Method = zip.Store
+	}
+	entryWriter, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entryWriter, reader)
+	return err
+}
+
+// UploadArchive распаковывает ZIP или tar.gz в отдельные файлы. Архив сначала сохраняется во
+// временный файл и проверяется целиком (пути, число записей, размеры, степень сжатия), так что
+// отвергнутый архив не оставляет после себя ни одного файла. Каждая запись затем проходит
+// через UploadFile, как обычная загрузка. Каталогов в хранилище нет, поэтому относительный путь
+// записи становится именем файла.
+func (s *FileService) UploadArchive(ctx context.Context, name, format string, data io.Reader) ([]*fileInfo.ArchiveEntryResult, error) {
+	if _, err := getUserIDFromContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get user ID: %v", err)
+	}
+	format, err := archive.DetectFormat(format, name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrArchiveRejected, err)
+	}
+
+	spool, err := os.CreateTemp("", "upload-archive-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	size, err := io.Copy(spool, io.LimitReader(data, s.archiveLimits.MaxUploadBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to receive archive: %w", err)
+	}
+	if size > s.archiveLimits.MaxUploadBytes {
+		return nil, fmt.Errorf("%w: archive is larger than %d bytes", ErrArchiveRejected, s.archiveLimits.MaxUploadBytes)
+	}
+
+	if err := archive.Validate(format, spool, size, s.archiveLimits); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrArchiveRejected, err)
+	}
+
+	var results []*fileInfo.ArchiveEntryResult
+	err = archive.Walk(format, spool, size, s.archiveLimits, func(entry archive.Entry, r io.Reader) error {
+		if !entry.Regular {
+			if !entry.Dir {
+				results = append(results, &fileInfo.ArchiveEntryResult{
+					Path:   entry.Path,
+					Status: fileInfo.ArchiveEntrySkipped,
+					Error:  "not a regular file",
+				})
+			}
+			return nil
+		}
+		// запись идёт в хранилище потоком; для типа содержимого хватает первых байт
+		buffered := bufio.NewReader(r)
+		head, err := buffered.Peek(512)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		result := &fileInfo.ArchiveEntryResult{Path: entry.Path}
+		file, err := s.UploadFile(ctx, entry.Path, detectContentType(entry.Path, head), buffered, entry.Size)
+		if err != nil {
+			log.Printf("[FileService.UploadArchive] failed to store entry %s: %v", entry.Path, err)
+			result.Status = fileInfo.ArchiveEntryFailed
+			result.Error = err.Error()
+			// Walk учитывает лимиты по прочитанным байтам, поэтому запись дочитывается
+			if _, err := io.Copy(io.Discard, buffered); err != nil {
+				return err
+			}
+		} else {
+			result.Status = fileInfo.ArchiveEntryCreated
+			result.FileID = file.ID
+		}
+		results = append(results, result)
+		return nil
+	})
+	if err != nil {
+		return results, fmt.Errorf("failed to unpack archive: %w", err)
+	}
+	return results, nil
+}
+
+func detectContentType(name string, content []byte) string {
+	if byExt := mime.TypeByExtension(strings.ToLower(path.Ext(name))); byExt != "" {
+		return byExt
+	}
+	return http.DetectContentType(content)
+}
@@ -6,10 +6,20 @@ import (
 	"fmt"
 	"io"
 	auth "registration-service/api/authproto/proto-generate"
-	"registration-service/internal/MinIO"
+	"registration-service/internal/archive"
 	"registration-service/internal/model/fileInfo"
-	"registration-service/internal/repository/fileRepo"
+	"registration-service/internal/model/webhookInfo"
+	"registration-service/internal/repository/exportRepo"
+	"registration-service/internal/repository/webhookRepo"
+	"registration-service/internal/scan"
+	"registration-service/internal/search"
+	"registration-service/internal/storage"
+	"registration-service/internal/takeout"
+	"registration-service/internal/thumbnail"
+	"registration-service/internal/tiering"
+	"registration-service/internal/versionStore"
 	"strconv"
+	"strings"
 	"time"
 
 	"log"
@@ -25,17 +35,71 @@ const (
 	// PermissionDelete = 3
 )
 
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+var (
+	ErrEmptySearchQuery = errors.New("search query is empty")
+	ErrScanPending      = errors.New("file is waiting for a malware scan")
+	ErrFileInfected     = errors.New("file is quarantined as infected")
+)
+
+// FileRepository — часть fileRepo.FileRepository, которая нужна сервису.
+type FileRepository interface {
+	ActivateVersion(ctx context.Context, version *fileInfo.FileVersion) (bool, error)
+	CheckUserPermission(ctx context.Context, fileID uuid.UUID, userID int) (int, error)
+	CreateFileVersion(ctx context.Context, version *fileInfo.FileVersion) error
+	CreatePendingUpload(ctx context.Context, file *fileInfo.File, version *fileInfo.FileVersion) error
+	DeleteFile(ctx context.Context, fileID uuid.UUID) error
+	DeletePendingVersion(ctx context.Context, versionID uint32) error
+	GetCurrentFileVersion(ctx context.Context, fileID uuid.UUID) (*fileInfo.FileVersion, error)
+	GetFileByID(ctx context.Context, fileID uuid.UUID) (*fileInfo.File, error)
+	GetFileVersion(ctx context.Context, fileID uuid.UUID, version int) (*fileInfo.FileVersion, error)
+	GetFileVersions(ctx context.Context, fileID uuid.UUID) ([]*fileInfo.FileVersion, error)
+	GetLatestFileVersion(ctx context.Context, fileID uuid.UUID) (*fileInfo.FileVersion, error)
+	GetSharedFiles(ctx context.Context, userID int, filter fileInfo.FileFilter) ([]*fileInfo.File, error)
+	ListFilesByOwner(ctx context.Context, ownerID int, filter fileInfo.FileFilter) ([]*fileInfo.File, error)
+	LookupUser(ctx context.Context, userID uint32) (isAdmin bool, found bool, err error)
+	NextVersionNumber(ctx context.Context, fileID uuid.UUID) (int, error)
+	RenameFile(ctx context.Context, fileID uuid.UUID, newName string) error
+	SearchFiles(ctx context.Context, userID int, permission int, query string, limit, offset int) ([]*fileInfo.SearchResult, error)
+	SetFilePermissions(ctx context.Context, fileID uuid.UUID, permissions []fileInfo.FilePermission) error
+	SetFileProperties(ctx context.Context, fileID uuid.UUID, properties map[string]string) error
+	SetFileTags(ctx context.Context, fileID uuid.UUID, tags []string) error
+	SetVersionCreatedAt(ctx context.Context, versionID uint32, createdAt time.Time) error
+	TouchFile(ctx context.Context, fileID uuid.UUID) error
+}
+
 type FileService struct {
-	fileRepo   *fileRepo.FileRepository
-	authClient auth.AuthServiceClient
-	minIO      *MinIO.MinIOClient
+	fileRepo    FileRepository
+	authClient  auth.AuthServiceClient
+	store       *versionStore.VersionStore
+	webhookRepo *webhookRepo.WebhookRepository
+	indexer     *search.Indexer
+	thumbnails  *thumbnail.Worker
+	scanner     *scan.Worker
+	tiering     *tiering.Worker
+	exportRepo  *exportRepo.ExportRepository
+	exports     *takeout.Worker
+
+	archiveLimits archive.Config
 }
 
-func New(fileRepo *fileRepo.FileRepository, authClient auth.AuthServiceClient, minIO *MinIO.MinIOClient) *FileService {
+func New(fileRepo FileRepository, authClient auth.AuthServiceClient, store *versionStore.VersionStore, webhookRepo *webhookRepo.WebhookRepository, indexer *search.Indexer, thumbnails *thumbnail.Worker, scanner *scan.Worker, tiering *tiering.Worker, exportRepo *exportRepo.ExportRepository, exports *takeout.Worker, archiveLimits archive.Config) *FileService {
 	return &FileService{
-		fileRepo:   fileRepo,
-		authClient: authClient,
-		minIO:      minIO,
+		fileRepo:      fileRepo,
+		authClient:    authClient,
+		store:         store,
+		webhookRepo:   webhookRepo,
+		indexer:       indexer,
+		thumbnails:    thumbnails,
+		scanner:       scanner,
+		tiering:       tiering,
+		exportRepo:    exportRepo,
+		exports:       exports,
+		archiveLimits: archiveLimits,
 	}
 }
 
@@ -75,34 +139,44 @@ func (s *FileService) UploadFile(ctx context.Context, name string, content_type
 	fileID := uuid.New()
 	version := 1
 	storageKey := fmt.Sprintf("%s/v%d", fileID, version)
-	if err := s.minIO.UploadFile(ctx, storageKey, fileData, size, content_type); err != nil {
-		return nil, errors.New("upload file to minio error")
+	initialFileVersion := &fileInfo.FileVersion{
+		FileID:        fileID,
+		VersionNumber: uint32(version),
+		StorageKey:    storageKey,
+		Size:          size,
+		ContentType:   content_type,
+		CreatedAt:     time.Now(),
+		ScanStatus:    fileInfo.ScanPending,
 	}
+	// Текущей версия станет после проверки сканером, до этого файл нельзя скачать
 	file := &fileInfo.File{
 		ID:             fileID,
 		OwnerID:        userID,
 		Name:           name,
-		CurrentVersion: version,
+		CurrentVersion: 0,
 		CreatedAt:      time.Now(),
 	}
-	if err := s.fileRepo.CreateFile(ctx, file); err != nil {
-		_ = s.minIO.DeleteFile(ctx, storageKey)
-		return nil, fmt.Errorf("create file entry error: %w", err)
+	if err := s.store.Prepare(ctx, initialFileVersion); err != nil {
+		return nil, fmt.Errorf("failed to prepare file version: %w", err)
 	}
-
-	initialFileVersion := &fileInfo.FileVersion{
-		FileID:        fileID,
-		VersionNumber: uint32(version),
-		StorageKey:    storageKey,
-		Size:          size,
-		CreatedAt:     time.Now(),
+	// Строки создаются в pending до загрузки объекта: если процесс упадёт посередине,
+	// RecoveryWorker завершит или откатит загрузку, а в списках файл не появится
+	if err := s.fileRepo.CreatePendingUpload(ctx, file, initialFileVersion); err != nil {
+		return nil, fmt.Errorf("create file entry error: %w", err)
 	}
-	if err := s.fileRepo.CreateFileVersion(ctx, initialFileVersion); err != nil {
-		_ = s.minIO.DeleteFile(ctx, storageKey)
-		_ = s.fileRepo.DeleteFile(ctx, fileID)
-		return nil, fmt.Errorf("failed to create initial file version: %w", err)
+	if err := s.storeVersion(ctx, initialFileVersion, fileData); err != nil {
+		return nil, err
 	}
+	s.scanner.Enqueue(initialFileVersion)
 
+	s.publishEvent(ctx, []uint32{userID}, webhookInfo.EventFileUploaded, fileEventData{
+		FileID:      fileID.String(),
+		Name:        name,
+		OwnerID:     userID,
+		Version:     uint32(version),
+		Size:        size,
+		ContentType: content_type,
+	})
 	return file, nil
 }
 
@@ -122,20 +196,51 @@ func (s *FileService) DownloadFile(ctx context.Context, fileID uuid.UUID) (io.Re
 	if err != nil || !hasAccess {
 		return nil, nil, fmt.Errorf("access denied or error checking access: %w", err)
 	}
-	versionNum, err := s.fileRepo.GetLatestFileVersion(ctx, fileID)
+	versionNum, err := s.currentVersion(ctx, fileID)
 	if err != nil {
-		return nil, nil, errors.New("get latest file version error")
-	}
-	if versionNum == nil {
-		return nil, nil, errors.New("file version not found")
+		return nil, nil, err
 	}
-	reader, err := s.minIO.DownloadFile(ctx, versionNum.StorageKey)
+	reader, err := s.store.Open(ctx, versionNum)
 	if err != nil {
 		return nil, nil, errors.New("download file to minio error")
 	}
+	if err := s.fileRepo.TouchFile(ctx, fileID); err != nil {
+		log.Printf("[FileService.DownloadFile] failed to record access to %s: %v", fileID, err)
+	}
+	s.tiering.EnqueueRestore(versionNum)
 	return reader, file, nil
 }
 
+// GetThumbnail возвращает превью текущей версии с теми же проверками доступа, что и DownloadFile.
+// Размер округляется до ближайшего настроенного; фактический размер возвращается вторым значением.
+func (s *FileService) GetThumbnail(ctx context.Context, fileID uuid.UUID, size int) ([]byte, string, int, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("failed to get user ID: %v", err)
+	}
+	file, err := s.fileRepo.GetFileByID(ctx, fileID)
+	if err != nil {
+		return nil, "", 0, errors.New("get file error")
+	}
+	if file == nil {
+		return nil, "", 0, errors.New("file not found")
+	}
+	hasAccess, err := s.checkFileAccess(ctx, fileID, int(userID), PermissionRead)
+	if err != nil || !hasAccess {
+		return nil, "", 0, fmt.Errorf("access denied or error checking access: %w", err)
+	}
+	version, err := s.currentVersion(ctx, fileID)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	actualSize := s.thumbnails.PickSize(size)
+	data, contentType, err := s.thumbnails.Get(ctx, version, actualSize)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return data, contentType, actualSize, nil
+}
+
 func (s *FileService) DeleteFile(ctx context.Context, fileID uuid.UUID) error {
 	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
@@ -158,27 +263,42 @@ func (s *FileService) DeleteFile(ctx context.Context, fileID uuid.UUID) error {
 	if err := s.fileRepo.DeleteFile(ctx, fileID); err != nil {
 		return fmt.Errorf("failed to delete file: %w", err)
 	}
+	// Строки уже удалены, поэтому ошибка удаления отдельного объекта не прерывает цикл:
+	// оставшиеся объекты-сироты уберёт сверка хранилища (cmd/reconcile)
 	for _, versionToDelete := range versions {
-		if err := s.minIO.DeleteFile(ctx, versionToDelete.StorageKey); err != nil {
-			return fmt.Errorf("failed to delete file: %w", err)
+		if err := s.store.DeleteVersion(ctx, versionToDelete); err != nil {
+			log.Printf("[FileService.DeleteFile] failed to delete object %s: %v", versionToDelete.StorageKey, err)
+		}
+		if thumbnail.IsImage(versionToDelete.ContentType) {
+			for _, size := range s.thumbnails.Sizes() {
+				if err := s.store.Delete(ctx, thumbnail.Key(versionToDelete.StorageKey, size)); err != nil {
+					log.Printf("[FileService.DeleteFile] failed to delete thumbnail of %s: %v", versionToDelete.StorageKey, err)
+				}
+			}
 		}
 	}
+	s.publishEvent(ctx, []uint32{userID}, webhookInfo.EventFileDeleted, fileEventData{
+		FileID:  fileID.String(),
+		Name:    file.Name,
+		OwnerID: file.OwnerID,
+	})
 	return nil
 }
 
-func (s *FileService) ListFiles(ctx context.Context, includeShared bool) ([]*fileInfo.File, error) {
+func (s *FileService) ListFiles(ctx context.Context, includeShared bool, filter fileInfo.FileFilter) ([]*fileInfo.File, error) {
 	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user ID: %v", err)
 	}
+	filter.Tags = normalizeTags(filter.Tags)
 
-	files, err := s.fileRepo.ListFilesByOwner(ctx, int(userID))
+	files, err := s.fileRepo.ListFilesByOwner(ctx, int(userID), filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list user files: %w", err)
 	}
 
 	if includeShared {
-		sharedFiles, err := s.fileRepo.GetSharedFiles(ctx, int(userID))
+		sharedFiles, err := s.fileRepo.GetSharedFiles(ctx, int(userID), filter)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list shared files: %w", err)
 		}
@@ -265,6 +385,20 @@ func (s *FileService) SetFilePermissions(ctx context.Context, fileID uuid.UUID,
 	if err := s.fileRepo.SetFilePermissions(ctx, fileID, permissionsForRepo); err != nil {
 		return fmt.Errorf("failed to set file permissions via repo: %w", err)
 	}
+
+	// Событие получают и владелец, и пользователи, которым открыли доступ
+	recipients := []uint32{userID}
+	shared := make([]sharedWithData, len(permissionsForRepo))
+	for i, p := range permissionsForRepo {
+		shared[i] = sharedWithData{UserID: p.UserID, Permission: p.Permission}
+		recipients = append(recipients, uint32(p.UserID))
+	}
+	s.publishEvent(ctx, recipients, webhookInfo.EventFileShared, fileEventData{
+		FileID:     fileID.String(),
+		Name:       file.Name,
+		OwnerID:    file.OwnerID,
+		SharedWith: shared,
+	})
 	return nil
 }
 
@@ -313,37 +447,146 @@ func (s *FileService) RevertFileVersion(ctx context.Context, fileID uuid.UUID, v
 	if oldVersion == nil {
 		return nil, errors.New("file version not found")
 	}
-	newVersion := file.CurrentVersion + 1
-	newStorageKey := fmt.Sprintf("%s/v%d", fileID, newVersion)
-
-	reader, err := s.minIO.DownloadFile(ctx, oldVersion.StorageKey)
-	if err != nil {
-		return nil, fmt.Errorf("download file to minio error: %w", err)
-	}
-	if err := s.minIO.UploadFile(ctx, newStorageKey, reader, oldVersion.Size, ""); err != nil {
-		return nil, fmt.Errorf("upload file to minio error: %w", err)
+	if oldVersion.ScanStatus != fileInfo.ScanClean {
+		return nil, scanError(oldVersion)
 	}
-
-	file.CurrentVersion = newVersion
-	if err := s.fileRepo.UpdateCurrentVersion(ctx, file.ID, file.CurrentVersion); err != nil {
-		_ = s.minIO.DeleteFile(ctx, newStorageKey)
-		return nil, fmt.Errorf("failed to update file record: %w", err)
+	// Номер берётся от последней версии, включая ещё ждущие проверки или незавершённые загрузки
+	newVersion, err := s.fileRepo.NextVersionNumber(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest file version: %w", err)
 	}
+	newStorageKey := fmt.Sprintf("%s/v%d", fileID, newVersion)
 
 	newFileVers := &fileInfo.FileVersion{
 		FileID:        fileID,
 		VersionNumber: uint32(newVersion),
 		StorageKey:    newStorageKey,
 		Size:          oldVersion.Size,
+		ContentType:   oldVersion.ContentType,
 		CreatedAt:     time.Now(),
+		ScanStatus:    fileInfo.ScanPending,
+		Status:        fileInfo.StatusPending,
+	}
+	if err := s.store.Prepare(ctx, newFileVers); err != nil {
+		return nil, fmt.Errorf("failed to prepare file version: %w", err)
 	}
 	if err := s.fileRepo.CreateFileVersion(ctx, newFileVers); err != nil {
-		_ = s.minIO.DeleteFile(ctx, newStorageKey)
 		return nil, fmt.Errorf("failed to create new file version: %w", err)
 	}
+
+	// Новая версия получает собственный ключ данных, поэтому объект перешифровывается, а не копируется
+	reader, err := s.store.Open(ctx, oldVersion)
+	if err != nil {
+		s.abortUpload(ctx, newFileVers)
+		return nil, fmt.Errorf("download file to minio error: %w", err)
+	}
+	err = s.storeVersion(ctx, newFileVers, reader)
+	reader.Close()
+	if err != nil {
+		return nil, err
+	}
+	// current_version переключится на новую версию, когда сканер признает её чистой
+	s.scanner.Enqueue(newFileVers)
+
+	s.publishEvent(ctx, []uint32{userID}, webhookInfo.EventFileVersionCreated, fileEventData{
+		FileID:  fileID.String(),
+		Name:    file.Name,
+		OwnerID: file.OwnerID,
+		Version: uint32(newVersion),
+		Size:    oldVersion.Size,
+	})
 	return file, nil
 }
 
+func (s *FileService) SearchFiles(ctx context.Context, query string, limit, offset int) ([]*fileInfo.SearchResult, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user ID: %v", err)
+	}
+	if strings.TrimSpace(query) == "" {
+		return nil, ErrEmptySearchQuery
+	}
+	if limit <= 0 || limit > maxSearchLimit {
+		limit = defaultSearchLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	results, err := s.fileRepo.SearchFiles(ctx, int(userID), PermissionRead, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search files: %w", err)
+	}
+	return results, nil
+}
+
+// storeVersion загружает содержимое pending-версии, проверяет объект в хранилище и делает
+// версию активной. При ошибке загрузка откатывается.
+func (s *FileService) storeVersion(ctx context.Context, version *fileInfo.FileVersion, data io.Reader) error {
+	if err := s.store.Put(ctx, version, data); err != nil {
+		s.abortUpload(ctx, version)
+		return fmt.Errorf("upload file to minio error: %w", err)
+	}
+	if err := s.store.Confirm(ctx, version); err != nil {
+		s.abortUpload(ctx, version)
+		return fmt.Errorf("failed to confirm uploaded object: %w", err)
+	}
+	activated, err := s.fileRepo.ActivateVersion(ctx, version)
+	if err != nil {
+		// объект на месте, поэтому RecoveryWorker активирует версию, если откат тоже не удастся
+		s.abortUpload(ctx, version)
+		return fmt.Errorf("failed to activate file version: %w", err)
+	}
+	if !activated {
+		// строку уже откатил RecoveryWorker, объект больше ни на что не ссылается
+		if err := s.store.Delete(ctx, version.StorageKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			log.Printf("[FileService.storeVersion] failed to delete orphaned object %s: %v", version.StorageKey, err)
+		}
+		return errors.New("upload was rolled back before it completed")
+	}
+	return nil
+}
+
+// abortUpload откатывает незавершённую загрузку. Ошибки только логируются: оставшиеся
+// pending-строки подберёт RecoveryWorker, а осиротевший объект — сверка хранилища.
+func (s *FileService) abortUpload(ctx context.Context, version *fileInfo.FileVersion) {
+	if err := s.store.Delete(ctx, version.StorageKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("[FileService.abortUpload] failed to delete object %s: %v", version.StorageKey, err)
+	}
+	if err := s.fileRepo.DeletePendingVersion(ctx, version.ID); err != nil {
+		log.Printf("[FileService.abortUpload] failed to delete pending version %d: %v", version.ID, err)
+	}
+}
+
+// currentVersion возвращает текущую (проверенную сканером) версию файла. Если её ещё нет,
+// ошибка объясняет, в каком состоянии последняя версия.
+// errVersionNotFound — у файла нет ни одной загруженной версии.
+var errVersionNotFound = errors.New("file version not found")
+
+func (s *FileService) currentVersion(ctx context.Context, fileID uuid.UUID) (*fileInfo.FileVersion, error) {
+	version, err := s.fileRepo.GetCurrentFileVersion(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("get latest file version error: %w", err)
+	}
+	if version != nil {
+		return version, nil
+	}
+	latest, err := s.fileRepo.GetLatestFileVersion(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("get latest file version error: %w", err)
+	}
+	if latest == nil {
+		return nil, errVersionNotFound
+	}
+	return nil, scanError(latest)
+}
+
+func scanError(version *fileInfo.FileVersion) error {
+	if version.ScanStatus == fileInfo.ScanInfected {
+		return fmt.Errorf("%w: %s", ErrFileInfected, version.ScanResult)
+	}
+	return ErrScanPending
+}
+
 func (s *FileService) checkFileAccess(ctx context.Context, fileID uuid.UUID, userID int, requiredPermission int) (bool, error) {
 	file, err := s.fileRepo.GetFileByID(ctx, fileID)
 	if err != nil {
@@ -0,0 +1,118 @@
+package fileService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxTagsPerFile       = 50
+	maxPropertiesPerFile = 50
+	maxTagLength         = 64
+	maxPropertyKeyLength = 64
+	maxPropertyValueLen  = 255
+)
+
+var ErrInvalidMetadata = errors.New("invalid file metadata")
+
+func (s *FileService) SetFileTags(ctx context.Context, fileID uuid.UUID, tags []string) ([]string, error) {
+	if err := s.checkOwner(ctx, fileID, "only owner can change file tags"); err != nil {
+		return nil, err
+	}
+	tags, err := validateTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fileRepo.SetFileTags(ctx, fileID, tags); err != nil {
+		return nil, fmt.Errorf("failed to set file tags: %w", err)
+	}
+	return tags, nil
+}
+
+func (s *FileService) SetFileProperties(ctx context.Context, fileID uuid.UUID, properties map[string]string) error {
+	if err := s.checkOwner(ctx, fileID, "only owner can change file properties"); err != nil {
+		return err
+	}
+	if err := validateProperties(properties); err != nil {
+		return err
+	}
+	if err := s.fileRepo.SetFileProperties(ctx, fileID, properties); err != nil {
+		return fmt.Errorf("failed to set file properties: %w", err)
+	}
+	return nil
+}
+
+func (s *FileService) checkOwner(ctx context.Context, fileID uuid.UUID, deniedMsg string) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get user ID: %v", err)
+	}
+	file, err := s.fileRepo.GetFileByID(ctx, fileID)
+	if err != nil {
+		return fmt.Errorf("failed to get file: %w", err)
+	}
+	if file == nil {
+		return errors.New("file not found")
+	}
+	if file.OwnerID != userID {
+		return errors.New(deniedMsg)
+	}
+	return nil
+}
+
+// validateTags нормализует теги и проверяет их число и длину.
+func validateTags(tags []string) ([]string, error) {
+	tags = normalizeTags(tags)
+	if len(tags) > maxTagsPerFile {
+		return nil, fmt.Errorf("%w: at most %d tags allowed", ErrInvalidMetadata, maxTagsPerFile)
+	}
+	for _, tag := range tags {
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, fmt.Errorf("%w: tag %q is longer than %d characters", ErrInvalidMetadata, tag, maxTagLength)
+		}
+	}
+	return tags, nil
+}
+
+func validateProperties(properties map[string]string) error {
+	if len(properties) > maxPropertiesPerFile {
+		return fmt.Errorf("%w: at most %d properties allowed", ErrInvalidMetadata, maxPropertiesPerFile)
+	}
+	for key, value := range properties {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("%w: property key is empty", ErrInvalidMetadata)
+		}
+		if utf8.RuneCountInString(key) > maxPropertyKeyLength {
+			return fmt.Errorf("%w: property key %q is longer than %d characters", ErrInvalidMetadata, key, maxPropertyKeyLength)
+		}
+		if utf8.RuneCountInString(value) > maxPropertyValueLen {
+			return fmt.Errorf("%w: value of %q is longer than %d characters", ErrInvalidMetadata, key, maxPropertyValueLen)
+		}
+	}
+	return nil
+}
+
+// normalizeTags приводит теги к нижнему регистру, убирает пустые и повторы.
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]struct{}, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		result = append(result, tag)
+	}
+	sort.Strings(result)
+	return result
+}
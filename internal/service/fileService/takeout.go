@@ -0,0 +1,307 @@
+package fileService
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"registration-service/internal/archive"
+	"registration-service/internal/model/fileInfo"
+	"registration-service/internal/model/webhookInfo"
+	"registration-service/internal/takeout"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrExportNotFound       = errors.New("export not found")
+	ErrExportNotReady       = errors.New("export is not ready")
+	ErrNotAdmin             = errors.New("administrator rights required")
+	ErrImportTargetNotFound = errors.New("target user not found")
+)
+
+// StartExport ставит в очередь выгрузку всех файлов пользователя. Если предыдущая выгрузка
+// ещё собирается, возвращается она.
+func (s *FileService) StartExport(ctx context.Context) (*fileInfo.Export, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user ID: %v", err)
+	}
+	active, err := s.exportRepo.GetActiveExport(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check running exports: %w", err)
+	}
+	if active != nil {
+		return active, nil
+	}
+
+	export := &fileInfo.Export{
+		ID:        uuid.New(),
+		OwnerID:   userID,
+		Status:    fileInfo.ExportPending,
+		CreatedAt: time.Now(),
+	}
+	if err := s.exportRepo.CreateExport(ctx, export); err != nil {
+		return nil, fmt.Errorf("failed to create export: %w", err)
+	}
+	s.exports.Enqueue()
+	return export, nil
+}
+
+// GetExport возвращает выгрузку текущего пользователя; чужие выгрузки не видны.
+func (s *FileService) GetExport(ctx context.Context, exportID uuid.UUID) (*fileInfo.Export, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user ID: %v", err)
+	}
+	export, err := s.exportRepo.GetExport(ctx, exportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get export: %w", err)
+	}
+	if export == nil || export.OwnerID != userID {
+		return nil, ErrExportNotFound
+	}
+	return export, nil
+}
+
+// OpenExport открывает готовый архив выгрузки для скачивания.
+func (s *FileService) OpenExport(ctx context.Context, exportID uuid.UUID) (io.ReadCloser, *fileInfo.Export, error) {
+	export, err := s.GetExport(ctx, exportID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if export.Status != fileInfo.ExportReady {
+		return nil, nil, fmt.Errorf("%w: export is %s", ErrExportNotReady, export.Status)
+	}
+	reader, err := s.store.Open(ctx, export.Object())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open export archive: %w", err)
+	}
+	return reader, export, nil
+}
+
+// ImportArchive восстанавливает файлы из архива выгрузки у пользователя targetUserID (0 —
+// текущий пользователь); импортировать файлы другому пользователю может только администратор.
+// Каждый файл получает новый идентификатор; номера и даты версий, теги и свойства сохраняются.
+// Версии загружаются так же, как обычные, и снова проходят проверку сканером. Права других
+// пользователей переносятся только с restorePermissions.
+func (s *FileService) ImportArchive(ctx context.Context, targetUserID uint32, restorePermissions bool, data io.Reader) ([]*fileInfo.ImportResult, error) {
+	callerID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user ID: %v", err)
+	}
+	userID, err := s.importTarget(ctx, callerID, targetUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	spool, err := os.CreateTemp("", "import-archive-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	size, err := io.Copy(spool, io.LimitReader(data, s.archiveLimits.MaxUploadBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to receive archive: %w", err)
+	}
+	if size > s.archiveLimits.MaxUploadBytes {
+		return nil, fmt.Errorf("%w: archive is larger than %d bytes", ErrArchiveRejected, s.archiveLimits.MaxUploadBytes)
+	}
+	if err := archive.Validate(archive.FormatZip, spool, size, s.archiveLimits); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrArchiveRejected, err)
+	}
+	zr, err := zip.NewReader(spool, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrArchiveRejected, err)
+	}
+	manifest, err := takeout.ReadManifest(zr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrArchiveRejected, err)
+	}
+
+	results := make([]*fileInfo.ImportResult, 0, len(manifest.Files))
+	for _, file := range manifest.Files {
+		results = append(results, s.importFile(ctx, userID, zr, file, restorePermissions))
+	}
+	return results, nil
+}
+
+// importTarget возвращает владельца импортируемых файлов. Права вызывающего проверяются до
+// существования получателя, чтобы по ответу нельзя было перебирать пользователей.
+func (s *FileService) importTarget(ctx context.Context, callerID, targetUserID uint32) (uint32, error) {
+	if targetUserID == 0 || targetUserID == callerID {
+		return callerID, nil
+	}
+	isAdmin, _, err := s.fileRepo.LookupUser(ctx, callerID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check administrator rights: %w", err)
+	}
+	if !isAdmin {
+		return 0, ErrNotAdmin
+	}
+	_, found, err := s.fileRepo.LookupUser(ctx, targetUserID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get target user: %w", err)
+	}
+	if !found {
+		return 0, fmt.Errorf("%w: %d", ErrImportTargetNotFound, targetUserID)
+	}
+	log.Printf("[FileService.ImportArchive] admin %d imports an archive for user %d", callerID, targetUserID)
+	return targetUserID, nil
+}
+
+func (s *FileService) importFile(ctx context.Context, userID uint32, zr *zip.Reader, source takeout.ManifestFile, restorePermissions bool) *fileInfo.ImportResult {
+	result := &fileInfo.ImportResult{SourceFileID: source.ID, Name: source.Name, Status: fileInfo.ArchiveEntryFailed}
+
+	var contents []takeout.ManifestVersion
+	for _, version := range source.Versions {
+		if version.Entry != "" {
+			contents = append(contents, version)
+		}
+	}
+	if len(contents) == 0 {
+		result.Status = fileInfo.ArchiveEntrySkipped
+		result.Error = "no versions with content"
+		return result
+	}
+	tags, err := validateTags(source.Tags)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if err := validateProperties(source.Properties); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	file := &fileInfo.File{
+		ID:             uuid.New(),
+		OwnerID:        userID,
+		Name:           source.Name,
+		CurrentVersion: 0,
+		CreatedAt:      source.CreatedAt,
+	}
+	versions := make([]*fileInfo.FileVersion, len(contents))
+	for i, content := range contents {
+		// исходная дата проставляется после загрузки, см. SetVersionCreatedAt
+		versions[i] = &fileInfo.FileVersion{
+			FileID:        file.ID,
+			VersionNumber: content.VersionNumber,
+			StorageKey:    fmt.Sprintf("%s/v%d", file.ID, content.VersionNumber),
+			Size:          content.Size,
+			ContentType:   content.ContentType,
+			CreatedAt:     time.Now(),
+			ScanStatus:    fileInfo.ScanPending,
+			Status:        fileInfo.StatusPending,
+		}
+		if err := s.store.Prepare(ctx, versions[i]); err != nil {
+			result.Error = fmt.Sprintf("failed to prepare file version: %v", err)
+			return result
+		}
+	}
+	if err := s.createPendingVersions(ctx, file, versions); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.FileID = file.ID
+
+	var problems []string
+	var last *fileInfo.FileVersion
+	for i, version := range versions {
+		if err := s.importVersion(ctx, zr, contents[i], version); err != nil {
+			problems = append(problems, fmt.Sprintf("version %d: %v", version.VersionNumber, err))
+			continue
+		}
+		s.scanner.Enqueue(version)
+		result.Versions++
+		last = version
+	}
+	if last == nil {
+		// abortUpload последней версии удалил и pending-файл
+		result.FileID = uuid.Nil
+		result.Error = strings.Join(problems, "; ")
+		return result
+	}
+
+	if err := s.fileRepo.SetFileTags(ctx, file.ID, tags); err != nil {
+		problems = append(problems, fmt.Sprintf("tags not restored: %v", err))
+	}
+	if err := s.fileRepo.SetFileProperties(ctx, file.ID, source.Properties); err != nil {
+		problems = append(problems, fmt.Sprintf("properties not restored: %v", err))
+	}
+	if restorePermissions {
+		if err := s.restorePermissions(ctx, userID, file.ID, source.Permissions); err != nil {
+			problems = append(problems, fmt.Sprintf("permissions not restored: %v", err))
+		}
+	}
+
+	result.Status = fileInfo.ArchiveEntryCreated
+	result.Error = strings.Join(problems, "; ")
+	s.publishEvent(ctx, []uint32{userID}, webhookInfo.EventFileUploaded, fileEventData{
+		FileID:      file.ID.String(),
+		Name:        file.Name,
+		OwnerID:     userID,
+		Version:     last.VersionNumber,
+		Size:        last.Size,
+		ContentType: last.ContentType,
+	})
+	return result
+}
+
+// createPendingVersions создаёт файл со всеми версиями в состоянии pending. Если вставка
+// прервалась, уже созданные строки удаляются; не удалённые подберёт RecoveryWorker.
+func (s *FileService) createPendingVersions(ctx context.Context, file *fileInfo.File, versions []*fileInfo.FileVersion) error {
+	if err := s.fileRepo.CreatePendingUpload(ctx, file, versions[0]); err != nil {
+		return fmt.Errorf("create file entry error: %w", err)
+	}
+	for i, version := range versions[1:] {
+		if err := s.fileRepo.CreateFileVersion(ctx, version); err != nil {
+			for _, created := range versions[:i+1] {
+				if err := s.fileRepo.DeletePendingVersion(ctx, created.ID); err != nil {
+					log.Printf("[FileService.createPendingVersions] failed to roll back version %d: %v", created.ID, err)
+				}
+			}
+			return fmt.Errorf("create file version error: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *FileService) importVersion(ctx context.Context, zr *zip.Reader, content takeout.ManifestVersion, version *fileInfo.FileVersion) error {
+	entry, err := zr.Open(content.Entry)
+	if err != nil {
+		s.abortUpload(ctx, version)
+		return fmt.Errorf("failed to open archive entry: %w", err)
+	}
+	defer entry.Close()
+	if err := s.storeVersion(ctx, version, entry); err != nil {
+		return err
+	}
+	if err := s.fileRepo.SetVersionCreatedAt(ctx, version.ID, content.CreatedAt); err != nil {
+		log.Printf("[FileService.importVersion] failed to restore creation time of %s: %v", version.StorageKey, err)
+	}
+	return nil
+}
+
+// restorePermissions переносит права других пользователей; права на собственный файл
+// владельцу не выдаются.
+func (s *FileService) restorePermissions(ctx context.Context, ownerID uint32, fileID uuid.UUID, source []takeout.ManifestPermission) error {
+	permissions := make([]fileInfo.FilePermission, 0, len(source))
+	for _, p := range source {
+		if p.UserID <= 0 || uint32(p.UserID) == ownerID {
+			continue
+		}
+		permissions = append(permissions, fileInfo.FilePermission{FileID: fileID, UserID: p.UserID, Permission: p.Permission})
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+	return s.fileRepo.SetFilePermissions(ctx, fileID, permissions)
+}
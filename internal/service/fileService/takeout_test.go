@@ -0,0 +1,256 @@
+package fileService_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"registration-service/internal/archive"
+	"registration-service/internal/compression"
+	"registration-service/internal/model/fileInfo"
+	"registration-service/internal/scan"
+	"registration-service/internal/service/fileService"
+	"registration-service/internal/storage/memory"
+	"registration-service/internal/takeout"
+	"registration-service/internal/versionStore"
+)
+
+var testLimits = archive.Config{
+	MaxUploadBytes: 1 << 20, MaxEntries: 10, MaxEntrySize: 16 << 10, MaxTotalSize: 1 << 20, MaxCompressionRatio: 100,
+}
+
+// fakeFiles хранит файлы в памяти вместо таблиц files и file_versions; нужные тестам методы
+// реализованы, остальные возвращают пустой ответ.
+type fakeFiles struct {
+	mu          sync.Mutex
+	files       map[uuid.UUID]*fileInfo.File
+	versions    map[uuid.UUID][]*fileInfo.FileVersion
+	permissions map[uuid.UUID][]fileInfo.FilePermission
+	admins      map[uint32]bool
+	nextID      uint32
+}
+
+func newFakeFiles(admins map[uint32]bool) *fakeFiles {
+	return &fakeFiles{
+		files:       map[uuid.UUID]*fileInfo.File{},
+		versions:    map[uuid.UUID][]*fileInfo.FileVersion{},
+		permissions: map[uuid.UUID][]fileInfo.FilePermission{},
+		admins:      admins,
+	}
+}
+
+func (r *fakeFiles) addVersion(version *fileInfo.FileVersion) {
+	r.nextID++
+	version.ID = r.nextID
+	r.versions[version.FileID] = append(r.versions[version.FileID], version)
+}
+
+func (r *fakeFiles) ownedBy(ownerID uint32) []*fileInfo.File {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*fileInfo.File
+	for _, f := range r.files {
+		if f.OwnerID == ownerID {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func (r *fakeFiles) CreatePendingUpload(ctx context.Context, file *fileInfo.File, version *fileInfo.FileVersion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.files[file.ID] = file
+	r.addVersion(version)
+	return nil
+}
+
+func (r *fakeFiles) CreateFileVersion(ctx context.Context, version *fileInfo.FileVersion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.addVersion(version)
+	return nil
+}
+
+func (r *fakeFiles) ActivateVersion(ctx context.Context, version *fileInfo.FileVersion) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	version.Status = fileInfo.StatusActive
+	if file := r.files[version.FileID]; file != nil && int(version.VersionNumber) > file.CurrentVersion {
+		file.CurrentVersion = int(version.VersionNumber)
+	}
+	return true, nil
+}
+
+func (r *fakeFiles) SetVersionCreatedAt(ctx context.Context, versionID uint32, createdAt time.Time) error {
+	return nil
+}
+
+func (r *fakeFiles) SetFileTags(ctx context.Context, fileID uuid.UUID, tags []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.files[fileID].Tags = tags
+	return nil
+}
+
+func (r *fakeFiles) SetFileProperties(ctx context.Context, fileID uuid.UUID, properties map[string]string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.files[fileID].Properties = properties
+	return nil
+}
+
+func (r *fakeFiles) SetFilePermissions(ctx context.Context, fileID uuid.UUID, permissions []fileInfo.FilePermission) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.permissions[fileID] = permissions
+	return nil
+}
+
+func (r *fakeFiles) LookupUser(ctx context.Context, userID uint32) (bool, bool, error) {
+	isAdmin, found := r.admins[userID]
+	return isAdmin, found, nil
+}
+
+func (r *fakeFiles) ListFilesByOwner(ctx context.Context, ownerID int, filter fileInfo.FileFilter) ([]*fileInfo.File, error) {
+	return r.ownedBy(uint32(ownerID)), nil
+}
+
+func (r *fakeFiles) GetFileVersions(ctx context.Context, fileID uuid.UUID) ([]*fileInfo.FileVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.versions[fileID], nil
+}
+
+func (r *fakeFiles) GetFilePermissions(ctx context.Context, fileID uuid.UUID) ([]fileInfo.FilePermission, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.permissions[fileID], nil
+}
+
+func (r *fakeFiles) CheckUserPermission(ctx context.Context, fileID uuid.UUID, userID int) (int, error) {
+	return 0, nil
+}
+
+func (r *fakeFiles) DeleteFile(ctx context.Context, fileID uuid.UUID) error { return nil }
+
+func (r *fakeFiles) DeletePendingVersion(ctx context.Context, versionID uint32) error { return nil }
+
+func (r *fakeFiles) GetCurrentFileVersion(ctx context.Context, fileID uuid.UUID) (*fileInfo.FileVersion, error) {
+	return nil, nil
+}
+
+func (r *fakeFiles) GetFileByID(ctx context.Context, fileID uuid.UUID) (*fileInfo.File, error) {
+	return nil, nil
+}
+
+func (r *fakeFiles) GetFileVersion(ctx context.Context, fileID uuid.UUID, version int) (*fileInfo.FileVersion, error) {
+	return nil, nil
+}
+
+func (r *fakeFiles) GetLatestFileVersion(ctx context.Context, fileID uuid.UUID) (*fileInfo.FileVersion, error) {
+	return nil, nil
+}
+
+func (r *fakeFiles) GetSharedFiles(ctx context.Context, userID int, filter fileInfo.FileFilter) ([]*fileInfo.File, error) {
+	return nil, nil
+}
+
+func (r *fakeFiles) NextVersionNumber(ctx context.Context, fileID uuid.UUID) (int, error) {
+	return 0, nil
+}
+
+func (r *fakeFiles) RenameFile(ctx context.Context, fileID uuid.UUID, newName string) error {
+	return nil
+}
+
+func (r *fakeFiles) SearchFiles(ctx context.Context, userID int, permission int, query string, limit, offset int) ([]*fileInfo.SearchResult, error) {
+	return nil, nil
+}
+
+func (r *fakeFiles) TouchFile(ctx context.Context, fileID uuid.UUID) error { return nil }
+
+const (
+	adminID  = uint32(1)
+	sourceID = uint32(7)
+	targetID = uint32(9)
+)
+
+// setupImport создаёт сервис и архив выгрузки пользователя sourceID с одним файлом в двух версиях.
+func setupImport(t *testing.T) (*fileService.FileService, *fakeFiles, []byte) {
+	t.Helper()
+	ctx := context.Background()
+	store := versionStore.New(memory.New(), nil, nil, compression.Config{Codec: compression.CodecNone})
+	files := newFakeFiles(map[uint32]bool{adminID: true, sourceID: false, targetID: false})
+
+	fileID := uuid.New()
+	files.files[fileID] = &fileInfo.File{ID: fileID, OwnerID: sourceID, Name: "notes.txt", CurrentVersion: 2, Tags: []string{"work"}}
+	for number, content := range []string{"first", "second revision"} {
+		version := &fileInfo.FileVersion{
+			FileID:        fileID,
+			VersionNumber: uint32(number + 1),
+			StorageKey:    fmt.Sprintf("%s/v%d", fileID, number+1),
+			Size:          int64(len(content)),
+			ContentType:   "text/plain",
+			CreatedAt:     time.Date(2024, 1, number+1, 0, 0, 0, 0, time.UTC),
+			ScanStatus:    fileInfo.ScanClean,
+		}
+		require.NoError(t, store.Put(ctx, version, strings.NewReader(content)))
+		files.addVersion(version)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, takeout.NewExporter(files, store, testLimits).WriteArchive(ctx, sourceID, &buf))
+
+	scanner := scan.NewWorker(nil, nil, scan.NopScanner{}, scan.Config{}, nil)
+	s := fileService.New(files, nil, store, nil, nil, nil, scanner, nil, nil, nil, testLimits)
+	return s, files, buf.Bytes()
+}
+
+func asUser(userID uint32) context.Context {
+	return context.WithValue(context.Background(), "userID", userID)
+}
+
+func TestImportArchive_AdminImportsForAnotherUser(t *testing.T) {
+	s, files, data := setupImport(t)
+
+	results, err := s.ImportArchive(asUser(adminID), targetID, false, bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, fileInfo.ArchiveEntryCreated, results[0].Status, results[0].Error)
+	assert.Equal(t, 2, results[0].Versions)
+
+	imported := files.ownedBy(targetID)
+	require.Len(t, imported, 1)
+	assert.Equal(t, results[0].FileID, imported[0].ID)
+	assert.Equal(t, "notes.txt", imported[0].Name)
+	assert.Equal(t, []string{"work"}, imported[0].Tags)
+	assert.Empty(t, files.ownedBy(adminID), "the admin does not receive a copy")
+}
+
+func TestImportArchive_DefaultsToCaller(t *testing.T) {
+	s, files, data := setupImport(t)
+
+	results, err := s.ImportArchive(asUser(targetID), 0, false, bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Len(t, files.ownedBy(targetID), 1)
+}
+
+func TestImportArchive_OtherUserRequiresAdmin(t *testing.T) {
+	s, files, data := setupImport(t)
+
+	_, err := s.ImportArchive(asUser(sourceID), targetID, false, bytes.NewReader(data))
+	assert.ErrorIs(t, err, fileService.ErrNotAdmin)
+	assert.Empty(t, files.ownedBy(targetID))
+
+	_, err = s.ImportArchive(asUser(adminID), 42, false, bytes.NewReader(data))
+	assert.ErrorIs(t, err, fileService.ErrImportTargetNotFound)
+}
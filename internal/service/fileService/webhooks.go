@@ -0,0 +1,131 @@
+package fileService
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"registration-service/internal/model/webhookInfo"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidWebhook   = errors.New("invalid webhook")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+type fileEventData struct {
+	FileID      string           `json:"file_id"`
+	Name        string           `json:"name"`
+	OwnerID     uint32           `json:"owner_id"`
+	Version     uint32           `json:"version,omitempty"`
+	Size        int64            `json:"size,omitempty"`
+	ContentType string           `json:"content_type,omitempty"`
+	SharedWith  []sharedWithData `json:"shared_with,omitempty"`
+}
+
+type sharedWithData struct {
+	UserID     int32 `json:"user_id"`
+	Permission int   `json:"permission"`
+}
+
+func (s *FileService) CreateWebhook(ctx context.Context, rawURL string, eventTypes []string) (*webhookInfo.Webhook, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user ID: %v", err)
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidWebhook)
+	}
+	if len(eventTypes) == 0 {
+		return nil, fmt.Errorf("%w: at least one event type is required", ErrInvalidWebhook)
+	}
+	for _, eventType := range eventTypes {
+		if !webhookInfo.IsKnownEvent(eventType) {
+			return nil, fmt.Errorf("%w: unknown event type %q", ErrInvalidWebhook, eventType)
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	hook := &webhookInfo.Webhook{
+		ID:         uuid.New(),
+		OwnerID:    userID,
+		URL:        parsed.String(),
+		Secret:     hex.EncodeToString(secret),
+		EventTypes: eventTypes,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.webhookRepo.CreateWebhook(ctx, hook); err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return hook, nil
+}
+
+func (s *FileService) ListWebhooks(ctx context.Context) ([]*webhookInfo.Webhook, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user ID: %v", err)
+	}
+	hooks, err := s.webhookRepo.ListWebhooksByOwner(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return hooks, nil
+}
+
+func (s *FileService) ListDeadLetters(ctx context.Context, webhookID uuid.UUID) ([]*webhookInfo.Delivery, error) {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user ID: %v", err)
+	}
+	deliveries, err := s.webhookRepo.ListDeadDeliveries(ctx, userID, webhookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (s *FileService) RedeliverWebhook(ctx context.Context, deliveryID uuid.UUID) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get user ID: %v", err)
+	}
+	found, err := s.webhookRepo.Redeliver(ctx, userID, deliveryID)
+	if err != nil {
+		return fmt.Errorf("failed to redeliver webhook: %w", err)
+	}
+	if !found {
+		return ErrDeliveryNotFound
+	}
+	return nil
+}
+
+// publishEvent пишет событие в outbox. Ошибка не должна ломать основную операцию, поэтому только логируется.
+func (s *FileService) publishEvent(ctx context.Context, userIDs []uint32, eventType string, data fileEventData) {
+	if s.webhookRepo == nil {
+		return
+	}
+	payload, err := json.Marshal(webhookInfo.Event{
+		ID:         uuid.New(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	})
+	if err != nil {
+		log.Printf("[FileService.publishEvent] failed to marshal %s event: %v", eventType, err)
+		return
+	}
+	if err := s.webhookRepo.EnqueueEvent(ctx, userIDs, eventType, payload); err != nil {
+		log.Printf("[FileService.publishEvent] failed to enqueue %s event: %v", eventType, err)
+	}
+}
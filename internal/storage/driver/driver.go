@@ -0,0 +1,44 @@
+package driver
+
+import (
+	"fmt"
+	"registration-service/internal/MinIO"
+	"registration-service/internal/storage"
+	"registration-service/internal/storage/filesystem"
+	"registration-service/internal/storage/memory"
+)
+
+const (
+	MinIODriver      = "minio"
+	FilesystemDriver = "filesystem"
+	MemoryDriver     = "memory"
+)
+
+type Config struct {
+	Driver         string `env:"STORAGE_DRIVER" env-default:"minio"`
+	FilesystemRoot string `env:"STORAGE_FS_ROOT" env-default:"./data/objects"`
+	// ColdBucket и ColdFilesystemRoot задают холодный уровень того же драйвера
+	ColdBucket         string `env:"STORAGE_COLD_BUCKET" env-default:"storage-cold"`
+	ColdFilesystemRoot string `env:"STORAGE_COLD_FS_ROOT" env-default:"./data/objects-cold"`
+}
+
+// New создаёт хранилище выбранного драйвера. Подключение к MinIO устанавливается только
+// для драйвера minio, так что остальные драйверы запускаются без живого MinIO.
+func New(cfg Config, minioCfg MinIO.Config) (storage.ObjectStore, error) {
+	switch cfg.Driver {
+	case MinIODriver, "":
+		return MinIO.New(minioCfg)
+	case FilesystemDriver:
+		return filesystem.New(cfg.FilesystemRoot)
+	case MemoryDriver:
+		return memory.New(), nil
+	}
+	return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+}
+
+// NewCold создаёт холодное хранилище того же драйвера: отдельный бакет MinIO или каталог.
+func NewCold(cfg Config, minioCfg MinIO.Config) (storage.ObjectStore, error) {
+	minioCfg.BucketName = cfg.ColdBucket
+	cfg.FilesystemRoot = cfg.ColdFilesystemRoot
+	return New(cfg, minioCfg)
+}
@@ -0,0 +1,210 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"registration-service/internal/storage"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Объект с ключом "a/b" хранится в файле root/a/b.obj, его content type — в root/a/b.meta.
+// Суффикс нужен, потому что ключ версии ("<id>/v1") одновременно служит префиксом ключей
+// её превью ("<id>/v1/thumb-256"), и без него файл и каталог столкнулись бы по имени.
+const (
+	objectSuffix = ".obj"
+	metaSuffix   = ".meta"
+	tempPattern  = ".tmp-*"
+)
+
+// Store хранит объекты в локальном каталоге. Подходит для развёртываний на одном узле.
+type Store struct {
+	root string
+}
+
+func New(root string) (*Store, error) {
+	if root == "" {
+		return nil, errors.New("filesystem storage root is required")
+	}
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage root: %w", err)
+	}
+	return &Store{root: root}, nil
+}
+
+func (s *Store) objectPath(key string) (string, error) {
+	if key == "" || strings.ContainsAny(key, "\\\x00") || path.IsAbs(key) || path.Clean(key) != key ||
+		key == ".." || strings.HasPrefix(key, "../") {
+		return "", fmt.Errorf("%w: %q", storage.ErrInvalidKey, key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+func notFound(err error, key string) error {
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR) {
+		return fmt.Errorf("%w: %s", storage.ErrNotFound, key)
+	}
+	return err
+}
+
+// Put пишет объект во временный файл и переименовывает его, поэтому читатели никогда не
+// видят частично записанный объект.
+func (s *Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	p, err := s.objectPath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), tempPattern)
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, r)
+	if err == nil && size >= 0 && written != size {
+		err = fmt.Errorf("object %s: expected %d bytes, got %d", key, size, written)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+	if err := os.WriteFile(p+metaSuffix, []byte(contentType), 0o640); err != nil {
+		return fmt.Errorf("failed to write object metadata: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), p+objectSuffix); err != nil {
+		return fmt.Errorf("failed to commit object: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.GetRange(ctx, key, 0, -1)
+}
+
+type sectionReader struct {
+	io.Reader
+	io.Closer
+}
+
+func (s *Store) GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	p, err := s.objectPath(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p + objectSuffix)
+	if err != nil {
+		return nil, notFound(err, key)
+	}
+	if offset > 0 {
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	if length < 0 {
+		return f, nil
+	}
+	return sectionReader{Reader: io.LimitReader(f, length), Closer: f}, nil
+}
+
+func (s *Store) Delete(ctx context.Context, key string) error {
+	p, err := s.objectPath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p + objectSuffix); err != nil && !errors.Is(notFound(err, key), storage.ErrNotFound) {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	if err := os.Remove(p + metaSuffix); err != nil && !errors.Is(notFound(err, key), storage.ErrNotFound) {
+		return fmt.Errorf("failed to delete object metadata: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) Copy(ctx context.Context, srcKey, dstKey string) error {
+	info, err := s.Stat(ctx, srcKey)
+	if err != nil {
+		return err
+	}
+	src, err := s.Get(ctx, srcKey)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	return s.Put(ctx, dstKey, src, info.Size, info.ContentType)
+}
+
+func (s *Store) Stat(ctx context.Context, key string) (storage.ObjectInfo, error) {
+	p, err := s.objectPath(key)
+	if err != nil {
+		return storage.ObjectInfo{}, err
+	}
+	fi, err := os.Stat(p + objectSuffix)
+	if err != nil {
+		return storage.ObjectInfo{}, notFound(err, key)
+	}
+	return s.info(key, p, fi), nil
+}
+
+func (s *Store) info(key, p string, fi fs.FileInfo) storage.ObjectInfo {
+	contentType := "application/octet-stream"
+	if meta, err := os.ReadFile(p + metaSuffix); err == nil && len(meta) > 0 {
+		contentType = string(meta)
+	}
+	return storage.ObjectInfo{Key: key, Size: fi.Size(), ContentType: contentType, LastModified: fi.ModTime()}
+}
+
+func (s *Store) List(ctx context.Context, prefix string, fn func(storage.ObjectInfo) error) error {
+	// обход начинается с самого глубокого каталога, целиком входящего в префикс
+	start := s.root
+	if i := strings.LastIndex(prefix, "/"); i > 0 {
+		start = filepath.Join(s.root, filepath.FromSlash(prefix[:i]))
+	}
+	err := filepath.WalkDir(start, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() || !strings.HasSuffix(p, objectSuffix) {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, strings.TrimSuffix(p, objectSuffix))
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return fn(s.info(key, strings.TrimSuffix(p, objectSuffix), fi))
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *Store) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return "", storage.ErrPresignUnsupported
+}
@@ -0,0 +1,43 @@
+package filesystem_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"registration-service/internal/storage"
+	"registration-service/internal/storage/filesystem"
+	"registration-service/internal/storage/storagetest"
+)
+
+func newStore(t *testing.T) *filesystem.Store {
+	s, err := filesystem.New(t.TempDir())
+	require.NoError(t, err)
+	return s
+}
+
+func TestStore(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.ObjectStore {
+		return newStore(t)
+	})
+}
+
+func TestStore_RejectsEscapingKeys(t *testing.T) {
+	s := newStore(t)
+	ctx := context.Background()
+	for _, key := range []string{"", "../etc/passwd", "/abs", "a/../../b", "a//b", `a\b`} {
+		err := s.Put(ctx, key, strings.NewReader("x"), 1, "")
+		assert.ErrorIs(t, err, storage.ErrInvalidKey, key)
+	}
+}
+
+func TestStore_SizeMismatchLeavesNoObject(t *testing.T) {
+	s := newStore(t)
+	ctx := context.Background()
+	err := s.Put(ctx, "short", strings.NewReader("abc"), 10, "")
+	assert.Error(t, err)
+	_, err = s.Stat(ctx, "short")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
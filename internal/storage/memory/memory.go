@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"registration-service/internal/storage"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type object struct {
+	data         []byte
+	contentType  string
+	lastModified time.Time
+}
+
+// Store держит объекты в памяти процесса. Предназначен для тестов и локальных экспериментов.
+type Store struct {
+	mu      sync.RWMutex
+	objects map[string]object
+}
+
+func New() *Store {
+	return &Store{objects: map[string]object{}}
+}
+
+func (s *Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read object: %w", err)
+	}
+	if size >= 0 && int64(len(data)) != size {
+		return fmt.Errorf("object %s: expected %d bytes, got %d", key, size, len(data))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = object{data: data, contentType: contentType, lastModified: time.Now()}
+	return nil
+}
+
+func (s *Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.GetRange(ctx, key, 0, -1)
+}
+
+func (s *Store) GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	s.mu.RLock()
+	obj, ok := s.objects[key]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", storage.ErrNotFound, key)
+	}
+	data := obj.data
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	data = data[offset:]
+	if length >= 0 && length < int64(len(data)) {
+		data = data[:length]
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *Store) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *Store) Copy(ctx context.Context, srcKey, dstKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.objects[srcKey]
+	if !ok {
+		return fmt.Errorf("%w: %s", storage.ErrNotFound, srcKey)
+	}
+	obj.lastModified = time.Now()
+	s.objects[dstKey] = obj
+	return nil
+}
+
+func (s *Store) Stat(ctx context.Context, key string) (storage.ObjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	obj, ok := s.objects[key]
+	if !ok {
+		return storage.ObjectInfo{}, fmt.Errorf("%w: %s", storage.ErrNotFound, key)
+	}
+	return info(key, obj), nil
+}
+
+func (s *Store) List(ctx context.Context, prefix string, fn func(storage.ObjectInfo) error) error {
+	s.mu.RLock()
+	var infos []storage.ObjectInfo
+	for key, obj := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			infos = append(infos, info(key, obj))
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+	for _, i := range infos {
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return "", storage.ErrPresignUnsupported
+}
+
+func info(key string, obj object) storage.ObjectInfo {
+	return storage.ObjectInfo{
+		Key:          key,
+		Size:         int64(len(obj.data)),
+		ContentType:  obj.contentType,
+		LastModified: obj.lastModified,
+	}
+}
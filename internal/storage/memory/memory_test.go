@@ -0,0 +1,15 @@
+package memory_test
+
+import (
+	"testing"
+
+	"registration-service/internal/storage"
+	"registration-service/internal/storage/memory"
+	"registration-service/internal/storage/storagetest"
+)
+
+func TestStore(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.ObjectStore {
+		return memory.New()
+	})
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrNotFound           = errors.New("object not found")
+	ErrInvalidKey         = errors.New("invalid object key")
+	ErrPresignUnsupported = errors.New("presigned URLs are not supported by this storage driver")
+)
+
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// ObjectStore — хранилище объектов, на котором держатся версии файлов. Ключи — пути со
+// слешами ("<fileID>/v<N>", "<fileID>/v<N>/thumb-256"); отсутствие объекта сообщается
+// ошибкой ErrNotFound из Get, GetRange и Stat.
+type ObjectStore interface {
+	// Put сохраняет объект; size < 0 означает, что размер заранее неизвестен.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// GetRange читает length байт начиная с offset; length < 0 — до конца объекта.
+	GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	Copy(ctx context.Context, srcKey, dstKey string) error
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+	// List вызывает fn для каждого объекта с ключом, начинающимся с prefix.
+	List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error
+	PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
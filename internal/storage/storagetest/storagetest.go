@@ -0,0 +1,107 @@
+// Package storagetest содержит общие проверки для драйверов storage.ObjectStore.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"registration-service/internal/storage"
+)
+
+func put(t *testing.T, s storage.ObjectStore, key, content string) {
+	t.Helper()
+	require.NoError(t, s.Put(context.Background(), key, strings.NewReader(content), int64(len(content)), "text/plain"))
+}
+
+// read принимает результат Get/GetRange целиком: read(t)(s.Get(ctx, key)).
+func read(t *testing.T) func(io.ReadCloser, error) string {
+	return func(r io.ReadCloser, err error) string {
+		t.Helper()
+		require.NoError(t, err)
+		defer r.Close()
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return string(data)
+	}
+}
+
+// Run проверяет поведение, на которое полагается versionStore, на свежем хранилище из newStore.
+func Run(t *testing.T, newStore func(t *testing.T) storage.ObjectStore) {
+	ctx := context.Background()
+
+	t.Run("PutGetStat", func(t *testing.T) {
+		s := newStore(t)
+		put(t, s, "file/v1", "hello world")
+		assert.Equal(t, "hello world", read(t)(s.Get(ctx, "file/v1")))
+
+		info, err := s.Stat(ctx, "file/v1")
+		require.NoError(t, err)
+		assert.Equal(t, "file/v1", info.Key)
+		assert.Equal(t, int64(11), info.Size)
+		assert.Equal(t, "text/plain", info.ContentType)
+
+		// ключ версии служит префиксом ключей её превью
+		put(t, s, "file/v1/thumb-64", "thumb")
+		assert.Equal(t, "thumb", read(t)(s.Get(ctx, "file/v1/thumb-64")))
+		assert.Equal(t, "hello world", read(t)(s.Get(ctx, "file/v1")))
+	})
+
+	t.Run("UnknownSize", func(t *testing.T) {
+		s := newStore(t)
+		require.NoError(t, s.Put(ctx, "stream", strings.NewReader("streamed"), -1, ""))
+		assert.Equal(t, "streamed", read(t)(s.Get(ctx, "stream")))
+	})
+
+	t.Run("GetRange", func(t *testing.T) {
+		s := newStore(t)
+		put(t, s, "range", "0123456789")
+		assert.Equal(t, "2345", read(t)(s.GetRange(ctx, "range", 2, 4)))
+		assert.Equal(t, "789", read(t)(s.GetRange(ctx, "range", 7, -1)))
+		assert.Equal(t, "012", read(t)(s.GetRange(ctx, "range", 0, 3)))
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		s := newStore(t)
+		_, err := s.Get(ctx, "missing")
+		assert.True(t, errors.Is(err, storage.ErrNotFound), "Get: %v", err)
+		_, err = s.Stat(ctx, "missing/v1")
+		assert.True(t, errors.Is(err, storage.ErrNotFound), "Stat: %v", err)
+		assert.NoError(t, s.Delete(ctx, "missing"), "deleting a missing object is not an error")
+	})
+
+	t.Run("CopyDelete", func(t *testing.T) {
+		s := newStore(t)
+		put(t, s, "file/v1", "payload")
+		require.NoError(t, s.Copy(ctx, "file/v1", "quarantine/file/v1"))
+		require.NoError(t, s.Delete(ctx, "file/v1"))
+
+		_, err := s.Get(ctx, "file/v1")
+		assert.True(t, errors.Is(err, storage.ErrNotFound))
+		assert.Equal(t, "payload", read(t)(s.Get(ctx, "quarantine/file/v1")))
+	})
+
+	t.Run("List", func(t *testing.T) {
+		s := newStore(t)
+		for _, key := range []string{"a/v1", "a/v1/thumb-64", "a/v2", "ab/v1", "b/v1"} {
+			put(t, s, key, key)
+		}
+		list := func(prefix string) []string {
+			var keys []string
+			require.NoError(t, s.List(ctx, prefix, func(info storage.ObjectInfo) error {
+				keys = append(keys, info.Key)
+				return nil
+			}))
+			return keys
+		}
+		assert.ElementsMatch(t, []string{"a/v1", "a/v1/thumb-64", "a/v2"}, list("a/"))
+		assert.ElementsMatch(t, []string{"a/v1", "a/v1/thumb-64", "a/v2", "ab/v1"}, list("a"))
+		assert.ElementsMatch(t, []string{"a/v1", "a/v1/thumb-64"}, list("a/v1"))
+		assert.Len(t, list(""), 5)
+		assert.Empty(t, list("zzz/"))
+	})
+}
@@ -0,0 +1,210 @@
+package takeout
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"registration-service/internal/archive"
+	"registration-service/internal/model/fileInfo"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FileRepository — часть fileRepo.FileRepository, которая нужна выгрузке.
+type FileRepository interface {
+	ListFilesByOwner(ctx context.Context, ownerID int, filter fileInfo.FileFilter) ([]*fileInfo.File, error)
+	GetFileVersions(ctx context.Context, fileID uuid.UUID) ([]*fileInfo.FileVersion, error)
+	GetFilePermissions(ctx context.Context, fileID uuid.UUID) ([]fileInfo.FilePermission, error)
+}
+
+// ContentStore — часть versionStore.VersionStore, из которой читается содержимое версий.
+type ContentStore interface {
+	Open(ctx context.Context, version *fileInfo.FileVersion) (io.ReadCloser, error)
+}
+
+// ErrTooLarge — архив не прошёл бы проверку при импорте (archive.Config), поэтому выгрузка
+// отказывает сразу, а не отдаёт архив, который нельзя загрузить обратно.
+var ErrTooLarge = errors.New("export exceeds archive import limits")
+
+// Exporter пишет архив со всеми файлами пользователя: содержимое каждой проверенной версии
+// отдельной записью и manifest.json с метаданными, правами доступа и историей версий.
+type Exporter struct {
+	files  FileRepository
+	store  ContentStore
+	limits archive.Config
+}
+
+func NewExporter(files FileRepository, store ContentStore, limits archive.Config) *Exporter {
+	return &Exporter{files: files, store: store, limits: limits}
+}
+
+// exportEntry — запись архива с содержимым версии.
+type exportEntry struct {
+	name    string
+	version *fileInfo.FileVersion
+}
+
+// WriteArchive пишет ZIP в w потоком. Содержимое есть только у версий в ScanClean: версии в
+// карантине и ещё не проверенные попадают в манифест без записи. Архив укладывается в те же
+// лимиты, что ImportArchive, иначе возвращается ErrTooLarge.
+func (e *Exporter) WriteArchive(ctx context.Context, ownerID uint32, w io.Writer) error {
+	files, err := e.files.ListFilesByOwner(ctx, int(ownerID), fileInfo.FileFilter{})
+	if err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+
+	manifest := Manifest{Format: Format, OwnerID: ownerID, ExportedAt: time.Now().UTC(), Files: []ManifestFile{}}
+	var entries []exportEntry
+	for _, file := range files {
+		entry, fileEntries, err := e.describeFile(ctx, file)
+		if err != nil {
+			return fmt.Errorf("failed to export file %s: %w", file.ID, err)
+		}
+		manifest.Files = append(manifest.Files, entry)
+		entries = append(entries, fileEntries...)
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	manifestData = append(manifestData, '\n')
+	if err := e.checkLimits(entries, int64(len(manifestData))); err != nil {
+		return err
+	}
+
+	limited := &limitedWriter{w: w, limit: e.limits.MaxUploadBytes}
+	zw := zip.NewWriter(limited)
+	total := int64(len(manifestData))
+	for _, entry := range entries {
+		n, err := e.writeVersion(ctx, zw, entry.name, entry.version)
+		if err != nil {
+			return fmt.Errorf("failed to write version %d of file %s: %w", entry.version.VersionNumber, entry.version.FileID, err)
+		}
+		total += n
+	}
+	manifestWriter, err := zw.Create(ManifestName)
+	if err != nil {
+		return err
+	}
+	if _, err := manifestWriter.Write(manifestData); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	// степень сжатия известна только в конце; ImportArchive считает её так же
+	if limited.n > 0 && total > 1024*1024 && float64(total)/float64(limited.n) > e.limits.MaxCompressionRatio {
+		return fmt.Errorf("%w: compression ratio is more than %.0f:1", ErrTooLarge, e.limits.MaxCompressionRatio)
+	}
+	return nil
+}
+
+// checkLimits проверяет число и размеры записей до того, как что-либо записано.
+func (e *Exporter) checkLimits(entries []exportEntry, manifestSize int64) error {
+	if count := len(entries) + 1; count > e.limits.MaxEntries {
+		return fmt.Errorf("%w: %d entries, at most %d allowed", ErrTooLarge, count, e.limits.MaxEntries)
+	}
+	total := manifestSize
+	if manifestSize > e.limits.MaxEntrySize {
+		return fmt.Errorf("%w: %s is %d bytes, at most %d allowed", ErrTooLarge, ManifestName, manifestSize, e.limits.MaxEntrySize)
+	}
+	for _, entry := range entries {
+		if entry.version.Size > e.limits.MaxEntrySize {
+			return fmt.Errorf("%w: version %d of file %s is %d bytes, at most %d allowed",
+				ErrTooLarge, entry.version.VersionNumber, entry.version.FileID, entry.version.Size, e.limits.MaxEntrySize)
+		}
+		total += entry.version.Size
+	}
+	if total > e.limits.MaxTotalSize {
+		return fmt.Errorf("%w: %d bytes of content, at most %d allowed", ErrTooLarge, total, e.limits.MaxTotalSize)
+	}
+	return nil
+}
+
+// describeFile собирает запись манифеста о файле и список версий, содержимое которых попадёт
+// в архив.
+func (e *Exporter) describeFile(ctx context.Context, file *fileInfo.File) (ManifestFile, []exportEntry, error) {
+	entry := ManifestFile{
+		ID:             file.ID.String(),
+		Name:           file.Name,
+		CreatedAt:      file.CreatedAt,
+		CurrentVersion: file.CurrentVersion,
+		Tags:           file.Tags,
+		Properties:     file.Properties,
+		Permissions:    []ManifestPermission{},
+		Versions:       []ManifestVersion{},
+	}
+
+	permissions, err := e.files.GetFilePermissions(ctx, file.ID)
+	if err != nil {
+		return entry, nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+	for _, p := range permissions {
+		entry.Permissions = append(entry.Permissions, ManifestPermission{UserID: p.UserID, Permission: p.Permission})
+	}
+
+	versions, err := e.files.GetFileVersions(ctx, file.ID)
+	if err != nil {
+		return entry, nil, fmt.Errorf("failed to get versions: %w", err)
+	}
+	var entries []exportEntry
+	// GetFileVersions отдаёт версии от новой к старой, в архиве они идут по возрастанию
+	for i := len(versions) - 1; i >= 0; i-- {
+		version := versions[i]
+		mv := ManifestVersion{
+			VersionNumber: version.VersionNumber,
+			Size:          version.Size,
+			ContentType:   version.ContentType,
+			CreatedAt:     version.CreatedAt,
+			ScanStatus:    version.ScanStatus,
+		}
+		if version.ScanStatus == fileInfo.ScanClean {
+			mv.Entry = EntryName(entry.ID, version.VersionNumber)
+			entries = append(entries, exportEntry{name: mv.Entry, version: version})
+		}
+		entry.Versions = append(entry.Versions, mv)
+	}
+	return entry, entries, nil
+}
+
+func (e *Exporter) writeVersion(ctx context.Context, zw *zip.Writer, name string, version *fileInfo.FileVersion) (int64, error) {
+	reader, err := e.store.Open(ctx, version)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	header := &zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: version.CreatedAt,
+	}
+	if archive.IsPrecompressed(version.ContentType) {
+		header.Method = zip.Store
+	}
+	entryWriter, err := zw.CreateHeader(header)
+	if err != nil {
+		return 0, err
+	}
+	return io.Copy(entryWriter, reader)
+}
+
+// limitedWriter обрывает запись, когда архив вырастает больше limit байт.
+type limitedWriter struct {
+	w     io.Writer
+	limit int64
+	n     int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if l.n+int64(len(p)) > l.limit {
+		return 0, fmt.Errorf("%w: archive is larger than %d bytes", ErrTooLarge, l.limit)
+	}
+	n, err := l.w.Write(p)
+	l.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,114 @@
+package takeout
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// ManifestName — последняя запись архива выгрузки.
+	ManifestName = "manifest.json"
+	// Format — версия формата архива; ImportArchive принимает только её.
+	Format = "takeout/v1"
+)
+
+var ErrInvalidManifest = errors.New("invalid takeout manifest")
+
+type Manifest struct {
+	Format     string         `json:"format"`
+	OwnerID    uint32         `json:"owner_id"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Files      []ManifestFile `json:"files"`
+}
+
+type ManifestFile struct {
+	ID             string               `json:"id"`
+	Name           string               `json:"name"`
+	CreatedAt      time.Time            `json:"created_at"`
+	CurrentVersion int                  `json:"current_version"`
+	Tags           []string             `json:"tags"`
+	Properties     map[string]string    `json:"properties"`
+	Permissions    []ManifestPermission `json:"permissions"`
+	// Versions — версии по возрастанию номера
+	Versions []ManifestVersion `json:"versions"`
+}
+
+type ManifestPermission struct {
+	UserID     int32 `json:"user_id"`
+	Permission int   `json:"permission"`
+}
+
+type ManifestVersion struct {
+	VersionNumber uint32    `json:"version_number"`
+	Size          int64     `json:"size"`
+	ContentType   string    `json:"content_type"`
+	CreatedAt     time.Time `json:"created_at"`
+	ScanStatus    string    `json:"scan_status"`
+	// Entry — запись архива с содержимым; пуста у версий в карантине, их содержимое не выгружается
+	Entry string `json:"entry,omitempty"`
+}
+
+// EntryName — имя записи архива с содержимым версии.
+func EntryName(fileID string, versionNumber uint32) string {
+	return fmt.Sprintf("files/%s/v%d", fileID, versionNumber)
+}
+
+// ReadManifest читает и проверяет manifest.json из архива выгрузки: формат, уникальность
+// файлов и номеров версий, наличие записей, на которые ссылаются версии.
+func ReadManifest(zr *zip.Reader) (*Manifest, error) {
+	f, err := zr.Open(ManifestName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s not found", ErrInvalidManifest, ManifestName)
+	}
+	defer f.Close()
+
+	var manifest Manifest
+	if err := json.NewDecoder(io.LimitReader(f, maxManifestSize)).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+	}
+	if manifest.Format != Format {
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidManifest, manifest.Format)
+	}
+
+	entries := make(map[string]*zip.File, len(zr.File))
+	for _, entry := range zr.File {
+		entries[entry.Name] = entry
+	}
+	files := make(map[string]struct{}, len(manifest.Files))
+	for _, file := range manifest.Files {
+		if _, ok := files[file.ID]; ok {
+			return nil, fmt.Errorf("%w: file %s is listed twice", ErrInvalidManifest, file.ID)
+		}
+		files[file.ID] = struct{}{}
+		versions := make(map[uint32]struct{}, len(file.Versions))
+		for _, version := range file.Versions {
+			if version.VersionNumber == 0 {
+				return nil, fmt.Errorf("%w: file %s has version 0", ErrInvalidManifest, file.ID)
+			}
+			if _, ok := versions[version.VersionNumber]; ok {
+				return nil, fmt.Errorf("%w: file %s has version %d twice", ErrInvalidManifest, file.ID, version.VersionNumber)
+			}
+			versions[version.VersionNumber] = struct{}{}
+			if version.Entry == "" {
+				continue
+			}
+			entry, ok := entries[version.Entry]
+			if !ok {
+				return nil, fmt.Errorf("%w: entry %s not found", ErrInvalidManifest, version.Entry)
+			}
+			if int64(entry.UncompressedSize64) != version.Size {
+				return nil, fmt.Errorf("%w: entry %s is %d bytes, manifest says %d",
+					ErrInvalidManifest, version.Entry, entry.UncompressedSize64, version.Size)
+			}
+		}
+	}
+	return &manifest, nil
+}
+
+// maxManifestSize ограничивает чтение манифеста: запись уже прошла archive.Validate,
+// но её содержимое целиком декодируется в память.
+const maxManifestSize = 64 << 20
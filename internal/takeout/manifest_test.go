@@ -0,0 +1,72 @@
+package takeout_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"registration-service/internal/takeout"
+)
+
+func buildArchive(t *testing.T, manifest takeout.Manifest, entries map[string]string) *zip.Reader {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	w, err := zw.Create(takeout.ManifestName)
+	require.NoError(t, err)
+	require.NoError(t, json.NewEncoder(w).Encode(manifest))
+	require.NoError(t, zw.Close())
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	return zr
+}
+
+func TestReadManifest_Rejects(t *testing.T) {
+	entry := takeout.EntryName("a", 1)
+	for name, tc := range map[string]struct {
+		manifest takeout.Manifest
+		entries  map[string]string
+	}{
+		"unknown format": {
+			manifest: takeout.Manifest{Format: "takeout/v0"},
+		},
+		"missing entry": {
+			manifest: takeout.Manifest{Format: takeout.Format, Files: []takeout.ManifestFile{
+				{ID: "a", Versions: []takeout.ManifestVersion{{VersionNumber: 1, Size: 3, Entry: entry}}},
+			}},
+		},
+		"size mismatch": {
+			manifest: takeout.Manifest{Format: takeout.Format, Files: []takeout.ManifestFile{
+				{ID: "a", Versions: []takeout.ManifestVersion{{VersionNumber: 1, Size: 10, Entry: entry}}},
+			}},
+			entries: map[string]string{entry: "abc"},
+		},
+		"duplicate version": {
+			manifest: takeout.Manifest{Format: takeout.Format, Files: []takeout.ManifestFile{
+				{ID: "a", Versions: []takeout.ManifestVersion{{VersionNumber: 1}, {VersionNumber: 1}}},
+			}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := takeout.ReadManifest(buildArchive(t, tc.manifest, tc.entries))
+			assert.ErrorIs(t, err, takeout.ErrInvalidManifest)
+		})
+	}
+}
+
+func TestReadManifest_AcceptsVersionsWithoutContent(t *testing.T) {
+	entry := takeout.EntryName("a", 2)
+	manifest, err := takeout.ReadManifest(buildArchive(t, takeout.Manifest{Format: takeout.Format, Files: []takeout.ManifestFile{
+		{ID: "a", Versions: []takeout.ManifestVersion{{VersionNumber: 1, ScanStatus: "infected"}, {VersionNumber: 2, Size: 3, Entry: entry}}},
+	}}, map[string]string{entry: "abc"}))
+	require.NoError(t, err)
+	assert.Len(t, manifest.Files[0].Versions, 2)
+}
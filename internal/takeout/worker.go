@@ -0,0 +1,208 @@
+package takeout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"registration-service/internal/archive"
+	"registration-service/internal/model/fileInfo"
+	"registration-service/internal/storage"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Config struct {
+	// TTL — сколько готовый архив (или запись о неудачной выгрузке) хранится до удаления
+	TTL          time.Duration `env:"EXPORT_TTL" env-default:"168h"`
+	PollInterval time.Duration `env:"EXPORT_POLL_INTERVAL" env-default:"30s"`
+	// Lease — сколько задача принадлежит экземпляру, который её забрал; должен покрывать
+	// сборку самого большого архива
+	Lease     time.Duration `env:"EXPORT_LEASE" env-default:"2h"`
+	KeyPrefix string        `env:"EXPORT_KEY_PREFIX" env-default:"exports/"`
+}
+
+// ExportRepository — часть exportRepo.ExportRepository, которая нужна воркеру.
+type ExportRepository interface {
+	ClaimExport(ctx context.Context, lease time.Duration) (*fileInfo.Export, error)
+	CompleteExport(ctx context.Context, export *fileInfo.Export, expiresAt time.Time) (bool, error)
+	FailExport(ctx context.Context, export *fileInfo.Export, lastError string, expiresAt time.Time) error
+	ListExpiredExports(ctx context.Context, limit int) ([]*fileInfo.Export, error)
+	DeleteExport(ctx context.Context, exportID uuid.UUID) error
+}
+
+// Store — часть versionStore.VersionStore, которая нужна воркеру: архив хранится как объект
+// версии, сжатым и зашифрованным по тем же правилам.
+type Store interface {
+	ContentStore
+	Prepare(ctx context.Context, version *fileInfo.FileVersion) error
+	Put(ctx context.Context, version *fileInfo.FileVersion, data io.Reader) error
+	Delete(ctx context.Context, key string) error
+}
+
+const cleanupBatch = 100
+
+var errUploadAborted = errors.New("archive upload aborted")
+
+// Worker собирает архивы выгрузок из очереди в таблице exports и удаляет просроченные.
+// Очередь хранится в БД, поэтому задачи переживают перезапуск; Enqueue лишь будит воркер,
+// не дожидаясь PollInterval.
+type Worker struct {
+	exports  ExportRepository
+	exporter *Exporter
+	store    Store
+	cfg      Config
+	wake     chan struct{}
+}
+
+// NewWorker создаёт воркер; limits — лимиты ImportArchive, в которые должен уложиться архив.
+func NewWorker(files FileRepository, exports ExportRepository, store Store, cfg Config, limits archive.Config) *Worker {
+	return &Worker{
+		exports:  exports,
+		exporter: NewExporter(files, store, limits),
+		store:    store,
+		cfg:      cfg,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+func (w *Worker) Enqueue() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		for {
+			processed, err := w.ProcessOnce(ctx)
+			if err != nil {
+				log.Printf("[takeout.Worker] export error: %v", err)
+			}
+			if !processed || ctx.Err() != nil {
+				break
+			}
+		}
+		if _, err := w.Cleanup(ctx); err != nil {
+			log.Printf("[takeout.Worker] cleanup error: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.wake:
+		}
+	}
+}
+
+// ProcessOnce собирает одну выгрузку из очереди. false — очередь пуста.
+func (w *Worker) ProcessOnce(ctx context.Context) (bool, error) {
+	export, err := w.exports.ClaimExport(ctx, w.cfg.Lease)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim export: %w", err)
+	}
+	if export == nil {
+		return false, nil
+	}
+
+	expiresAt := time.Now().Add(w.cfg.TTL)
+	if buildErr := w.build(ctx, export); buildErr != nil {
+		log.Printf("[takeout.Worker] export %s failed: %v", export.ID, buildErr)
+		if err := w.exports.FailExport(ctx, export, buildErr.Error(), expiresAt); err != nil {
+			return true, fmt.Errorf("failed to mark export %s failed: %w", export.ID, err)
+		}
+		return true, nil
+	}
+
+	ok, err := w.exports.CompleteExport(ctx, export, expiresAt)
+	if err != nil || !ok {
+		// задачу забрал другой экземпляр или выгрузку удалили: этот архив никому не нужен
+		w.deleteObject(ctx, export.StorageKey)
+	}
+	if err != nil {
+		return true, fmt.Errorf("failed to complete export %s: %w", export.ID, err)
+	}
+	return true, nil
+}
+
+// build пишет архив в хранилище потоком: ZIP собирается в одной горутине и сразу
+// загружается через io.Pipe, не занимая ни память, ни диск. У каждой попытки свой ключ:
+// экземпляр, у которого истекла аренда, не перезапишет и не удалит архив следующей попытки.
+// Объект попытки, оборвавшейся вместе с процессом, остаётся сиротой для reconcile.
+func (w *Worker) build(ctx context.Context, export *fileInfo.Export) error {
+	export.StorageKey = w.cfg.KeyPrefix + export.ID.String() + "-" + uuid.NewString() + ".zip"
+	object := export.Object()
+	object.Size = -1
+	if err := w.store.Prepare(ctx, object); err != nil {
+		return fmt.Errorf("failed to prepare archive object: %w", err)
+	}
+
+	pr, pw := io.Pipe()
+	counted := &countingWriter{w: pw}
+	written := make(chan error, 1)
+	go func() {
+		err := w.exporter.WriteArchive(ctx, export.OwnerID, counted)
+		pw.CloseWithError(err)
+		written <- err
+	}()
+	putErr := w.store.Put(ctx, object, pr)
+	// освобождаем писателя, если загрузка прервалась раньше конца архива
+	pr.CloseWithError(errUploadAborted)
+	if err := <-written; err != nil && !errors.Is(err, errUploadAborted) {
+		w.deleteObject(ctx, export.StorageKey)
+		return err
+	}
+	if putErr != nil {
+		w.deleteObject(ctx, export.StorageKey)
+		return fmt.Errorf("failed to store archive: %w", putErr)
+	}
+
+	export.Size = counted.n
+	export.Codec = object.Codec
+	export.KeyID, export.WrappedKey = object.KeyID, object.WrappedKey
+	return nil
+}
+
+// Cleanup удаляет выгрузки, срок хранения которых истёк, вместе с их архивами.
+func (w *Worker) Cleanup(ctx context.Context) (int, error) {
+	expired, err := w.exports.ListExpiredExports(ctx, cleanupBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expired exports: %w", err)
+	}
+	deleted := 0
+	for _, export := range expired {
+		if export.StorageKey != "" {
+			if err := w.store.Delete(ctx, export.StorageKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+				log.Printf("[takeout.Worker] failed to delete archive %s: %v", export.StorageKey, err)
+				continue
+			}
+		}
+		if err := w.exports.DeleteExport(ctx, export.ID); err != nil {
+			return deleted, fmt.Errorf("failed to delete export %s: %w", export.ID, err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (w *Worker) deleteObject(ctx context.Context, key string) {
+	if err := w.store.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("[takeout.Worker] failed to delete archive %s: %v", key, err)
+	}
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
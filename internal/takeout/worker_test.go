@@ -0,0 +1,281 @@
+package takeout_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"registration-service/internal/archive"
+	"registration-service/internal/compression"
+	"registration-service/internal/model/fileInfo"
+	"registration-service/internal/storage"
+	"registration-service/internal/storage/memory"
+	"registration-service/internal/takeout"
+	"registration-service/internal/versionStore"
+)
+
+// testLimits — лимиты импорта, в которые должен уложиться архив выгрузки.
+var testLimits = archive.Config{
+	MaxUploadBytes: 1 << 20, MaxEntries: 10, MaxEntrySize: 16 << 10, MaxTotalSize: 1 << 20, MaxCompressionRatio: 100,
+}
+
+type fakeFiles struct {
+	files       []*fileInfo.File
+	versions    map[uuid.UUID][]*fileInfo.FileVersion
+	permissions map[uuid.UUID][]fileInfo.FilePermission
+}
+
+func (r *fakeFiles) ListFilesByOwner(ctx context.Context, ownerID int, filter fileInfo.FileFilter) ([]*fileInfo.File, error) {
+	var out []*fileInfo.File
+	for _, f := range r.files {
+		if int(f.OwnerID) == ownerID {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeFiles) GetFileVersions(ctx context.Context, fileID uuid.UUID) ([]*fileInfo.FileVersion, error) {
+	return r.versions[fileID], nil
+}
+
+func (r *fakeFiles) GetFilePermissions(ctx context.Context, fileID uuid.UUID) ([]fileInfo.FilePermission, error) {
+	return r.permissions[fileID], nil
+}
+
+type fakeExports struct {
+	queue     []*fileInfo.Export
+	completed []*fileInfo.Export
+	failed    map[uuid.UUID]string
+	expired   []*fileInfo.Export
+	deleted   []uuid.UUID
+}
+
+func (r *fakeExports) ClaimExport(ctx context.Context, lease time.Duration) (*fileInfo.Export, error) {
+	if len(r.queue) == 0 {
+		return nil, nil
+	}
+	export := r.queue[0]
+	r.queue = r.queue[1:]
+	export.Status = fileInfo.ExportRunning
+	return export, nil
+}
+
+func (r *fakeExports) CompleteExport(ctx context.Context, export *fileInfo.Export, expiresAt time.Time) (bool, error) {
+	export.Status = fileInfo.ExportReady
+	export.ExpiresAt = &expiresAt
+	r.completed = append(r.completed, export)
+	return true, nil
+}
+
+func (r *fakeExports) FailExport(ctx context.Context, export *fileInfo.Export, lastError string, expiresAt time.Time) error {
+	r.failed[export.ID] = lastError
+	return nil
+}
+
+func (r *fakeExports) ListExpiredExports(ctx context.Context, limit int) ([]*fileInfo.Export, error) {
+	return r.expired, nil
+}
+
+func (r *fakeExports) DeleteExport(ctx context.Context, exportID uuid.UUID) error {
+	r.deleted = append(r.deleted, exportID)
+	return nil
+}
+
+func putVersion(t *testing.T, store *versionStore.VersionStore, fileID uuid.UUID, number uint32, content, scanStatus string) *fileInfo.FileVersion {
+	version := &fileInfo.FileVersion{
+		FileID:        fileID,
+		VersionNumber: number,
+		StorageKey:    fmt.Sprintf("%s/v%d", fileID, number),
+		Size:          int64(len(content)),
+		ContentType:   "text/plain",
+		CreatedAt:     time.Date(2024, 1, int(number), 0, 0, 0, 0, time.UTC),
+		ScanStatus:    scanStatus,
+	}
+	require.NoError(t, store.Put(context.Background(), version, strings.NewReader(content)))
+	return version
+}
+
+func TestWorker_BuildsArchiveWithHistory(t *testing.T) {
+	ctx := context.Background()
+	objects := memory.New()
+	store := versionStore.New(objects, nil, nil, compression.Config{Codec: compression.CodecZstd, MinSize: 1})
+
+	fileID := uuid.New()
+	v1 := putVersion(t, store, fileID, 1, "first", fileInfo.ScanClean)
+	v2 := putVersion(t, store, fileID, 2, "infected", fileInfo.ScanInfected)
+	v3 := putVersion(t, store, fileID, 3, "third revision", fileInfo.ScanClean)
+	files := &fakeFiles{
+		files: []*fileInfo.File{{
+			ID: fileID, OwnerID: 7, Name: "notes.txt", CurrentVersion: 3,
+			Tags: []string{"work"}, Properties: map[string]string{"project": "x"},
+		}},
+		versions:    map[uuid.UUID][]*fileInfo.FileVersion{fileID: {v3, v2, v1}},
+		permissions: map[uuid.UUID][]fileInfo.FilePermission{fileID: {{FileID: fileID, UserID: 9, Permission: 1}}},
+	}
+	export := &fileInfo.Export{ID: uuid.New(), OwnerID: 7, Status: fileInfo.ExportPending}
+	exports := &fakeExports{queue: []*fileInfo.Export{export}, failed: map[uuid.UUID]string{}}
+
+	worker := takeout.NewWorker(files, exports, store, takeout.Config{TTL: time.Hour, KeyPrefix: "exports/"}, testLimits)
+	processed, err := worker.ProcessOnce(ctx)
+	require.NoError(t, err)
+	assert.True(t, processed)
+	require.Len(t, exports.completed, 1)
+	assert.True(t, strings.HasPrefix(export.StorageKey, "exports/"+export.ID.String()+"-"), export.StorageKey)
+
+	reader, err := store.Open(ctx, export.Object())
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	assert.EqualValues(t, len(data), export.Size)
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	assert.Equal(t, takeout.ManifestName, zr.File[len(zr.File)-1].Name, "manifest is the last entry")
+	manifest, err := takeout.ReadManifest(zr)
+	require.NoError(t, err)
+
+	require.Len(t, manifest.Files, 1)
+	file := manifest.Files[0]
+	assert.Equal(t, "notes.txt", file.Name)
+	assert.Equal(t, []string{"work"}, file.Tags)
+	assert.Equal(t, []takeout.ManifestPermission{{UserID: 9, Permission: 1}}, file.Permissions)
+	require.Len(t, file.Versions, 3)
+	assert.Equal(t, []uint32{1, 2, 3}, []uint32{file.Versions[0].VersionNumber, file.Versions[1].VersionNumber, file.Versions[2].VersionNumber})
+	assert.Empty(t, file.Versions[1].Entry, "quarantined content is not exported")
+	assert.Equal(t, v1.CreatedAt, file.Versions[0].CreatedAt)
+
+	entry, err := zr.Open(file.Versions[2].Entry)
+	require.NoError(t, err)
+	content, err := io.ReadAll(entry)
+	require.NoError(t, err)
+	assert.Equal(t, "third revision", string(content))
+}
+
+func TestWorker_FailsAndCleansUp(t *testing.T) {
+	ctx := context.Background()
+	objects := memory.New()
+	store := versionStore.New(objects, nil, nil, compression.Config{Codec: compression.CodecNone})
+
+	// объекта версии нет в хранилище: сборка архива обрывается посередине
+	fileID := uuid.New()
+	files := &fakeFiles{
+		files: []*fileInfo.File{{ID: fileID, OwnerID: 7, Name: "lost.txt"}},
+		versions: map[uuid.UUID][]*fileInfo.FileVersion{fileID: {{
+			FileID: fileID, VersionNumber: 1, StorageKey: "lost/v1", Size: 4, ScanStatus: fileInfo.ScanClean, Codec: compression.CodecNone,
+		}}},
+	}
+	export := &fileInfo.Export{ID: uuid.New(), OwnerID: 7}
+	stale := &fileInfo.Export{ID: uuid.New(), StorageKey: "exports/stale.zip"}
+	require.NoError(t, objects.Put(ctx, stale.StorageKey, strings.NewReader("zip"), 3, ""))
+	exports := &fakeExports{queue: []*fileInfo.Export{export}, expired: []*fileInfo.Export{stale}, failed: map[uuid.UUID]string{}}
+
+	worker := takeout.NewWorker(files, exports, store, takeout.Config{TTL: time.Hour, KeyPrefix: "exports/"}, testLimits)
+	_, err := worker.ProcessOnce(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, exports.completed)
+	assert.Contains(t, exports.failed[export.ID], "lost")
+	_, err = objects.Stat(ctx, export.StorageKey)
+	assert.ErrorIs(t, err, storage.ErrNotFound, "partial archive is removed")
+
+	deleted, err := worker.Cleanup(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.Equal(t, []uuid.UUID{stale.ID}, exports.deleted)
+	_, err = objects.Stat(ctx, stale.StorageKey)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestWorker_RejectsExportBeyondImportLimits(t *testing.T) {
+	ctx := context.Background()
+	objects := memory.New()
+	store := versionStore.New(objects, nil, nil, compression.Config{Codec: compression.CodecNone})
+
+	fileID := uuid.New()
+	big := putVersion(t, store, fileID, 1, strings.Repeat("x", 32<<10), fileInfo.ScanClean)
+	files := &fakeFiles{
+		files:    []*fileInfo.File{{ID: fileID, OwnerID: 7, Name: "big.txt"}},
+		versions: map[uuid.UUID][]*fileInfo.FileVersion{fileID: {big}},
+	}
+	export := &fileInfo.Export{ID: uuid.New(), OwnerID: 7}
+	exports := &fakeExports{queue: []*fileInfo.Export{export}, failed: map[uuid.UUID]string{}}
+
+	worker := takeout.NewWorker(files, exports, store, takeout.Config{TTL: time.Hour, KeyPrefix: "exports/"}, testLimits)
+	_, err := worker.ProcessOnce(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, exports.completed)
+	// архив, который не примет ImportArchive, не собирается вовсе
+	assert.Contains(t, exports.failed[export.ID], takeout.ErrTooLarge.Error())
+	assert.Contains(t, exports.failed[export.ID], "at most 16384 allowed")
+	_, err = objects.Stat(ctx, export.StorageKey)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+// lateExports отдаёт задачу дважды, как после истечения аренды, пока первый экземпляр ещё
+// собирает архив. Завершить выгрузку успевает одна попытка, вторая опаздывает.
+type lateExports struct {
+	fakeExports
+	attempts int
+}
+
+func (r *lateExports) ClaimExport(ctx context.Context, lease time.Duration) (*fileInfo.Export, error) {
+	if len(r.queue) == 0 {
+		return nil, nil
+	}
+	claimed := *r.queue[0]
+	r.attempts++
+	if r.attempts == 2 {
+		r.queue = nil
+	}
+	return &claimed, nil
+}
+
+func (r *lateExports) CompleteExport(ctx context.Context, export *fileInfo.Export, expiresAt time.Time) (bool, error) {
+	if len(r.completed) > 0 {
+		return false, nil
+	}
+	return r.fakeExports.CompleteExport(ctx, export, expiresAt)
+}
+
+func TestWorker_LateAttemptKeepsCompletedArchive(t *testing.T) {
+	ctx := context.Background()
+	objects := memory.New()
+	store := versionStore.New(objects, nil, nil, compression.Config{Codec: compression.CodecNone})
+
+	fileID := uuid.New()
+	v1 := putVersion(t, store, fileID, 1, "content", fileInfo.ScanClean)
+	files := &fakeFiles{
+		files:    []*fileInfo.File{{ID: fileID, OwnerID: 7, Name: "notes.txt"}},
+		versions: map[uuid.UUID][]*fileInfo.FileVersion{fileID: {v1}},
+	}
+	exports := &lateExports{fakeExports: fakeExports{
+		queue: []*fileInfo.Export{{ID: uuid.New(), OwnerID: 7}}, failed: map[uuid.UUID]string{},
+	}}
+	worker := takeout.NewWorker(files, exports, store, takeout.Config{TTL: time.Hour, KeyPrefix: "exports/"}, testLimits)
+
+	_, err := worker.ProcessOnce(ctx)
+	require.NoError(t, err)
+	require.Len(t, exports.completed, 1)
+	// опоздавшая попытка удаляет только свой архив
+	_, err = worker.ProcessOnce(ctx)
+	require.NoError(t, err)
+	require.Len(t, exports.completed, 1)
+
+	_, err = objects.Stat(ctx, exports.completed[0].StorageKey)
+	assert.NoError(t, err, "the completed archive survives")
+	var archives []string
+	require.NoError(t, objects.List(ctx, "exports/", func(info storage.ObjectInfo) error {
+		archives = append(archives, info.Key)
+		return nil
+	}))
+	assert.Equal(t, []string{exports.completed[0].StorageKey}, archives)
+}
@@ -0,0 +1,119 @@
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+)
+
+const (
+	ContentTypeJPEG = "image/jpeg"
+	ContentTypePNG  = "image/png"
+)
+
+var (
+	ErrUnsupportedImage = errors.New("unsupported image format")
+	ErrImageTooLarge    = errors.New("image dimensions exceed limit")
+)
+
+// IsImage сообщает, нужно ли строить превью для такого content type.
+func IsImage(contentType string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(contentType)), "image/")
+}
+
+// Generate декодирует изображение, вписывает его в квадрат size×size с сохранением пропорций
+// и кодирует результат: JPEG для JPEG-источников, PNG для остальных, чтобы не терять прозрачность.
+// maxPixels ограничивает размер исходника до декодирования, защищая от «бомб» с огромными размерами.
+func Generate(src io.Reader, sourceContentType string, size int, maxPixels int) ([]byte, string, error) {
+	var header bytes.Buffer
+	cfg, _, err := image.DecodeConfig(io.TeeReader(src, &header))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, "", ErrImageTooLarge
+	}
+	img, _, err := image.Decode(io.MultiReader(&header, src))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+
+	thumb := Resize(img, size)
+	var out bytes.Buffer
+	if OutputContentType(sourceContentType) == ContentTypeJPEG {
+		if err := jpeg.Encode(&out, thumb, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, "", fmt.Errorf("encode jpeg: %w", err)
+		}
+		return out.Bytes(), ContentTypeJPEG, nil
+	}
+	if err := png.Encode(&out, thumb); err != nil {
+		return nil, "", fmt.Errorf("encode png: %w", err)
+	}
+	return out.Bytes(), ContentTypePNG, nil
+}
+
+// OutputContentType — формат превью для исходника с таким content type.
+func OutputContentType(sourceContentType string) string {
+	switch strings.ToLower(strings.TrimSpace(sourceContentType)) {
+	case ContentTypeJPEG, "image/jpg", "image/pjpeg":
+		return ContentTypeJPEG
+	}
+	return ContentTypePNG
+}
+
+// Resize уменьшает изображение так, чтобы большая сторона была равна size.
+// Каждый пиксель результата — среднее по соответствующему прямоугольнику источника.
+// Изображения меньше size не увеличиваются.
+func Resize(src image.Image, size int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+		draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+		return dst
+	}
+
+	dw, dh := size, size
+	if w > h {
+		dh = max(1, h*size/w)
+	} else {
+		dw = max(1, w*size/h)
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0 := b.Min.Y + y*h/dh
+		y1 := max(y0+1, b.Min.Y+(y+1)*h/dh)
+		for x := 0; x < dw; x++ {
+			x0 := b.Min.X + x*w/dw
+			x1 := max(x0+1, b.Min.X+(x+1)*w/dw)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBA64Model.Convert(src.At(sx, sy)).(color.NRGBA64)
+					r += uint64(c.R)
+					g += uint64(c.G)
+					bl += uint64(c.B)
+					a += uint64(c.A)
+					n++
+				}
+			}
+			dst.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(bl / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return dst
+}
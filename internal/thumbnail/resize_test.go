@@ -0,0 +1,84 @@
+package thumbnail_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"registration-service/internal/thumbnail"
+)
+
+func solidImage(w, h int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestResize(t *testing.T) {
+	t.Run("Keeps aspect ratio", func(t *testing.T) {
+		thumb := thumbnail.Resize(solidImage(800, 400, color.White), 256)
+		assert.Equal(t, 256, thumb.Bounds().Dx())
+		assert.Equal(t, 128, thumb.Bounds().Dy())
+
+		thumb = thumbnail.Resize(solidImage(300, 900, color.White), 256)
+		assert.Equal(t, 85, thumb.Bounds().Dx())
+		assert.Equal(t, 256, thumb.Bounds().Dy())
+	})
+
+	t.Run("Does not upscale", func(t *testing.T) {
+		thumb := thumbnail.Resize(solidImage(40, 30, color.White), 256)
+		assert.Equal(t, 40, thumb.Bounds().Dx())
+		assert.Equal(t, 30, thumb.Bounds().Dy())
+	})
+
+	t.Run("Averages colours", func(t *testing.T) {
+		thumb := thumbnail.Resize(solidImage(100, 100, color.NRGBA{R: 200, G: 100, B: 50, A: 255}), 10)
+		r, g, b, a := thumb.At(5, 5).RGBA()
+		assert.Equal(t, uint32(200), r>>8)
+		assert.Equal(t, uint32(100), g>>8)
+		assert.Equal(t, uint32(50), b>>8)
+		assert.Equal(t, uint32(255), a>>8)
+	})
+}
+
+func TestGenerate(t *testing.T) {
+	var src bytes.Buffer
+	assert.NoError(t, png.Encode(&src, solidImage(512, 256, color.Black)))
+
+	t.Run("PNG stays PNG", func(t *testing.T) {
+		data, contentType, err := thumbnail.Generate(bytes.NewReader(src.Bytes()), "image/png", 64, 1<<20)
+		assert.NoError(t, err)
+		assert.Equal(t, thumbnail.ContentTypePNG, contentType)
+		img, err := png.Decode(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 64, 32), img.Bounds())
+	})
+
+	t.Run("JPEG output for JPEG source", func(t *testing.T) {
+		var jpg bytes.Buffer
+		assert.NoError(t, jpeg.Encode(&jpg, solidImage(512, 256, color.Black), nil))
+		data, contentType, err := thumbnail.Generate(&jpg, "image/jpeg", 64, 1<<20)
+		assert.NoError(t, err)
+		assert.Equal(t, thumbnail.ContentTypeJPEG, contentType)
+		_, err = jpeg.Decode(bytes.NewReader(data))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Rejects oversized source", func(t *testing.T) {
+		_, _, err := thumbnail.Generate(bytes.NewReader(src.Bytes()), "image/png", 64, 1000)
+		assert.ErrorIs(t, err, thumbnail.ErrImageTooLarge)
+	})
+
+	t.Run("Rejects non-image", func(t *testing.T) {
+		_, _, err := thumbnail.Generate(bytes.NewReader([]byte("not an image")), "image/png", 64, 1<<20)
+		assert.ErrorIs(t, err, thumbnail.ErrUnsupportedImage)
+	})
+}
@@ -0,0 +1,140 @@
+package thumbnail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"registration-service/internal/model/fileInfo"
+	"registration-service/internal/storage"
+	"registration-service/internal/versionStore"
+	"sort"
+)
+
+type Config struct {
+	Sizes          []int `env:"THUMBNAIL_SIZES" env-separator:"," env-default:"64,256,1024"`
+	QueueSize      int   `env:"THUMBNAIL_QUEUE_SIZE" env-default:"128"`
+	MaxSourceBytes int64 `env:"THUMBNAIL_MAX_SOURCE_BYTES" env-default:"52428800"`
+	MaxPixels      int   `env:"THUMBNAIL_MAX_PIXELS" env-default:"50000000"`
+}
+
+// Key возвращает ключ превью рядом с объектом версии: <fileID>/v<N>/thumb-<size>.
+func Key(storageKey string, size int) string {
+	return fmt.Sprintf("%s/thumb-%d", storageKey, size)
+}
+
+// Worker строит превью для новых версий-изображений в фоне.
+// Если задача потерялась (переполнение очереди, рестарт), превью будет построено по запросу в Get.
+type Worker struct {
+	store *versionStore.VersionStore
+	cfg   Config
+	jobs  chan *fileInfo.FileVersion
+}
+
+func NewWorker(store *versionStore.VersionStore, cfg Config) *Worker {
+	sizes := append([]int(nil), cfg.Sizes...)
+	if len(sizes) == 0 {
+		sizes = []int{256}
+	}
+	sort.Ints(sizes)
+	cfg.Sizes = sizes
+	return &Worker{
+		store: store,
+		cfg:   cfg,
+		jobs:  make(chan *fileInfo.FileVersion, cfg.QueueSize),
+	}
+}
+
+func (w *Worker) Sizes() []int {
+	return w.cfg.Sizes
+}
+
+func (w *Worker) Enqueue(version *fileInfo.FileVersion) {
+	if !IsImage(version.ContentType) {
+		return
+	}
+	select {
+	case w.jobs <- version:
+	default:
+		log.Printf("[thumbnail.Worker] queue is full, thumbnails for %s will be built on demand", version.StorageKey)
+	}
+}
+
+func (w *Worker) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case version := <-w.jobs:
+			if err := w.generateAll(ctx, version); err != nil {
+				log.Printf("[thumbnail.Worker] failed to build thumbnails for %s: %v", version.StorageKey, err)
+			}
+		}
+	}
+}
+
+// PickSize выбирает наименьший настроенный размер не меньше запрошенного, иначе наибольший.
+func (w *Worker) PickSize(requested int) int {
+	for _, size := range w.cfg.Sizes {
+		if size >= requested {
+			return size
+		}
+	}
+	return w.cfg.Sizes[len(w.cfg.Sizes)-1]
+}
+
+// Get возвращает превью версии, при необходимости строя и сохраняя его.
+func (w *Worker) Get(ctx context.Context, version *fileInfo.FileVersion, size int) ([]byte, string, error) {
+	if !IsImage(version.ContentType) {
+		return nil, "", ErrUnsupportedImage
+	}
+	key := Key(version.StorageKey, size)
+	reader, err := w.store.OpenDerived(ctx, version, key)
+	if err == nil {
+		data, readErr := io.ReadAll(reader)
+		reader.Close()
+		if readErr != nil {
+			return nil, "", fmt.Errorf("failed to read thumbnail: %w", readErr)
+		}
+		return data, OutputContentType(version.ContentType), nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return nil, "", fmt.Errorf("failed to read thumbnail: %w", err)
+	}
+
+	data, contentType, err := w.generate(ctx, version, size)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := w.store.PutDerived(ctx, version, key, data, contentType); err != nil {
+		log.Printf("[thumbnail.Worker] failed to store thumbnail %s: %v", key, err)
+	}
+	return data, contentType, nil
+}
+
+func (w *Worker) generateAll(ctx context.Context, version *fileInfo.FileVersion) error {
+	for _, size := range w.cfg.Sizes {
+		data, contentType, err := w.generate(ctx, version, size)
+		if err != nil {
+			return err
+		}
+		key := Key(version.StorageKey, size)
+		if err := w.store.PutDerived(ctx, version, key, data, contentType); err != nil {
+			return fmt.Errorf("failed to store thumbnail %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func (w *Worker) generate(ctx context.Context, version *fileInfo.FileVersion, size int) ([]byte, string, error) {
+	if version.Size > w.cfg.MaxSourceBytes {
+		return nil, "", ErrImageTooLarge
+	}
+	reader, err := w.store.Open(ctx, version)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read original: %w", err)
+	}
+	defer reader.Close()
+	return Generate(reader, version.ContentType, size, w.cfg.MaxPixels)
+}
@@ -0,0 +1,162 @@
+package tiering
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"registration-service/internal/model/fileInfo"
+	"registration-service/internal/storage"
+	"time"
+)
+
+type Config struct {
+	// Enabled включает холодный уровень хранения (STORAGE_COLD_BUCKET / STORAGE_COLD_FS_ROOT)
+	Enabled bool `env:"TIERING_ENABLED" env-default:"false"`
+	// ColdAfter — через сколько без скачиваний файл целиком переносится в холодный уровень
+	ColdAfter time.Duration `env:"TIERING_COLD_AFTER" env-default:"720h"`
+	Interval  time.Duration `env:"TIERING_INTERVAL" env-default:"1h"`
+	BatchSize int           `env:"TIERING_BATCH" env-default:"100"`
+	// RestoreOnAccess возвращает скачанную холодную версию в горячий уровень
+	RestoreOnAccess bool `env:"TIERING_RESTORE_ON_ACCESS" env-default:"false"`
+	QueueSize       int  `env:"TIERING_QUEUE_SIZE" env-default:"64"`
+}
+
+// Repository — часть fileRepo.FileRepository, которая нужна политике хранения.
+type Repository interface {
+	GetFileVersionByID(ctx context.Context, versionID uint32) (*fileInfo.FileVersion, error)
+	ListVersionsForColdTier(ctx context.Context, accessedBefore time.Time, limit int) ([]*fileInfo.FileVersion, error)
+	SetVersionTier(ctx context.Context, versionID uint32, fromTier, toTier string) (bool, error)
+}
+
+// ObjectStore — часть versionStore.VersionStore, которая нужна политике хранения.
+type ObjectStore interface {
+	CopyToTier(ctx context.Context, version *fileInfo.FileVersion, tier string) error
+	DeleteFromTier(ctx context.Context, key, tier string) error
+}
+
+// Worker периодически переносит в холодный уровень нетекущие версии и версии файлов,
+// которые не скачивали дольше ColdAfter, и по запросу возвращает версии в горячий.
+// Объект сначала копируется, затем строка переключается на новый уровень и только потом
+// удаляется оригинал, так что читатели всегда находят объект там, куда указывает строка.
+type Worker struct {
+	repo     Repository
+	store    ObjectStore
+	cfg      Config
+	restores chan uint32
+}
+
+func NewWorker(repo Repository, store ObjectStore, cfg Config) *Worker {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	return &Worker{
+		repo:     repo,
+		store:    store,
+		cfg:      cfg,
+		restores: make(chan uint32, cfg.QueueSize),
+	}
+}
+
+// EnqueueRestore ставит холодную версию в очередь на возврат в горячий уровень,
+// если это разрешено RestoreOnAccess. Не блокирует: при полной очереди запрос отбрасывается.
+func (w *Worker) EnqueueRestore(version *fileInfo.FileVersion) {
+	if !w.cfg.Enabled || !w.cfg.RestoreOnAccess || version.Tier != fileInfo.TierCold {
+		return
+	}
+	select {
+	case w.restores <- version.ID:
+	default:
+		log.Printf("[tiering.Worker] restore queue is full, version %s stays cold", version.StorageKey)
+	}
+}
+
+func (w *Worker) Run(ctx context.Context) {
+	if !w.cfg.Enabled || w.cfg.Interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case versionID := <-w.restores:
+			if err := w.Restore(ctx, versionID); err != nil {
+				log.Printf("[tiering.Worker] failed to restore version %d: %v", versionID, err)
+			}
+		case <-ticker.C:
+			moved, err := w.Apply(ctx)
+			if err != nil {
+				log.Printf("[tiering.Worker] tiering pass failed: %v", err)
+			}
+			if moved > 0 {
+				log.Printf("[tiering.Worker] moved %d versions to cold tier", moved)
+			}
+		}
+	}
+}
+
+// Apply переносит в холодный уровень одну пачку подходящих версий и возвращает их число.
+// Версия, перенос которой не удался, остаётся горячей до следующего прохода.
+func (w *Worker) Apply(ctx context.Context) (int, error) {
+	versions, err := w.repo.ListVersionsForColdTier(ctx, time.Now().Add(-w.cfg.ColdAfter), w.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	moved := 0
+	for _, version := range versions {
+		ok, err := w.move(ctx, version, fileInfo.TierCold)
+		if err != nil {
+			log.Printf("[tiering.Worker] failed to move version %d to cold tier: %v", version.ID, err)
+			continue
+		}
+		if ok {
+			moved++
+		}
+	}
+	return moved, nil
+}
+
+// Restore возвращает версию в горячий уровень; версии, которые уже горячие или удалены, пропускаются.
+func (w *Worker) Restore(ctx context.Context, versionID uint32) error {
+	version, err := w.repo.GetFileVersionByID(ctx, versionID)
+	if err != nil {
+		return fmt.Errorf("failed to get version: %w", err)
+	}
+	if version == nil || version.Tier != fileInfo.TierCold {
+		return nil
+	}
+	_, err = w.move(ctx, version, fileInfo.TierHot)
+	return err
+}
+
+func (w *Worker) move(ctx context.Context, version *fileInfo.FileVersion, tier string) (bool, error) {
+	fromTier := version.Tier
+	if err := w.store.CopyToTier(ctx, version, tier); err != nil {
+		return false, err
+	}
+	ok, err := w.repo.SetVersionTier(ctx, version.ID, fromTier, tier)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		// строку изменили параллельно; копию можно удалить, только если на неё никто не ссылается
+		current, err := w.repo.GetFileVersionByID(ctx, version.ID)
+		if err != nil {
+			return false, err
+		}
+		if current == nil || current.Tier != tier {
+			if err := w.store.DeleteFromTier(ctx, version.StorageKey, tier); err != nil && !errors.Is(err, storage.ErrNotFound) {
+				log.Printf("[tiering.Worker] failed to delete stale copy of %s: %v", version.StorageKey, err)
+			}
+		}
+		return false, nil
+	}
+	if err := w.store.DeleteFromTier(ctx, version.StorageKey, fromTier); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		// строка уже указывает на новый уровень; оставшийся объект уберёт сверка хранилища
+		log.Printf("[tiering.Worker] failed to delete %s from %s tier: %v", version.StorageKey, fromTier, err)
+	}
+	version.Tier = tier
+	return true, nil
+}
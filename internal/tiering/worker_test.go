@@ -0,0 +1,131 @@
+package tiering_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"registration-service/internal/compression"
+	"registration-service/internal/model/fileInfo"
+	"registration-service/internal/storage"
+	"registration-service/internal/storage/memory"
+	"registration-service/internal/tiering"
+	"registration-service/internal/versionStore"
+)
+
+type fakeRepo struct {
+	versions map[uint32]*fileInfo.FileVersion
+	eligible []uint32
+}
+
+func (r *fakeRepo) GetFileVersionByID(ctx context.Context, versionID uint32) (*fileInfo.FileVersion, error) {
+	v, ok := r.versions[versionID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *v
+	return &copied, nil
+}
+
+func (r *fakeRepo) ListVersionsForColdTier(ctx context.Context, accessedBefore time.Time, limit int) ([]*fileInfo.FileVersion, error) {
+	var out []*fileInfo.FileVersion
+	for _, id := range r.eligible {
+		if v, ok := r.versions[id]; ok && v.Tier == fileInfo.TierHot {
+			copied := *v
+			out = append(out, &copied)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeRepo) SetVersionTier(ctx context.Context, versionID uint32, fromTier, toTier string) (bool, error) {
+	v, ok := r.versions[versionID]
+	if !ok || v.Tier != fromTier {
+		return false, nil
+	}
+	v.Tier = toTier
+	return true, nil
+}
+
+func TestWorker_MovesToColdAndRestores(t *testing.T) {
+	ctx := context.Background()
+	hot, cold := memory.New(), memory.New()
+	store := versionStore.New(hot, cold, nil, compression.Config{Codec: compression.CodecNone})
+
+	content := []byte("old revision")
+	version := &fileInfo.FileVersion{ID: 1, StorageKey: "f/v1", Size: int64(len(content)), ContentType: "text/plain"}
+	require.NoError(t, store.Put(ctx, version, bytes.NewReader(content)))
+	repo := &fakeRepo{versions: map[uint32]*fileInfo.FileVersion{1: version}, eligible: []uint32{1}}
+
+	worker := tiering.NewWorker(repo, store, tiering.Config{Enabled: true, RestoreOnAccess: true, QueueSize: 1})
+	moved, err := worker.Apply(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, moved)
+	assert.Equal(t, fileInfo.TierCold, version.Tier)
+
+	_, err = hot.Stat(ctx, "f/v1")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	r, err := store.Open(ctx, version)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+
+	require.NoError(t, worker.Restore(ctx, 1))
+	assert.Equal(t, fileInfo.TierHot, version.Tier)
+	_, err = hot.Stat(ctx, "f/v1")
+	assert.NoError(t, err)
+	_, err = cold.Stat(ctx, "f/v1")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestWorker_ConcurrentChange(t *testing.T) {
+	for name, tc := range map[string]struct {
+		race     func(repo *fakeRepo)
+		keepCopy bool
+	}{
+		// другой экземпляр успел перенести ту же версию: копия теперь принадлежит ему
+		"moved by another instance": {race: func(repo *fakeRepo) { repo.versions[1].Tier = fileInfo.TierCold }, keepCopy: true},
+		// файл удалили во время переноса: копия ни на что не ссылается
+		"deleted": {race: func(repo *fakeRepo) { delete(repo.versions, 1) }, keepCopy: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			hot, cold := memory.New(), memory.New()
+			store := versionStore.New(hot, cold, nil, compression.Config{Codec: compression.CodecNone})
+
+			version := &fileInfo.FileVersion{ID: 1, StorageKey: "f/v1", Size: 3, ContentType: "text/plain"}
+			require.NoError(t, store.Put(ctx, version, bytes.NewReader([]byte("abc"))))
+			repo := &racingRepo{fakeRepo: fakeRepo{versions: map[uint32]*fileInfo.FileVersion{1: version}, eligible: []uint32{1}}, race: tc.race}
+
+			moved, err := tiering.NewWorker(repo, store, tiering.Config{Enabled: true}).Apply(ctx)
+			require.NoError(t, err)
+			assert.Zero(t, moved)
+			_, err = cold.Stat(ctx, "f/v1")
+			if tc.keepCopy {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, storage.ErrNotFound)
+			}
+		})
+	}
+}
+
+// racingRepo применяет race перед первым SetVersionTier, имитируя параллельное изменение строки.
+type racingRepo struct {
+	fakeRepo
+	race func(repo *fakeRepo)
+}
+
+func (r *racingRepo) SetVersionTier(ctx context.Context, versionID uint32, fromTier, toTier string) (bool, error) {
+	if r.race != nil {
+		r.race(&r.fakeRepo)
+		r.race = nil
+	}
+	return r.fakeRepo.SetVersionTier(ctx, versionID, fromTier, toTier)
+}
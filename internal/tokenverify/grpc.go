@@ -0,0 +1,45 @@
+package tokenverify
+
+import (
+	"context"
+	auth "registration-service/api/authproto/proto-generate"
+	"registration-service/internal/jwtkeys"
+)
+
+// GRPCKeySource загружает JWKS через GetJWKS сервиса авторизации.
+func GRPCKeySource(client auth.AuthServiceClient) KeySource {
+	return func(ctx context.Context) (jwtkeys.JWKS, error) {
+		resp, err := client.GetJWKS(ctx, &auth.GetJWKSRequest{})
+		if err != nil {
+			return jwtkeys.JWKS{}, err
+		}
+		set := jwtkeys.JWKS{Keys: make([]jwtkeys.JWK, 0, len(resp.Keys))}
+		for _, k := range resp.Keys {
+			set.Keys = append(set.Keys, jwtkeys.JWK{
+				KeyType:   k.Kty,
+				KeyID:     k.Kid,
+				Algorithm: k.Alg,
+				Use:       k.Use,
+				Curve:     k.Crv,
+				X:         k.X,
+				N:         k.N,
+				E:         k.E,
+			})
+		}
+		return set, nil
+	}
+}
+
+// GRPCFallback проверяет токен через GetUIDByToken, как до перехода на JWKS.
+func GRPCFallback(client auth.AuthServiceClient) Fallback {
+	return func(ctx context.Context, token string) (uint32, error) {
+		resp, err := client.GetUIDByToken(ctx, &auth.GetUIDByTokenRequest{Token: token})
+		if err != nil {
+			return 0, err
+		}
+		if !resp.IsValid {
+			return 0, ErrInvalidToken
+		}
+		return resp.Uid, nil
+	}
+}
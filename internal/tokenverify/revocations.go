@@ -0,0 +1,168 @@
+package tokenverify
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"registration-service/internal/repository/BlackListRepo"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+// reconnectDelay — пауза перед повторной подпиской, если Redis недоступен.
+const reconnectDelay = time.Second
+
+// watermark — отметка RevokeAllTokens: токены пользователя с iat раньше issuedBefore отозваны.
+type watermark struct {
+	issuedBefore int64
+	expiresAt    time.Time
+}
+
+// RevocationList — локальная копия чёрного списка и отметок отзыва. Новые отзывы приходят через
+// pub/sub, а после каждой (пере)подписки копия пересобирается целиком, чтобы не потерять отзывы,
+// опубликованные пока подписки не было. Если Redis недоступен, действует последняя известная копия.
+type RevocationList struct {
+	repo *BlackListRepo.BlackListRepo
+
+	mu         sync.RWMutex
+	tokens     map[string]time.Time
+	watermarks map[uint32]watermark
+}
+
+func NewRevocationList(repo *BlackListRepo.BlackListRepo) *RevocationList {
+	return &RevocationList{repo: repo, tokens: map[string]time.Time{}, watermarks: map[uint32]watermark{}}
+}
+
+// IsRevoked сообщает, отозван ли токен; id — из BlackListRepo.RevocationID.
+func (l *RevocationList) IsRevoked(id string) bool {
+	l.mu.RLock()
+	expiresAt, ok := l.tokens[id]
+	l.mu.RUnlock()
+	return ok && time.Now().Before(expiresAt)
+}
+
+// IsRevokedForUser сообщает, попадает ли токен пользователя, выданный в issuedAt, под отметку
+// RevokeAllTokens. Токен без iat считается выданным до отметки.
+func (l *RevocationList) IsRevokedForUser(userID uint32, issuedAt *jwt.NumericDate) bool {
+	l.mu.RLock()
+	w, ok := l.watermarks[userID]
+	l.mu.RUnlock()
+	if !ok || !time.Now().Before(w.expiresAt) {
+		return false
+	}
+	return issuedAt == nil || issuedAt.Unix() < w.issuedBefore
+}
+
+// Run держит подписку на канал отзывов, пока не отменён ctx.
+func (l *RevocationList) Run(ctx context.Context) {
+	pruneTicker := time.NewTicker(time.Minute)
+	defer pruneTicker.Stop()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-pruneTicker.C:
+				l.prune()
+			}
+		}
+	}()
+
+	pubsub := l.repo.Subscribe(ctx)
+	defer pubsub.Close()
+	for {
+		msg, err := pubsub.Receive(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("[tokenverify.RevocationList] subscription error, using cached list: %v", err)
+			time.Sleep(reconnectDelay)
+			continue
+		}
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			if m.Kind == "subscribe" {
+				if err := l.Sync(ctx); err != nil {
+					log.Printf("[tokenverify.RevocationList] failed to sync blacklist: %v", err)
+				}
+			}
+		case *redis.Message:
+			var revocation BlackListRepo.Revocation
+			if err := json.Unmarshal([]byte(m.Payload), &revocation); err != nil {
+				log.Printf("[tokenverify.RevocationList] bad revocation message: %v", err)
+				continue
+			}
+			l.add(revocation)
+		}
+	}
+}
+
+// Sync заменяет копию текущим содержимым чёрного списка и отметок.
+func (l *RevocationList) Sync(ctx context.Context) error {
+	revocations, err := l.repo.ListTokens(ctx)
+	if err != nil {
+		return err
+	}
+	userRevocations, err := l.repo.ListWatermarks(ctx)
+	if err != nil {
+		return err
+	}
+	tokens := make(map[string]time.Time, len(revocations))
+	for _, r := range revocations {
+		tokens[r.Token] = r.ExpiresAt
+	}
+	watermarks := make(map[uint32]watermark, len(userRevocations))
+	for _, r := range userRevocations {
+		watermarks[r.UserID] = watermark{issuedBefore: r.IssuedBefore, expiresAt: r.ExpiresAt}
+	}
+	now := time.Now()
+	l.mu.Lock()
+	// отзывы, пришедшие во время SCAN, могли не попасть в выборку
+	for token, expiresAt := range l.tokens {
+		if _, ok := tokens[token]; !ok && now.Before(expiresAt) {
+			tokens[token] = expiresAt
+		}
+	}
+	for userID, w := range l.watermarks {
+		if current, ok := watermarks[userID]; now.Before(w.expiresAt) && (!ok || current.issuedBefore < w.issuedBefore) {
+			watermarks[userID] = w
+		}
+	}
+	l.tokens = tokens
+	l.watermarks = watermarks
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *RevocationList) add(r BlackListRepo.Revocation) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if r.Token != "" {
+		l.tokens[r.Token] = r.ExpiresAt
+		return
+	}
+	// сообщения могут прийти не по порядку: отметка только сдвигается вперёд
+	if w, ok := l.watermarks[r.UserID]; !ok || w.issuedBefore <= r.IssuedBefore {
+		l.watermarks[r.UserID] = watermark{issuedBefore: r.IssuedBefore, expiresAt: r.ExpiresAt}
+	}
+}
+
+func (l *RevocationList) prune() {
+	now := time.Now()
+	l.mu.Lock()
+	for token, expiresAt := range l.tokens {
+		if !now.Before(expiresAt) {
+			delete(l.tokens, token)
+		}
+	}
+	for userID, w := range l.watermarks {
+		if !now.Before(w.expiresAt) {
+			delete(l.watermarks, userID)
+		}
+	}
+	l.mu.Unlock()
+}
@@ -0,0 +1,212 @@
+// Package tokenverify проверяет access-токены без обращения к сервису авторизации: подпись и
+// срок — по закешированному JWKS, отзыв — по локальной копии чёрного списка из Redis.
+package tokenverify
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"log"
+	"registration-service/internal/jwtkeys"
+	"registration-service/internal/model/signingKey"
+	"registration-service/internal/repository/BlackListRepo"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrRevokedToken = errors.New("token has been revoked")
+)
+
+type Config struct {
+	// JWKSRefreshInterval — как часто ключи перечитываются в фоне
+	JWKSRefreshInterval time.Duration `env:"JWKS_REFRESH_INTERVAL" env-default:"5m"`
+	// JWKSMinRefreshInterval ограничивает внеочередные загрузки при незнакомом kid, чтобы
+	// токены с выдуманным kid не превращались в нагрузку на сервис авторизации
+	JWKSMinRefreshInterval time.Duration `env:"JWKS_MIN_REFRESH_INTERVAL" env-default:"10s"`
+	// AcceptHS256 — токены без kid проверяются через Fallback; включается только на время
+	// миграции, как и одноимённая настройка сервиса авторизации
+	AcceptHS256 bool `env:"JWT_ACCEPT_HS256" env-default:"false"`
+}
+
+// KeySource загружает актуальный JWKS, например через GetJWKS сервиса авторизации.
+type KeySource func(ctx context.Context) (jwtkeys.JWKS, error)
+
+// Fallback проверяет токен удалённо. Нужен только для токенов без kid, подписанных общим
+// секретом до перехода на JWKS, и вызывается только при Config.AcceptHS256.
+type Fallback func(ctx context.Context, token string) (uint32, error)
+
+type publicKey struct {
+	algorithm string
+	key       crypto.PublicKey
+}
+
+// Verifier хранит последние успешно загруженные ключи: если сервис авторизации недоступен,
+// токены продолжают проверяться по ним.
+type Verifier struct {
+	source      KeySource
+	fallback    Fallback
+	revocations *RevocationList
+	cfg         Config
+
+	mu          sync.RWMutex
+	keys        map[string]publicKey
+	lastFetch   time.Time
+	fetchMu     sync.Mutex
+	fetchFailed bool
+}
+
+func New(source KeySource, fallback Fallback, revocations *RevocationList, cfg Config) *Verifier {
+	return &Verifier{
+		source:      source,
+		fallback:    fallback,
+		revocations: revocations,
+		cfg:         cfg,
+		keys:        map[string]publicKey{},
+	}
+}
+
+// Run периодически обновляет ключи. Первая загрузка выполняется сразу.
+func (v *Verifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(v.cfg.JWKSRefreshInterval)
+	defer ticker.Stop()
+	for {
+		if err := v.Refresh(ctx); err != nil {
+			log.Printf("[tokenverify.Verifier] failed to refresh JWKS, keeping %d cached keys: %v", v.keyCount(), err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh загружает JWKS и заменяет кешированные ключи. При ошибке кеш не меняется.
+func (v *Verifier) Refresh(ctx context.Context) error {
+	v.fetchMu.Lock()
+	defer v.fetchMu.Unlock()
+	return v.fetch(ctx)
+}
+
+func (v *Verifier) fetch(ctx context.Context) error {
+	v.mu.Lock()
+	v.lastFetch = time.Now()
+	v.mu.Unlock()
+
+	set, err := v.source(ctx)
+	if err != nil {
+		v.fetchFailed = true
+		return err
+	}
+	keys := make(map[string]publicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		key, err := jwk.PublicKey()
+		if err != nil {
+			log.Printf("[tokenverify.Verifier] skipping key %s: %v", jwk.KeyID, err)
+			continue
+		}
+		keys[jwk.KeyID] = publicKey{algorithm: jwk.Algorithm, key: key}
+	}
+	if v.fetchFailed {
+		log.Printf("[tokenverify.Verifier] JWKS loaded again, %d keys", len(keys))
+		v.fetchFailed = false
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.mu.Unlock()
+	return nil
+}
+
+func (v *Verifier) keyCount() int {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return len(v.keys)
+}
+
+func (v *Verifier) lookup(kid string) (publicKey, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+// refreshForUnknownKID перечитывает JWKS, когда встретился незнакомый kid: после ротации
+// новый ключ подписывает сразу. Не чаще JWKSMinRefreshInterval.
+func (v *Verifier) refreshForUnknownKID(ctx context.Context, kid string) (publicKey, bool) {
+	v.fetchMu.Lock()
+	defer v.fetchMu.Unlock()
+	if key, ok := v.lookup(kid); ok {
+		// ключ загрузил параллельный запрос
+		return key, true
+	}
+	v.mu.RLock()
+	recent := time.Since(v.lastFetch) < v.cfg.JWKSMinRefreshInterval
+	v.mu.RUnlock()
+	if recent {
+		return publicKey{}, false
+	}
+	if err := v.fetch(ctx); err != nil {
+		log.Printf("[tokenverify.Verifier] failed to load JWKS for kid %q: %v", kid, err)
+		return publicKey{}, false
+	}
+	return v.lookup(kid)
+}
+
+// accessClaims — поля access-токена, нужные для проверки; sid — сессия, которой выдан токен.
+type accessClaims struct {
+	SessionID string `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Verify проверяет подпись, срок и отзыв токена — по jti, по sid отозванной сессии и по отметке
+// RevokeAllTokens — и возвращает ID пользователя.
+func (v *Verifier) Verify(ctx context.Context, token string) (uint32, error) {
+	unverified, _, err := jwt.NewParser().ParseUnverified(token, &jwt.RegisteredClaims{})
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	kid, hasKID := unverified.Header["kid"].(string)
+	if !hasKID {
+		if v.fallback == nil || !v.cfg.AcceptHS256 {
+			return 0, fmt.Errorf("%w: token has no kid", ErrInvalidToken)
+		}
+		return v.fallback(ctx, token)
+	}
+
+	claims := &accessClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		key, ok := v.lookup(kid)
+		if !ok {
+			key, ok = v.refreshForUnknownKID(ctx, kid)
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", jwtkeys.ErrUnknownKey, kid)
+		}
+		if t.Method.Alg() != key.algorithm {
+			return nil, fmt.Errorf("%w: token signed with %s, key is %s", jwtkeys.ErrUnsupportedAlgorithm, t.Method.Alg(), key.algorithm)
+		}
+		return key.key, nil
+	}, jwt.WithValidMethods([]string{signingKey.AlgEdDSA, signingKey.AlgRS256}), jwt.WithExpirationRequired())
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	uid, err := strconv.ParseUint(claims.Subject, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%w: bad subject", ErrInvalidToken)
+	}
+	if v.revocations.IsRevoked(BlackListRepo.RevocationID(claims.ID, token)) || v.revocations.IsRevokedForUser(uint32(uid), claims.IssuedAt) {
+		return 0, ErrRevokedToken
+	}
+	if claims.SessionID != "" && v.revocations.IsRevoked(BlackListRepo.SessionRevocationID(claims.SessionID)) {
+		return 0, ErrRevokedToken
+	}
+	return uint32(uid), nil
+}
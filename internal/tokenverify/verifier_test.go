@@ -0,0 +1,239 @@
+package tokenverify_test
+
+import (
+	"context"
+	"errors"
+	"registration-service/internal/jwtkeys"
+	"registration-service/internal/model/signingKey"
+	"registration-service/internal/repository/BlackListRepo"
+	"registration-service/internal/tokenverify"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type authServer struct {
+	ring  *jwtkeys.KeyRing
+	down  atomic.Bool
+	calls atomic.Int32
+}
+
+func newAuthServer(t *testing.T, store jwtkeys.Store, rotation time.Duration) *authServer {
+	ring, err := jwtkeys.NewKeyRing(store, nil, jwtkeys.Config{
+		Algorithm: signingKey.AlgEdDSA, RotationInterval: rotation, Overlap: time.Hour,
+	})
+	require.NoError(t, err)
+	require.NoError(t, ring.Load(context.Background()))
+	return &authServer{ring: ring}
+}
+
+func (a *authServer) source(ctx context.Context) (jwtkeys.JWKS, error) {
+	a.calls.Add(1)
+	if a.down.Load() {
+		return jwtkeys.JWKS{}, errors.New("auth service unavailable")
+	}
+	return a.ring.JWKS(), nil
+}
+
+func (a *authServer) token(t *testing.T, subject string) string {
+	token, err := a.ring.Sign(jwt.RegisteredClaims{Subject: subject, ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))})
+	require.NoError(t, err)
+	return token
+}
+
+func newRevocations(t *testing.T) (*tokenverify.RevocationList, *BlackListRepo.BlackListRepo) {
+	mr := miniredis.RunT(t)
+	repo := BlackListRepo.NewBlackListRepo(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	return tokenverify.NewRevocationList(repo), repo
+}
+
+func TestVerifier_VerifiesLocallyAndSurvivesAuthOutage(t *testing.T) {
+	ctx := context.Background()
+	auth := newAuthServer(t, jwtkeys.NewMemoryStore(), time.Hour)
+	revocations, _ := newRevocations(t)
+	verifier := tokenverify.New(auth.source, nil, revocations, tokenverify.Config{JWKSMinRefreshInterval: time.Hour})
+	require.NoError(t, verifier.Refresh(ctx))
+
+	token := auth.token(t, "42")
+	auth.down.Store(true)
+	for i := 0; i < 3; i++ {
+		uid, err := verifier.Verify(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(42), uid)
+	}
+	assert.EqualValues(t, 1, auth.calls.Load(), "keys are fetched once, not per request")
+
+	assert.Error(t, verifier.Refresh(ctx))
+	_, err := verifier.Verify(ctx, token)
+	assert.NoError(t, err, "failed refresh keeps cached keys")
+
+	_, err = verifier.Verify(ctx, token+"x")
+	assert.ErrorIs(t, err, tokenverify.ErrInvalidToken)
+}
+
+func TestVerifier_RefetchesKeysAfterRotation(t *testing.T) {
+	ctx := context.Background()
+	store := jwtkeys.NewMemoryStore()
+	auth := newAuthServer(t, store, time.Hour)
+	revocations, _ := newRevocations(t)
+	verifier := tokenverify.New(auth.source, nil, revocations, tokenverify.Config{JWKSMinRefreshInterval: 0})
+	require.NoError(t, verifier.Refresh(ctx))
+
+	// другой экземпляр сервиса авторизации ротировал ключ
+	rotated := newAuthServer(t, store, 0)
+	require.NoError(t, auth.ring.Load(ctx))
+	uid, err := verifier.Verify(ctx, rotated.token(t, "7"))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(7), uid)
+	assert.EqualValues(t, 2, auth.calls.Load())
+}
+
+func TestVerifier_LimitsRefetchesForUnknownKID(t *testing.T) {
+	ctx := context.Background()
+	auth := newAuthServer(t, jwtkeys.NewMemoryStore(), time.Hour)
+	stranger := newAuthServer(t, jwtkeys.NewMemoryStore(), time.Hour)
+	revocations, _ := newRevocations(t)
+	verifier := tokenverify.New(auth.source, nil, revocations, tokenverify.Config{JWKSMinRefreshInterval: time.Hour})
+	require.NoError(t, verifier.Refresh(ctx))
+
+	for i := 0; i < 5; i++ {
+		_, err := verifier.Verify(ctx, stranger.token(t, "1"))
+		assert.ErrorIs(t, err, tokenverify.ErrInvalidToken)
+	}
+	assert.EqualValues(t, 1, auth.calls.Load())
+}
+
+func TestVerifier_LegacyTokensUseFallbackOnlyDuringMigration(t *testing.T) {
+	ctx := context.Background()
+	auth := newAuthServer(t, jwtkeys.NewMemoryStore(), time.Hour)
+	revocations, _ := newRevocations(t)
+	var fallbackCalls int
+	fallback := func(ctx context.Context, token string) (uint32, error) {
+		fallbackCalls++
+		return 9, nil
+	}
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: "9"}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	// по умолчанию токены без kid отвергаются, не доходя до сервиса авторизации
+	_, err = tokenverify.New(auth.source, fallback, revocations, tokenverify.Config{}).Verify(ctx, legacy)
+	assert.ErrorIs(t, err, tokenverify.ErrInvalidToken)
+	assert.Zero(t, fallbackCalls)
+
+	verifier := tokenverify.New(auth.source, fallback, revocations, tokenverify.Config{AcceptHS256: true})
+	uid, err := verifier.Verify(ctx, legacy)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(9), uid)
+	assert.Equal(t, 1, fallbackCalls)
+}
+
+func TestRevocationList_FollowsBlacklist(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	auth := newAuthServer(t, jwtkeys.NewMemoryStore(), time.Hour)
+	revocations, repo := newRevocations(t)
+	verifier := tokenverify.New(auth.source, nil, revocations, tokenverify.Config{})
+	require.NoError(t, verifier.Refresh(ctx))
+
+	before, after := auth.token(t, "1"), auth.token(t, "2")
+	// отозван до подписки: попадает в копию при первичной синхронизации
+	require.NoError(t, repo.AddToken(ctx, before, time.Now().Add(time.Minute)))
+	go revocations.Run(ctx)
+	require.Eventually(t, func() bool { return revocations.IsRevoked(before) }, time.Second, 10*time.Millisecond)
+
+	_, err := verifier.Verify(ctx, after)
+	require.NoError(t, err)
+	require.NoError(t, repo.AddToken(ctx, after, time.Now().Add(time.Minute)))
+	require.Eventually(t, func() bool {
+		_, err := verifier.Verify(ctx, after)
+		return errors.Is(err, tokenverify.ErrRevokedToken)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRevocationList_FollowsWatermarks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	auth := newAuthServer(t, jwtkeys.NewMemoryStore(), time.Hour)
+	revocations, repo := newRevocations(t)
+	verifier := tokenverify.New(auth.source, nil, revocations, tokenverify.Config{})
+	require.NoError(t, verifier.Refresh(ctx))
+
+	issuedAt := func(subject string, at time.Time) string {
+		token, err := auth.ring.Sign(jwt.RegisteredClaims{
+			Subject:  subject,
+			IssuedAt: jwt.NewNumericDate(at), ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		})
+		require.NoError(t, err)
+		return token
+	}
+	// отметка поставлена до подписки: попадает в копию при первичной синхронизации
+	require.NoError(t, repo.RevokeIssuedBefore(ctx, 1, time.Now(), time.Hour))
+	go revocations.Run(ctx)
+
+	old := issuedAt("1", time.Now().Add(-time.Minute))
+	require.Eventually(t, func() bool {
+		_, err := verifier.Verify(ctx, old)
+		return errors.Is(err, tokenverify.ErrRevokedToken)
+	}, time.Second, 10*time.Millisecond)
+	_, err := verifier.Verify(ctx, issuedAt("1", time.Now().Add(time.Second)))
+	assert.NoError(t, err, "tokens issued after the watermark are accepted")
+
+	other := issuedAt("2", time.Now().Add(-time.Minute))
+	_, err = verifier.Verify(ctx, other)
+	require.NoError(t, err)
+	require.NoError(t, repo.RevokeIssuedBefore(ctx, 2, time.Now(), time.Hour))
+	require.Eventually(t, func() bool {
+		_, err := verifier.Verify(ctx, other)
+		return errors.Is(err, tokenverify.ErrRevokedToken)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestVerifier_RejectsRevokedSession(t *testing.T) {
+	ctx := context.Background()
+	auth := newAuthServer(t, jwtkeys.NewMemoryStore(), time.Hour)
+	revocations, repo := newRevocations(t)
+	verifier := tokenverify.New(auth.source, nil, revocations, tokenverify.Config{})
+	require.NoError(t, verifier.Refresh(ctx))
+
+	sign := func(sessionID string) string {
+		token, err := auth.ring.Sign(jwt.MapClaims{"sub": "5", "sid": sessionID, "exp": time.Now().Add(time.Minute).Unix()})
+		require.NoError(t, err)
+		return token
+	}
+	revoked, kept := sign("s1"), sign("s2")
+	require.NoError(t, repo.AddToken(ctx, BlackListRepo.SessionRevocationID("s1"), time.Now().Add(time.Minute)))
+	require.NoError(t, revocations.Sync(ctx))
+
+	_, err := verifier.Verify(ctx, revoked)
+	assert.ErrorIs(t, err, tokenverify.ErrRevokedToken)
+	_, err = verifier.Verify(ctx, kept)
+	assert.NoError(t, err)
+}
+
+func TestRevocationList_RevokesByJTI(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	auth := newAuthServer(t, jwtkeys.NewMemoryStore(), time.Hour)
+	revocations, repo := newRevocations(t)
+	verifier := tokenverify.New(auth.source, nil, revocations, tokenverify.Config{})
+	require.NoError(t, verifier.Refresh(ctx))
+	go revocations.Run(ctx)
+
+	token, err := auth.ring.Sign(jwt.RegisteredClaims{ID: "jti-1", Subject: "3", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))})
+	require.NoError(t, err)
+	_, err = verifier.Verify(ctx, token)
+	require.NoError(t, err)
+
+	// Logout заносит в чёрный список только jti, а не всю строку токена
+	require.NoError(t, repo.AddToken(ctx, "jti-1", time.Now().Add(time.Minute)))
+	require.Eventually(t, func() bool {
+		_, err := verifier.Verify(ctx, token)
+		return errors.Is(err, tokenverify.ErrRevokedToken)
+	}, time.Second, 10*time.Millisecond)
+}
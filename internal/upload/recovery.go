@@ -0,0 +1,116 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"log"
+	"registration-service/internal/model/fileInfo"
+	"registration-service/internal/storage"
+	"registration-service/internal/versionStore"
+	"time"
+)
+
+type Config struct {
+	// PendingTimeout — сколько загрузка может оставаться pending, прежде чем её подберёт восстановление.
+	// Должен быть больше времени загрузки самого большого файла, иначе идущая загрузка будет откатана
+	PendingTimeout time.Duration `env:"UPLOAD_PENDING_TIMEOUT" env-default:"1h"`
+	Interval       time.Duration `env:"UPLOAD_RECOVERY_INTERVAL" env-default:"5m"`
+	BatchSize      int           `env:"UPLOAD_RECOVERY_BATCH" env-default:"100"`
+}
+
+// Repository — часть fileRepo.FileRepository, которая нужна восстановлению.
+type Repository interface {
+	ListStalePendingVersions(ctx context.Context, before time.Time, limit int) ([]*fileInfo.FileVersion, error)
+	ActivateVersion(ctx context.Context, version *fileInfo.FileVersion) (bool, error)
+	DeletePendingVersion(ctx context.Context, versionID uint32) error
+}
+
+// ObjectStore — часть versionStore.VersionStore, которая нужна восстановлению.
+type ObjectStore interface {
+	Confirm(ctx context.Context, version *fileInfo.FileVersion) error
+	Delete(ctx context.Context, key string) error
+}
+
+// RecoveryWorker доводит до конца загрузки, прерванные между записью pending-строк и их активацией.
+// Если объект версии есть в хранилище, версия активируется; если его нет или он неполный,
+// версия (и файл, если это его единственная версия) удаляется.
+type RecoveryWorker struct {
+	repo        Repository
+	store       ObjectStore
+	cfg         Config
+	onActivated func(*fileInfo.FileVersion)
+}
+
+// NewRecoveryWorker создаёт обработчик; onActivated вызывается для каждой активированной версии
+// (например, чтобы поставить её в очередь сканера) и может быть nil.
+func NewRecoveryWorker(repo Repository, store ObjectStore, cfg Config, onActivated func(*fileInfo.FileVersion)) *RecoveryWorker {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	return &RecoveryWorker{repo: repo, store: store, cfg: cfg, onActivated: onActivated}
+}
+
+func (w *RecoveryWorker) Run(ctx context.Context) {
+	if w.cfg.Interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			activated, rolledBack, err := w.Recover(ctx)
+			if err != nil {
+				log.Printf("[upload.RecoveryWorker] recovery failed: %v", err)
+				continue
+			}
+			if activated > 0 || rolledBack > 0 {
+				log.Printf("[upload.RecoveryWorker] activated=%d rolled_back=%d", activated, rolledBack)
+			}
+		}
+	}
+}
+
+// Recover обрабатывает одну пачку зависших загрузок и возвращает число активированных и откатанных версий.
+// Версии, которые не удалось проверить, остаются pending до следующего прохода.
+func (w *RecoveryWorker) Recover(ctx context.Context) (activated, rolledBack int, err error) {
+	versions, err := w.repo.ListStalePendingVersions(ctx, time.Now().Add(-w.cfg.PendingTimeout), w.cfg.BatchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, version := range versions {
+		confirmErr := w.store.Confirm(ctx, version)
+		switch {
+		case confirmErr == nil:
+			ok, err := w.repo.ActivateVersion(ctx, version)
+			if err != nil {
+				log.Printf("[upload.RecoveryWorker] failed to activate version %d: %v", version.ID, err)
+				continue
+			}
+			if !ok {
+				continue
+			}
+			activated++
+			if w.onActivated != nil {
+				w.onActivated(version)
+			}
+		case errors.Is(confirmErr, versionStore.ErrSizeMismatch):
+			if err := w.store.Delete(ctx, version.StorageKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+				log.Printf("[upload.RecoveryWorker] failed to delete partial object %s: %v", version.StorageKey, err)
+				continue
+			}
+			fallthrough
+		case errors.Is(confirmErr, storage.ErrNotFound):
+			if err := w.repo.DeletePendingVersion(ctx, version.ID); err != nil {
+				log.Printf("[upload.RecoveryWorker] failed to roll back version %d: %v", version.ID, err)
+				continue
+			}
+			rolledBack++
+		default:
+			log.Printf("[upload.RecoveryWorker] failed to check object %s: %v", version.StorageKey, confirmErr)
+		}
+	}
+	return activated, rolledBack, nil
+}
@@ -0,0 +1,92 @@
+package upload_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"registration-service/internal/compression"
+	"registration-service/internal/model/fileInfo"
+	"registration-service/internal/storage"
+	"registration-service/internal/storage/memory"
+	"registration-service/internal/upload"
+	"registration-service/internal/versionStore"
+)
+
+type fakeRepo struct {
+	pending   []*fileInfo.FileVersion
+	activated []uint32
+	deleted   []uint32
+}
+
+func (r *fakeRepo) ListStalePendingVersions(ctx context.Context, before time.Time, limit int) ([]*fileInfo.FileVersion, error) {
+	var stale []*fileInfo.FileVersion
+	for _, v := range r.pending {
+		if v.CreatedAt.Before(before) && len(stale) < limit {
+			stale = append(stale, v)
+		}
+	}
+	return stale, nil
+}
+
+func (r *fakeRepo) ActivateVersion(ctx context.Context, version *fileInfo.FileVersion) (bool, error) {
+	r.activated = append(r.activated, version.ID)
+	version.Status = fileInfo.StatusActive
+	return true, nil
+}
+
+func (r *fakeRepo) DeletePendingVersion(ctx context.Context, versionID uint32) error {
+	r.deleted = append(r.deleted, versionID)
+	return nil
+}
+
+func pendingVersion(t *testing.T, store *versionStore.VersionStore, id uint32, key string, size int64, age time.Duration) *fileInfo.FileVersion {
+	version := &fileInfo.FileVersion{
+		ID:          id,
+		StorageKey:  key,
+		Size:        size,
+		ContentType: "application/octet-stream",
+		CreatedAt:   time.Now().Add(-age),
+		ScanStatus:  fileInfo.ScanPending,
+		Status:      fileInfo.StatusPending,
+	}
+	require.NoError(t, store.Prepare(context.Background(), version))
+	return version
+}
+
+func TestRecoveryWorker_FinishesOrRollsBack(t *testing.T) {
+	ctx := context.Background()
+	objects := memory.New()
+	store := versionStore.New(objects, nil, nil, compression.Config{Codec: compression.CodecNone})
+
+	uploaded := pendingVersion(t, store, 1, "a/v1", 5, 2*time.Hour)
+	require.NoError(t, objects.Put(ctx, "a/v1", strings.NewReader("hello"), 5, "application/octet-stream"))
+	missing := pendingVersion(t, store, 2, "b/v1", 5, 2*time.Hour)
+	partial := pendingVersion(t, store, 3, "c/v1", 5, 2*time.Hour)
+	require.NoError(t, objects.Put(ctx, "c/v1", strings.NewReader("he"), 2, "application/octet-stream"))
+	// загрузка ещё может идти
+	fresh := pendingVersion(t, store, 4, "d/v1", 5, time.Minute)
+
+	repo := &fakeRepo{pending: []*fileInfo.FileVersion{uploaded, missing, partial, fresh}}
+	var enqueued []uint32
+	worker := upload.NewRecoveryWorker(repo, store, upload.Config{PendingTimeout: time.Hour}, func(v *fileInfo.FileVersion) {
+		enqueued = append(enqueued, v.ID)
+	})
+
+	activated, rolledBack, err := worker.Recover(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, activated)
+	assert.Equal(t, 2, rolledBack)
+	assert.Equal(t, []uint32{1}, repo.activated)
+	assert.Equal(t, []uint32{1}, enqueued)
+	assert.Equal(t, int64(5), uploaded.StoredSize)
+	assert.ElementsMatch(t, []uint32{2, 3}, repo.deleted)
+
+	_, err = objects.Stat(ctx, "c/v1")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	_, err = objects.Stat(ctx, "a/v1")
+	assert.NoError(t, err)
+}
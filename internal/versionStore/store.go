@@ -0,0 +1,317 @@
+package versionStore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"registration-service/internal/compression"
+	"registration-service/internal/encryption"
+	"registration-service/internal/model/fileInfo"
+	"registration-service/internal/storage"
+)
+
+// contentPurpose — метка ключа основного объекта версии; производные объекты (превью)
+// используют собственный ключ объекта в качестве метки.
+const contentPurpose = "content"
+
+// VersionStore читает и пишет объекты версий. Содержимое сначала сжимается (для текстовых
+// типов), затем шифруется ключом данных версии, если задан KeyProvider. Обёрнутый ключ и кодек
+// сохраняются в строке file_versions; версии, записанные до включения сжатия или шифрования,
+// читаются как есть. Основной объект версии читается из хранилища её уровня (version.Tier),
+// производные объекты всегда лежат в горячем хранилище.
+type VersionStore struct {
+	objects     storage.ObjectStore
+	cold        storage.ObjectStore
+	keys        encryption.KeyProvider
+	compression compression.Config
+}
+
+// ErrNoColdTier — версия лежит в холодном хранилище, но оно не настроено.
+var ErrNoColdTier = errors.New("cold storage tier is not configured")
+
+// New создаёт хранилище; cold == nil отключает холодный уровень, keys == nil — шифрование новых версий.
+func New(objects, cold storage.ObjectStore, keys encryption.KeyProvider, compression compression.Config) *VersionStore {
+	return &VersionStore{objects: objects, cold: cold, keys: keys, compression: compression}
+}
+
+// HasColdTier сообщает, настроено ли холодное хранилище.
+func (s *VersionStore) HasColdTier() bool {
+	return s.cold != nil
+}
+
+func (s *VersionStore) tier(tier string) (storage.ObjectStore, error) {
+	if tier != fileInfo.TierCold {
+		return s.objects, nil
+	}
+	if s.cold == nil {
+		return nil, ErrNoColdTier
+	}
+	return s.cold, nil
+}
+
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r readCloser) Close() error {
+	var first error
+	for _, c := range r.closers {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// ErrSizeMismatch — объект в хранилище не совпадает по размеру с ожидаемым для версии.
+var ErrSizeMismatch = errors.New("stored object size mismatch")
+
+// Prepare выбирает кодек и создаёт ключ данных версии, заполняя version.Codec и, при включённом
+// шифровании, version.KeyID и version.WrappedKey. Вызывается до записи строки версии, чтобы
+// объект, загруженный до сбоя, можно было прочитать по уже сохранённой строке.
+func (s *VersionStore) Prepare(ctx context.Context, version *fileInfo.FileVersion) error {
+	version.Tier = fileInfo.TierHot
+	version.Codec = s.compression.Choose(version.ContentType, version.Size)
+	version.KeyID, version.WrappedKey = "", nil
+	if s.keys == nil {
+		return nil
+	}
+	dataKey, err := encryption.NewDataKey()
+	if err != nil {
+		return err
+	}
+	keyID, wrapped, err := s.keys.Wrap(ctx, dataKey)
+	if err != nil {
+		return fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	version.KeyID, version.WrappedKey = keyID, wrapped
+	return nil
+}
+
+// Put сохраняет содержимое версии под version.StorageKey в горячем хранилище. version.Size — логический размер.
+// Если версия не подготовлена через Prepare, Put делает это сам. Put заполняет version.StoredSize.
+func (s *VersionStore) Put(ctx context.Context, version *fileInfo.FileVersion, data io.Reader) error {
+	if version.Codec == "" {
+		if err := s.Prepare(ctx, version); err != nil {
+			return err
+		}
+	}
+	compressed, err := compression.NewReader(version.Codec, data)
+	if err != nil {
+		return err
+	}
+	defer compressed.Close()
+
+	stream := io.Reader(compressed)
+	storedSize := s.expectedStoredSize(version)
+	if version.WrappedKey != nil {
+		dataKey, err := s.unwrap(ctx, version)
+		if err != nil {
+			return err
+		}
+		stream, err = encryption.NewEncryptReader(stream, encryption.DeriveKey(dataKey, contentPurpose))
+		if err != nil {
+			return err
+		}
+	}
+
+	counted := &countingReader{r: stream}
+	if err := s.objects.Put(ctx, version.StorageKey, counted, storedSize, version.ContentType); err != nil {
+		return err
+	}
+	version.Tier = fileInfo.TierHot
+	version.StoredSize = counted.n
+	return nil
+}
+
+// Confirm проверяет, что объект версии есть в хранилище и его размер согласуется с версией,
+// и заполняет version.StoredSize. Отсутствующий объект — storage.ErrNotFound.
+func (s *VersionStore) Confirm(ctx context.Context, version *fileInfo.FileVersion) error {
+	objects, err := s.tier(version.Tier)
+	if err != nil {
+		return err
+	}
+	info, err := objects.Stat(ctx, version.StorageKey)
+	if err != nil {
+		return err
+	}
+	if expected := s.expectedStoredSize(version); expected >= 0 && info.Size != expected {
+		return fmt.Errorf("%w: %s is %d bytes, expected %d", ErrSizeMismatch, version.StorageKey, info.Size, expected)
+	}
+	version.StoredSize = info.Size
+	return nil
+}
+
+// expectedStoredSize — размер объекта подготовленной версии или -1, если он зависит от сжатия.
+func (s *VersionStore) expectedStoredSize(version *fileInfo.FileVersion) int64 {
+	if version.Codec != compression.CodecNone || version.Size < 0 {
+		return -1
+	}
+	if version.WrappedKey != nil {
+		return encryption.EncryptedSize(version.Size)
+	}
+	return version.Size
+}
+
+// Open возвращает исходное содержимое версии потоком.
+func (s *VersionStore) Open(ctx context.Context, version *fileInfo.FileVersion) (io.ReadCloser, error) {
+	objects, err := s.tier(version.Tier)
+	if err != nil {
+		return nil, err
+	}
+	return s.open(ctx, objects, version, version.StorageKey, contentPurpose, version.Codec)
+}
+
+// OpenStored возвращает объект версии в том виде, в каком он лежит в хранилище (сжатым и
+// зашифрованным), вместе с его размером и типом; используется репликацией.
+func (s *VersionStore) OpenStored(ctx context.Context, version *fileInfo.FileVersion) (io.ReadCloser, storage.ObjectInfo, error) {
+	objects, err := s.tier(version.Tier)
+	if err != nil {
+		return nil, storage.ObjectInfo{}, err
+	}
+	info, err := objects.Stat(ctx, version.StorageKey)
+	if err != nil {
+		return nil, storage.ObjectInfo{}, err
+	}
+	object, err := objects.Get(ctx, version.StorageKey)
+	if err != nil {
+		return nil, storage.ObjectInfo{}, err
+	}
+	return object, info, nil
+}
+
+// PutDerived сохраняет производный объект версии (например, превью) под ключом версии.
+// Производные объекты не сжимаются.
+func (s *VersionStore) PutDerived(ctx context.Context, version *fileInfo.FileVersion, key string, data []byte, contentType string) error {
+	if version.WrappedKey == nil {
+		return s.objects.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType)
+	}
+	dataKey, err := s.unwrap(ctx, version)
+	if err != nil {
+		return err
+	}
+	encrypted, err := encryption.NewEncryptReader(bytes.NewReader(data), encryption.DeriveKey(dataKey, key))
+	if err != nil {
+		return err
+	}
+	return s.objects.Put(ctx, key, encrypted, encryption.EncryptedSize(int64(len(data))), contentType)
+}
+
+func (s *VersionStore) OpenDerived(ctx context.Context, version *fileInfo.FileVersion, key string) (io.ReadCloser, error) {
+	return s.open(ctx, s.objects, version, key, key, compression.CodecNone)
+}
+
+// Delete удаляет объект из горячего хранилища (производные объекты и незавершённые загрузки).
+func (s *VersionStore) Delete(ctx context.Context, key string) error {
+	return s.objects.Delete(ctx, key)
+}
+
+// DeleteVersion удаляет основной объект версии из хранилища её уровня.
+func (s *VersionStore) DeleteVersion(ctx context.Context, version *fileInfo.FileVersion) error {
+	return s.DeleteFromTier(ctx, version.StorageKey, version.Tier)
+}
+
+// DeleteFromTier удаляет объект из хранилища указанного уровня.
+func (s *VersionStore) DeleteFromTier(ctx context.Context, key, tier string) error {
+	objects, err := s.tier(tier)
+	if err != nil {
+		return err
+	}
+	return objects.Delete(ctx, key)
+}
+
+// CopyToTier копирует объект версии как есть (сжатым и зашифрованным) в хранилище уровня tier
+// и проверяет размер копии. Исходный объект остаётся на месте: его удаляют после того,
+// как строка версии переключена на новый уровень.
+func (s *VersionStore) CopyToTier(ctx context.Context, version *fileInfo.FileVersion, tier string) error {
+	from, err := s.tier(version.Tier)
+	if err != nil {
+		return err
+	}
+	to, err := s.tier(tier)
+	if err != nil {
+		return err
+	}
+	info, err := from.Stat(ctx, version.StorageKey)
+	if err != nil {
+		return err
+	}
+	object, err := from.Get(ctx, version.StorageKey)
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+	if err := to.Put(ctx, version.StorageKey, object, info.Size, info.ContentType); err != nil {
+		return fmt.Errorf("failed to copy %s to %s tier: %w", version.StorageKey, tier, err)
+	}
+	copied, err := to.Stat(ctx, version.StorageKey)
+	if err != nil {
+		return err
+	}
+	if copied.Size != info.Size {
+		return fmt.Errorf("%w: %s copy is %d bytes, expected %d", ErrSizeMismatch, version.StorageKey, copied.Size, info.Size)
+	}
+	return nil
+}
+
+// Move переносит объект версии под новый ключ (например, в карантин). Ключ шифрования
+// не зависит от имени объекта, поэтому содержимое копируется без перешифрования.
+func (s *VersionStore) Move(ctx context.Context, fromKey, toKey string) error {
+	if err := s.objects.Copy(ctx, fromKey, toKey); err != nil {
+		return err
+	}
+	return s.objects.Delete(ctx, fromKey)
+}
+
+func (s *VersionStore) open(ctx context.Context, objects storage.ObjectStore, version *fileInfo.FileVersion, key, purpose, codec string) (io.ReadCloser, error) {
+	object, err := objects.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	reader := io.Reader(object)
+
+	if version.WrappedKey != nil {
+		dataKey, err := s.unwrap(ctx, version)
+		if err != nil {
+			object.Close()
+			return nil, err
+		}
+		reader, err = encryption.NewDecryptReader(reader, encryption.DeriveKey(dataKey, purpose))
+		if err != nil {
+			object.Close()
+			return nil, err
+		}
+	}
+
+	decoded, err := compression.NewDecoder(codec, reader)
+	if err != nil {
+		object.Close()
+		return nil, fmt.Errorf("failed to decode %s: %w", key, err)
+	}
+	return readCloser{Reader: decoded, closers: []io.Closer{decoded, object}}, nil
+}
+
+func (s *VersionStore) unwrap(ctx context.Context, version *fileInfo.FileVersion) ([]byte, error) {
+	if s.keys == nil {
+		return nil, fmt.Errorf("version %s is encrypted but no master keys are configured", version.StorageKey)
+	}
+	dataKey, err := s.keys.Unwrap(ctx, version.KeyID, version.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key of %s: %w", version.StorageKey, err)
+	}
+	return dataKey, nil
+}
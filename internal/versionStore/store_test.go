@@ -0,0 +1,100 @@
+package versionStore_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"registration-service/internal/compression"
+	"registration-service/internal/encryption"
+	"registration-service/internal/model/fileInfo"
+	"registration-service/internal/storage"
+	"registration-service/internal/storage/memory"
+	"registration-service/internal/versionStore"
+)
+
+func newKeys(t *testing.T) encryption.KeyProvider {
+	keys, err := encryption.NewLocalKeyProvider(encryption.Config{
+		MasterKeys: "k1:" + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, encryption.DataKeySize)),
+	})
+	require.NoError(t, err)
+	return keys
+}
+
+func readAll(t *testing.T, r io.ReadCloser, err error) []byte {
+	t.Helper()
+	require.NoError(t, err)
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return data
+}
+
+func TestVersionStore_CompressesAndEncrypts(t *testing.T) {
+	ctx := context.Background()
+	objects := memory.New()
+	store := versionStore.New(objects, nil, newKeys(t), compression.Config{Codec: compression.CodecZstd, MinSize: 16})
+
+	content := []byte(strings.Repeat("name,status\napollo,approved\n", 1000))
+	version := &fileInfo.FileVersion{StorageKey: "f/v1", Size: int64(len(content)), ContentType: "text/csv"}
+	require.NoError(t, store.Put(ctx, version, bytes.NewReader(content)))
+
+	assert.Equal(t, compression.CodecZstd, version.Codec)
+	assert.Equal(t, "k1", version.KeyID)
+	assert.NotEmpty(t, version.WrappedKey)
+	assert.Less(t, version.StoredSize, version.Size)
+
+	r, err := objects.Get(ctx, "f/v1")
+	raw := readAll(t, r, err)
+	assert.Equal(t, version.StoredSize, int64(len(raw)))
+	assert.NotContains(t, string(raw), "apollo")
+
+	r, err = store.Open(ctx, version)
+	assert.Equal(t, content, readAll(t, r, err))
+
+	require.NoError(t, store.PutDerived(ctx, version, "f/v1/thumb-64", []byte("preview"), "image/png"))
+	r, err = objects.Get(ctx, "f/v1/thumb-64")
+	assert.NotContains(t, string(readAll(t, r, err)), "preview")
+	r, err = store.OpenDerived(ctx, version, "f/v1/thumb-64")
+	assert.Equal(t, []byte("preview"), readAll(t, r, err))
+}
+
+func TestVersionStore_ReadsLegacyPlaintext(t *testing.T) {
+	ctx := context.Background()
+	objects := memory.New()
+	require.NoError(t, objects.Put(ctx, "old/v1", strings.NewReader("legacy"), 6, "text/plain"))
+
+	store := versionStore.New(objects, nil, newKeys(t), compression.Config{Codec: compression.CodecZstd})
+	r, err := store.Open(ctx, &fileInfo.FileVersion{StorageKey: "old/v1", Size: 6, Codec: compression.CodecNone})
+	assert.Equal(t, []byte("legacy"), readAll(t, r, err))
+}
+
+func TestVersionStore_Confirm(t *testing.T) {
+	ctx := context.Background()
+	objects := memory.New()
+	store := versionStore.New(objects, nil, newKeys(t), compression.Config{Codec: compression.CodecNone})
+
+	content := []byte("plain binary payload")
+	version := &fileInfo.FileVersion{StorageKey: "f/v1", Size: int64(len(content)), ContentType: "application/octet-stream"}
+	require.NoError(t, store.Prepare(ctx, version))
+	prepared := *version
+
+	// объект ещё не загружен
+	assert.ErrorIs(t, store.Confirm(ctx, &prepared), storage.ErrNotFound)
+
+	require.NoError(t, store.Put(ctx, version, bytes.NewReader(content)))
+	require.NoError(t, store.Confirm(ctx, &prepared))
+	assert.Equal(t, version.StoredSize, prepared.StoredSize)
+
+	// строка, сохранённая до загрузки, читает объект тем же ключом данных
+	r, err := store.Open(ctx, &prepared)
+	assert.Equal(t, content, readAll(t, r, err))
+
+	require.NoError(t, objects.Put(ctx, "f/v1", strings.NewReader("truncated"), 9, "application/octet-stream"))
+	assert.ErrorIs(t, store.Confirm(ctx, &prepared), versionStore.ErrSizeMismatch)
+}
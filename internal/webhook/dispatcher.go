@@ -0,0 +1,145 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"registration-service/internal/model/webhookInfo"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Config struct {
+	PollInterval   time.Duration `env:"WEBHOOK_POLL_INTERVAL" env-default:"5s"`
+	BatchSize      int           `env:"WEBHOOK_BATCH_SIZE" env-default:"20"`
+	MaxAttempts    int           `env:"WEBHOOK_MAX_ATTEMPTS" env-default:"8"`
+	BaseBackoff    time.Duration `env:"WEBHOOK_BASE_BACKOFF" env-default:"10s"`
+	MaxBackoff     time.Duration `env:"WEBHOOK_MAX_BACKOFF" env-default:"1h"`
+	RequestTimeout time.Duration `env:"WEBHOOK_REQUEST_TIMEOUT" env-default:"10s"`
+
+	// AllowPrivateNetworks разрешает доставку на loopback, link-local и частные адреса;
+	// только для локальной разработки
+	AllowPrivateNetworks bool `env:"WEBHOOK_ALLOW_PRIVATE_NETWORKS" env-default:"false"`
+}
+
+// Store — часть webhookRepo.WebhookRepository, нужная диспетчеру.
+type Store interface {
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*webhookInfo.Delivery, error)
+	MarkDelivered(ctx context.Context, deliveryID uuid.UUID, attempts int) error
+	MarkRetry(ctx context.Context, deliveryID uuid.UUID, attempts int, nextAttemptAt time.Time, lastError string) error
+	MarkDead(ctx context.Context, deliveryID uuid.UUID, attempts int, lastError string) error
+}
+
+// Dispatcher разбирает outbox webhook_deliveries и отправляет подписанные JSON-запросы.
+// Неудачные доставки повторяются с экспоненциальной задержкой, после MaxAttempts попадают в dead-letter.
+type Dispatcher struct {
+	store  Store
+	client *http.Client
+	cfg    Config
+}
+
+func NewDispatcher(store Store, cfg Config) *Dispatcher {
+	return &Dispatcher{
+		store:  store,
+		client: newClient(cfg),
+		cfg:    cfg,
+	}
+}
+
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := d.DispatchOnce(ctx); err != nil {
+			log.Printf("[webhook.Dispatcher] dispatch error: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce обрабатывает одну пачку доставок и возвращает их количество.
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	// lease с запасом перекрывает таймаут запроса, чтобы доставку не взял другой экземпляр
+	deliveries, err := d.store.ClaimDueDeliveries(ctx, d.cfg.BatchSize, 2*d.cfg.RequestTimeout+d.cfg.PollInterval)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim deliveries: %w", err)
+	}
+	for _, delivery := range deliveries {
+		d.deliver(ctx, delivery)
+	}
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery *webhookInfo.Delivery) {
+	attempts := delivery.Attempts + 1
+	sendErr := d.send(ctx, delivery)
+	if sendErr == nil {
+		if err := d.store.MarkDelivered(ctx, delivery.ID, attempts); err != nil {
+			log.Printf("[webhook.Dispatcher] failed to mark delivery %s delivered: %v", delivery.ID, err)
+		}
+		return
+	}
+
+	if attempts >= d.cfg.MaxAttempts || errors.Is(sendErr, ErrForbiddenDestination) {
+		log.Printf("[webhook.Dispatcher] delivery %s moved to dead-letter after %d attempts: %v", delivery.ID, attempts, sendErr)
+		if err := d.store.MarkDead(ctx, delivery.ID, attempts, sendErr.Error()); err != nil {
+			log.Printf("[webhook.Dispatcher] failed to mark delivery %s dead: %v", delivery.ID, err)
+		}
+		return
+	}
+
+	next := time.Now().Add(Backoff(attempts, d.cfg.BaseBackoff, d.cfg.MaxBackoff))
+	if err := d.store.MarkRetry(ctx, delivery.ID, attempts, next, sendErr.Error()); err != nil {
+		log.Printf("[webhook.Dispatcher] failed to schedule retry for delivery %s: %v", delivery.ID, err)
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery *webhookInfo.Delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q", ErrForbiddenDestination, req.URL.Scheme)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, delivery.Payload))
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.ID.String())
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Backoff возвращает задержку перед попыткой attempts+1: base * 2^(attempts-1), не больше max.
+func Backoff(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
@@ -0,0 +1,202 @@
+package webhook_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"registration-service/internal/model/webhookInfo"
+	"registration-service/internal/webhook"
+)
+
+// fakeStore хранит доставки в памяти вместо webhook_deliveries
+type fakeStore struct {
+	mu         sync.Mutex
+	deliveries map[uuid.UUID]*webhookInfo.Delivery
+}
+
+func newFakeStore(deliveries ...*webhookInfo.Delivery) *fakeStore {
+	s := &fakeStore{deliveries: map[uuid.UUID]*webhookInfo.Delivery{}}
+	for _, d := range deliveries {
+		d.Status = webhookInfo.DeliveryPending
+		s.deliveries[d.ID] = d
+	}
+	return s
+}
+
+func (s *fakeStore) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*webhookInfo.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []*webhookInfo.Delivery
+	for _, d := range s.deliveries {
+		if d.Status == webhookInfo.DeliveryPending && !d.NextAttemptAt.After(time.Now()) && len(due) < limit {
+			copied := *d
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (s *fakeStore) MarkDelivered(ctx context.Context, id uuid.UUID, attempts int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries[id].Status = webhookInfo.DeliveryDelivered
+	s.deliveries[id].Attempts = attempts
+	return nil
+}
+
+func (s *fakeStore) MarkRetry(ctx context.Context, id uuid.UUID, attempts int, next time.Time, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries[id].Attempts = attempts
+	s.deliveries[id].NextAttemptAt = next
+	s.deliveries[id].LastError = lastError
+	return nil
+}
+
+func (s *fakeStore) MarkDead(ctx context.Context, id uuid.UUID, attempts int, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries[id].Status = webhookInfo.DeliveryDead
+	s.deliveries[id].Attempts = attempts
+	s.deliveries[id].LastError = lastError
+	return nil
+}
+
+func testConfig() webhook.Config {
+	return webhook.Config{
+		PollInterval:   time.Second,
+		BatchSize:      10,
+		MaxAttempts:    3,
+		BaseBackoff:    0,
+		MaxBackoff:     0,
+		RequestTimeout: time.Second,
+		// получатели в тестах — httptest на 127.0.0.1
+		AllowPrivateNetworks: true,
+	}
+}
+
+func TestDispatcher_DeliversSignedPayload(t *testing.T) {
+	const secret = "test-secret"
+	payload := []byte(`{"type":"file.uploaded","data":{"file_id":"42"}}`)
+
+	var gotBody []byte
+	var gotSignature, gotEvent string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotSignature = r.Header.Get(webhook.SignatureHeader)
+		gotEvent = r.Header.Get(webhook.EventHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	delivery := &webhookInfo.Delivery{
+		ID:        uuid.New(),
+		EventType: webhookInfo.EventFileUploaded,
+		Payload:   payload,
+		URL:       receiver.URL,
+		Secret:    secret,
+	}
+	store := newFakeStore(delivery)
+
+	n, err := webhook.NewDispatcher(store, testConfig()).DispatchOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	assert.Equal(t, payload, gotBody)
+	assert.Equal(t, webhookInfo.EventFileUploaded, gotEvent)
+	assert.True(t, webhook.Verify(secret, gotBody, gotSignature))
+	assert.False(t, webhook.Verify("other-secret", gotBody, gotSignature))
+	assert.Equal(t, webhookInfo.DeliveryDelivered, store.deliveries[delivery.ID].Status)
+}
+
+func TestDispatcher_RetriesThenDeadLetters(t *testing.T) {
+	calls := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	delivery := &webhookInfo.Delivery{
+		ID:        uuid.New(),
+		EventType: webhookInfo.EventFileShared,
+		Payload:   []byte(`{}`),
+		URL:       receiver.URL,
+		Secret:    "s",
+	}
+	store := newFakeStore(delivery)
+	d := webhook.NewDispatcher(store, testConfig())
+
+	for i := 0; i < 3; i++ {
+		_, err := d.DispatchOnce(context.Background())
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 3, calls)
+	stored := store.deliveries[delivery.ID]
+	assert.Equal(t, webhookInfo.DeliveryDead, stored.Status)
+	assert.Equal(t, 3, stored.Attempts)
+	assert.Contains(t, stored.LastError, "500")
+
+	// dead-letter больше не отправляется
+	n, err := d.DispatchOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestBackoff(t *testing.T) {
+	base, max := 10*time.Second, time.Minute
+	assert.Equal(t, 10*time.Second, webhook.Backoff(1, base, max))
+	assert.Equal(t, 20*time.Second, webhook.Backoff(2, base, max))
+	assert.Equal(t, 40*time.Second, webhook.Backoff(3, base, max))
+	assert.Equal(t, time.Minute, webhook.Backoff(4, base, max))
+	assert.Equal(t, time.Minute, webhook.Backoff(30, base, max))
+}
+
+func TestDispatcher_RefusesInternalDestinations(t *testing.T) {
+	calls := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	cfg := testConfig()
+	cfg.AllowPrivateNetworks = false
+	var deliveries []*webhookInfo.Delivery
+	for _, url := range []string{
+		receiver.URL,
+		// имя резолвится в loopback: проверяется адрес соединения, а не строка URL
+		"http://localhost:" + receiver.URL[len("http://127.0.0.1:"):],
+		"http://169.254.169.254/latest/meta-data/",
+		"file:///etc/passwd",
+	} {
+		deliveries = append(deliveries, &webhookInfo.Delivery{
+			ID:        uuid.New(),
+			EventType: webhookInfo.EventFileUploaded,
+			Payload:   []byte(`{}`),
+			URL:       url,
+			Secret:    "s",
+		})
+	}
+	store := newFakeStore(deliveries...)
+
+	_, err := webhook.NewDispatcher(store, cfg).DispatchOnce(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0, calls)
+	for _, d := range deliveries {
+		stored := store.deliveries[d.ID]
+		// повтор не поможет, доставка сразу уходит в dead-letter
+		assert.Equal(t, webhookInfo.DeliveryDead, stored.Status, d.URL)
+		assert.Equal(t, 1, stored.Attempts, d.URL)
+		assert.Contains(t, stored.LastError, webhook.ErrForbiddenDestination.Error(), d.URL)
+	}
+}
@@ -28,4 +28,169 @@ CREATE TABLE IF NOT EXISTS file_permissions (
     user_id INT REFERENCES users(id),
     permission INT DEFAULT 0,
     PRIMARY KEY (file_id, user_id)
-); 
\ No newline at end of file
+); 
+CREATE TABLE IF NOT EXISTS webhooks (
+    id UUID PRIMARY KEY,
+    owner_id INT REFERENCES users(id),
+    url TEXT NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    event_types TEXT[] NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY,
+    webhook_id UUID REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW(),
+    delivered_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+ALTER TABLE file_versions ADD COLUMN IF NOT EXISTS content_type VARCHAR(255) NOT NULL DEFAULT 'application/octet-stream';
+
+CREATE TABLE IF NOT EXISTS file_search (
+    file_id UUID PRIMARY KEY REFERENCES files(id) ON DELETE CASCADE,
+    version_number INT NOT NULL,
+    content TEXT NOT NULL DEFAULT '',
+    document TSVECTOR NOT NULL,
+    indexed_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_search_document ON file_search USING GIN (document);
+CREATE INDEX IF NOT EXISTS idx_files_name_tsv ON files USING GIN (to_tsvector('simple', name));
+
+CREATE TABLE IF NOT EXISTS file_tags (
+    file_id UUID REFERENCES files(id) ON DELETE CASCADE,
+    tag VARCHAR(64) NOT NULL,
+    PRIMARY KEY (file_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_tags_tag ON file_tags (tag, file_id);
+
+CREATE TABLE IF NOT EXISTS file_properties (
+    file_id UUID REFERENCES files(id) ON DELETE CASCADE,
+    key VARCHAR(64) NOT NULL,
+    value VARCHAR(255) NOT NULL,
+    PRIMARY KEY (file_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_properties_key_value ON file_properties (key, value, file_id);
+
+ALTER TABLE file_versions ADD COLUMN IF NOT EXISTS encryption_key_id VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE file_versions ADD COLUMN IF NOT EXISTS wrapped_key BYTEA;
+
+CREATE INDEX IF NOT EXISTS idx_file_versions_key_id ON file_versions (encryption_key_id) WHERE wrapped_key IS NOT NULL;
+
+ALTER TABLE file_versions ADD COLUMN IF NOT EXISTS codec VARCHAR(16) NOT NULL DEFAULT 'none';
+ALTER TABLE file_versions ADD COLUMN IF NOT EXISTS stored_size BIGINT;
+-- версии, записанные до сжатия: объект занимает исходный размер плюс теги сегментов, если он зашифрован
+UPDATE file_versions
+SET stored_size = CASE
+    WHEN wrapped_key IS NULL THEN size
+    ELSE size + 16 * GREATEST(1, CEIL(size / 65536.0))::BIGINT
+END
+WHERE stored_size IS NULL;
+ALTER TABLE file_versions ALTER COLUMN stored_size SET NOT NULL;
+
+-- текст зашифрованных версий в индексе не хранится, остаётся только tsvector
+UPDATE file_search fs
+SET content = ''
+FROM file_versions v
+WHERE v.file_id = fs.file_id AND v.version_number = fs.version_number
+  AND v.wrapped_key IS NOT NULL AND fs.content <> '';
+
+-- версии, загруженные до появления сканера, считаются проверенными
+ALTER TABLE file_versions ADD COLUMN IF NOT EXISTS scan_status VARCHAR(16) NOT NULL DEFAULT 'clean';
+ALTER TABLE file_versions ADD COLUMN IF NOT EXISTS scan_result TEXT NOT NULL DEFAULT '';
+ALTER TABLE file_versions ADD COLUMN IF NOT EXISTS scanned_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_file_versions_pending_scan ON file_versions (id) WHERE scan_status = 'pending_scan';
+
+ALTER TABLE file_versions ADD COLUMN IF NOT EXISTS object_missing_at TIMESTAMP;
+
+-- загрузка сначала создаёт строки в состоянии pending; существующие файлы уже загружены
+ALTER TABLE files ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'active';
+ALTER TABLE file_versions ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'active';
+
+CREATE INDEX IF NOT EXISTS idx_file_versions_pending_upload ON file_versions (created_at) WHERE status = 'pending';
+
+ALTER TABLE file_versions ADD COLUMN IF NOT EXISTS tier VARCHAR(8) NOT NULL DEFAULT 'hot';
+ALTER TABLE files ADD COLUMN IF NOT EXISTS last_accessed_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS idx_file_versions_hot ON file_versions (id) WHERE tier = 'hot';
+
+ALTER TABLE file_versions ADD COLUMN IF NOT EXISTS replication_status VARCHAR(16) NOT NULL DEFAULT 'none';
+
+CREATE TABLE IF NOT EXISTS replication_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    -- у задач удаления строки версии уже нет
+    version_id INT,
+    storage_key VARCHAR(255) NOT NULL,
+    operation VARCHAR(16) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW(),
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_replication_jobs_due ON replication_jobs (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_replication_jobs_version ON replication_jobs (version_id) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS exports (
+    id UUID PRIMARY KEY,
+    owner_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    storage_key VARCHAR(255) NOT NULL DEFAULT '',
+    size BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    codec VARCHAR(16) NOT NULL DEFAULT '',
+    encryption_key_id VARCHAR(64) NOT NULL DEFAULT '',
+    wrapped_key BYTEA,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP,
+    -- упавший во время сборки экземпляр оставляет задачу running; после lease_until её заберёт другой
+    lease_until TIMESTAMP,
+    completed_at TIMESTAMP,
+    expires_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_exports_owner ON exports (owner_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_exports_queue ON exports (created_at) WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS idx_exports_expires ON exports (expires_at) WHERE status IN ('ready', 'failed');
+
+-- существующие пользователи считаются подтверждёнными; новые создаются без подтверждения
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP DEFAULT NOW();
+ALTER TABLE users ALTER COLUMN email_verified_at DROP DEFAULT;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS display_name VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_url TEXT NOT NULL DEFAULT '';
+
+-- двухфакторная аутентификация: секрет хранится с момента EnrollTOTP, включается после ConfirmTOTP
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    PRIMARY KEY (user_id, code_hash)
+);
+
+-- ключи подписи access-токенов; открытые части публикуются в JWKS
+CREATE TABLE IF NOT EXISTS signing_keys (
+    kid VARCHAR(64) PRIMARY KEY,
+    algorithm VARCHAR(16) NOT NULL,
+    private_key BYTEA NOT NULL,
+    wrap_key_id TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    retire_at TIMESTAMP
+);
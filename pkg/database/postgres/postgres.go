@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Config struct {
@@ -15,7 +15,9 @@ type Config struct {
 	Database string `env:"POSTGRES_DB"   env-default:"users"`
 }
 
-func New(config Config) (*pgx.Conn, error) {
+// New открывает пул соединений: одним *pgx.Conn нельзя пользоваться
+// одновременно из gRPC-обработчиков и фоновых воркеров.
+func New(config Config) (*pgxpool.Pool, error) {
 	connStr := fmt.Sprintf("postgres://%s:%s@%s:%d/%s",
 		config.Username,
 		config.Password,
@@ -23,9 +25,13 @@ func New(config Config) (*pgx.Conn, error) {
 		config.Port,
 		config.Database,
 	)
-	conn, err := pgx.Connect(context.Background(), connStr)
+	pool, err := pgxpool.New(context.Background(), connStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
-	return conn, nil
+	if err := pool.Ping(context.Background()); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return pool, nil
 }
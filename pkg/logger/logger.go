@@ -29,6 +29,14 @@ func (logger *Logger) Info(msg string, fields ...zap.Field) {
 	logger.l.Info(msg, fields...)
 }
 
+func (logger *Logger) Warn(msg string, fields ...zap.Field) {
+	logger.l.Warn(msg, fields...)
+}
+
+func (logger *Logger) Error(msg string, fields ...zap.Field) {
+	logger.l.Error(msg, fields...)
+}
+
 func (logger *Logger) Fatal(msg string, fields ...zap.Field) {
 	logger.l.Fatal(msg, fields...)
 }
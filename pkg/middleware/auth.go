@@ -3,7 +3,6 @@ package middleware
 import (
 	"context"
 	"log"
-	auth "registration-service/api/authproto/proto-generate"
 	"strings"
 
 	"google.golang.org/grpc"
@@ -12,7 +11,12 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-func AuthInterceptor(authClient auth.AuthServiceClient) grpc.UnaryServerInterceptor {
+// TokenVerifier проверяет access-токен и возвращает ID пользователя.
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (uint32, error)
+}
+
+func AuthInterceptor(verifier TokenVerifier) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		// Пропускаем методы авторизации
 		if info.FullMethod == "/auth.AuthService/Login" ||
@@ -32,19 +36,17 @@ func AuthInterceptor(authClient auth.AuthServiceClient) grpc.UnaryServerIntercep
 
 		token := strings.TrimPrefix(authHeader[0], "Bearer ")
 
-		// Используем существующий метод GetUIDByToken
-		resp, err := authClient.GetUIDByToken(ctx, &auth.GetUIDByTokenRequest{
-			Token: token,
-		})
-		if err != nil || !resp.IsValid {
-			log.Printf("[AuthInterceptor] Invalid token or error: %v, IsValid: %t", err, resp.IsValid)
+		// Подпись и отзыв проверяются локально, без запроса к сервису авторизации
+		uid, err := verifier.Verify(ctx, token)
+		if err != nil {
+			log.Printf("[AuthInterceptor] Invalid token: %v", err)
 			return nil, status.Error(codes.Unauthenticated, "invalid token")
 		}
 
-		log.Printf("[AuthInterceptor] Token validated. UID: %d. Adding to context with key 'userID'", resp.Uid)
+		log.Printf("[AuthInterceptor] Token validated. UID: %d. Adding to context with key 'userID'", uid)
 
 		// Добавляем userID в контекст
-		newCtx := context.WithValue(ctx, "userID", resp.Uid)
+		newCtx := context.WithValue(ctx, "userID", uid)
 
 		// Проверка сразу после добавления
 		if val := newCtx.Value("userID"); val != nil {
@@ -57,7 +59,7 @@ func AuthInterceptor(authClient auth.AuthServiceClient) grpc.UnaryServerIntercep
 	}
 }
 
-func StreamAuthInterceptor(authClient auth.AuthServiceClient) grpc.StreamServerInterceptor {
+func StreamAuthInterceptor(verifier TokenVerifier) grpc.StreamServerInterceptor {
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		// Пропускаем методы, если они не требуют аутентификации (если такие есть для стримов)
 		// if info.FullMethod == "/some.Service/UnprotectedStream" {
@@ -79,16 +81,14 @@ func StreamAuthInterceptor(authClient auth.AuthServiceClient) grpc.StreamServerI
 
 		token := strings.TrimPrefix(authHeader[0], "Bearer ")
 
-		resp, err := authClient.GetUIDByToken(ctx, &auth.GetUIDByTokenRequest{
-			Token: token,
-		})
-		if err != nil || !resp.IsValid {
-			log.Printf("[StreamAuthInterceptor] Invalid token or error for method %s: %v, IsValid: %t", info.FullMethod, err, resp.IsValid)
+		uid, err := verifier.Verify(ctx, token)
+		if err != nil {
+			log.Printf("[StreamAuthInterceptor] Invalid token for method %s: %v", info.FullMethod, err)
 			return status.Error(codes.Unauthenticated, "invalid token")
 		}
 
-		log.Printf("[StreamAuthInterceptor] Token validated for method %s. UID: %d. Adding to context with key 'userID'", info.FullMethod, resp.Uid)
-		newCtx := context.WithValue(ctx, "userID", resp.Uid)
+		log.Printf("[StreamAuthInterceptor] Token validated for method %s. UID: %d. Adding to context with key 'userID'", info.FullMethod, uid)
+		newCtx := context.WithValue(ctx, "userID", uid)
 
 		// Оборачиваем ServerStream для использования нового контекста
 		wrappedStream := &wrappedServerStream{